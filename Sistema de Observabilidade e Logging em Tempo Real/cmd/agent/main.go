@@ -1,75 +1,180 @@
 package main
 import (
 	"context"
+	"crypto/tls"
+	"fmt"
 	"log"
+	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"observability-system/internal/application/usecases"
 	"observability-system/internal/domain/entities"
+	"observability-system/internal/domain/ports"
 	"observability-system/internal/infrastructure/adapters"
+	"observability-system/internal/infrastructure/alertrules"
+	"observability-system/internal/infrastructure/logparse"
+	"observability-system/internal/infrastructure/logstore"
+	"observability-system/internal/infrastructure/prometheus"
+	"observability-system/internal/infrastructure/redact"
+	"observability-system/internal/infrastructure/tsdb"
+	"observability-system/internal/infrastructure/wal"
 )
+const metricsInterval = 5 * time.Second
 func main() {
 	log.Println("🚀 Starting Observability Agent (Clean Architecture)...")
-	processCollector, err := adapters.NewProcessCollectorAdapter()
+	processCollector, err := newCollector()
 	if err != nil {
-		log.Fatalf("Failed to create Process collector: %v", err)
+		log.Fatalf("Failed to create collector: %v", err)
 	}
 	defer processCollector.Close()
 
-	metricsRepo := adapters.NewInfluxDBRepository(
-		getEnv("INFLUXDB_URL", "http://localhost:8086"),
-		getEnv("INFLUXDB_TOKEN", "my-super-secret-token"),
-		getEnv("INFLUXDB_ORG", "observability"),
-		getEnv("INFLUXDB_BUCKET", "metrics"),
-	)
+	retentionRules, err := entities.ParseRetentionRules(getEnv("RETENTION_RULES", entities.DefaultRetentionRules))
+	if err != nil {
+		log.Fatalf("Invalid retention rules: %v", err)
+	}
+	metricsRepo, alertRepo, logRepo, err := newRepositories(retentionRules)
+	if err != nil {
+		log.Fatalf("Failed to open storage: %v", err)
+	}
+	defer logRepo.Close()
 	defer metricsRepo.Close()
-
-	alertRepo := adapters.NewRedisAlertRepository(getEnv("REDIS_ADDR", "localhost:6379"))
 	defer alertRepo.Close()
+	metricsStore, _ := metricsRepo.(ports.MetricsRetentionStore)
+	if metricsStore == nil {
+		log.Printf("⚠️ Metrics backend does not support deletes; metric retention rules will not be enforced")
+	}
+	alertStore, _ := alertRepo.(ports.AlertRetentionStore)
+	enforceRetentionUC := usecases.NewEnforceRetentionUseCase(
+		entities.RetentionRulesForTiers(retentionRules, entities.RetentionTierRaw, entities.RetentionTierAlerts),
+		metricsStore,
+		alertStore,
+	)
 
-	notifier := adapters.NewConsoleNotifier()
+	redactor, err := newRedactor()
+	if err != nil {
+		log.Fatalf("Invalid redaction settings: %v", err)
+	}
+	var notifier ports.Notifier = adapters.NewConsoleNotifier()
+	var logRedactor ports.Redactor
+	if redactor.Enabled() {
+		notifier = adapters.NewRedactingNotifier(notifier, redactor)
+		logRedactor = redactor
+	}
+	maintenanceWindows, err := newMaintenanceWindows()
+	if err != nil {
+		log.Fatalf("Invalid maintenance windows: %v", err)
+	}
+	silenceRepo, _ := alertRepo.(ports.SilenceRepository)
+	if silenceRepo != nil || len(maintenanceWindows) > 0 {
+		notifier = adapters.NewSilencingNotifier(notifier, usecases.NewSilenceAlertsUseCase(silenceRepo, maintenanceWindows))
+	}
 
+	var recorders []ports.SampleRecorder
+	if addr := getEnv("METRICS_ADDR", ""); addr != "" {
+		recorders = append(recorders, prometheus.NewMetricsExporter(getEnvDuration("METRICS_STALE_AFTER", 5*time.Minute)))
+		go serveMetrics(addr)
+	}
 	collectMetricsUC := usecases.NewCollectMetricsUseCase(processCollector, metricsRepo)
-	checkAlertsUC := usecases.NewCheckAlertsUseCase(alertRepo, notifier, 90.0, 85.0)
+	alertRules, err := newAlertRules()
+	if err != nil {
+		log.Fatalf("Invalid alert rules: %v", err)
+	}
+	alertStates, _ := alertRepo.(ports.AlertStateRepository)
+	checkAlertsUC := usecases.NewCheckAlertsUseCase(alertRepo, alertStates, notifier, alertRules, metricsInterval)
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
-	go collectMetrics(ctx, collectMetricsUC, checkAlertsUC, alertRepo)
+	go collectMetrics(ctx, collectMetricsUC, checkAlertsUC, recorders)
+	go enforceRetention(ctx, enforceRetentionUC)
+	if redactor.Enabled() {
+		go reportRedactions(ctx, redactor, metricsRepo, recorders)
+	}
+
+	logSources, err := newLogSources()
+	if err != nil {
+		log.Fatalf("Failed to create log sources: %v", err)
+	}
+	logParser, err := newLogParser()
+	if err != nil {
+		log.Fatalf("Invalid log parse rules: %v", err)
+	}
+	multilineRules, err := logparse.ParseMultilineRules(getEnv("LOG_MULTILINE", ""))
+	if err != nil {
+		log.Fatalf("Invalid multiline rules: %v", err)
+	}
+	logAlertRules, err := newLogAlertRules()
+	if err != nil {
+		log.Fatalf("Invalid log alert rules: %v", err)
+	}
+	logMetricRules, err := newLogMetricRules()
+	if err != nil {
+		log.Fatalf("Invalid log metric rules: %v", err)
+	}
+	var logSink ports.LogSink = logRepo
+	if getEnv("LOG_SINK", "storage") == "console" {
+		logSink = adapters.NewConsoleLogSink()
+	}
+	logSinks := []ports.LogSink{logSink}
+	if len(logAlertRules) > 0 {
+		logSinks = append(logSinks, usecases.NewCheckLogAlertsUseCase(logAlertRules, alertRepo, notifier))
+	}
+	if len(logMetricRules) > 0 {
+		logSinks = append(logSinks, usecases.NewExtractLogMetricsUseCase(logparse.NewMetricExtractor(logMetricRules), metricsRepo, append(recorders, checkAlertsUC)...))
+	}
+	if len(logSinks) > 1 {
+		logSink = adapters.NewMultiLogSink(logSinks...)
+	}
+	logsDone := make(chan struct{})
+	if len(logSources) > 0 {
+		collectLogsUC := usecases.NewCollectLogsUseCase(
+			logSources,
+			logparse.NewMultiline(multilineRules),
+			logParser,
+			logRedactor,
+			logSink,
+			getEnvInt("LOG_BATCH_SIZE", 500),
+			getEnvDuration("LOG_FLUSH_INTERVAL", 1*time.Second),
+		)
+		go func() {
+			defer close(logsDone)
+			collectLogsUC.Execute(ctx)
+		}()
+	} else {
+		close(logsDone)
+	}
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 	<-sigChan
 	log.Println("🛑 Shutting down agent...")
+	cancel()
+	<-logsDone
+	for _, source := range logSources {
+		source.Close()
+	}
 }
-func collectMetrics(ctx context.Context, collectUC *usecases.CollectMetricsUseCase, alertUC *usecases.CheckAlertsUseCase, alertRepo *adapters.RedisAlertRepository) {
-	ticker := time.NewTicker(5 * time.Second)
+func collectMetrics(ctx context.Context, collectUC *usecases.CollectMetricsUseCase, alertUC *usecases.CheckAlertsUseCase, recorders []ports.SampleRecorder) {
+	ticker := time.NewTicker(metricsInterval)
 	defer ticker.Stop()
 	for {
 		select {
 		case <-ctx.Done():
 			return
 		case <-ticker.C:
-			allMetrics, err := collectUC.Execute(ctx)
+			samples, err := collectUC.Execute(ctx)
 			if err != nil {
 				log.Printf("Error collecting metrics: %v", err)
 				continue
 			}
-			for _, metrics := range allMetrics {
-				if err := alertUC.Execute(ctx, metrics); err != nil {
-					log.Printf("Error checking alerts: %v", err)
-				}
-				if !metrics.IsHealthy(90.0, 85.0) {
-					violations := metrics.ExceedsThreshold(90.0, 85.0)
-					for _, v := range violations {
-						var alertType entities.AlertType
-						if v == "CPU" {
-							alertType = entities.AlertTypeCPU
-						} else {
-							alertType = entities.AlertTypeMemory
-						}
-						alertRepo.SetCooldown(ctx, metrics.ContainerID, alertType, 5*time.Minute)
-					}
-				}
+			for _, recorder := range recorders {
+				recorder.RecordSamples(samples)
+			}
+			alertUC.Execute(ctx, samples)
+			for _, metrics := range entities.ContainerMetricsFromSamples(samples) {
 				log.Printf("📊 %s - CPU: %.2f%% | Memory: %.2f%% | Net RX: %d TX: %d",
 					metrics.ContainerName,
 					metrics.CPUPercent,
@@ -81,9 +186,294 @@ func collectMetrics(ctx context.Context, collectUC *usecases.CollectMetricsUseCa
 		}
 	}
 }
+func enforceRetention(ctx context.Context, uc *usecases.EnforceRetentionUseCase) {
+	ticker := time.NewTicker(getEnvDuration("RETENTION_INTERVAL", 1*time.Hour))
+	defer ticker.Stop()
+	for {
+		results, err := uc.Execute(ctx, time.Now())
+		if err != nil {
+			log.Printf("Error enforcing retention: %v", err)
+		}
+		for _, result := range results {
+			switch {
+			case result.Deleted > 0:
+				log.Printf("🧹 Retention %s removed %d records older than %s", result.Rule, result.Deleted, result.Before.Format(time.RFC3339))
+			case result.Deleted == entities.RetentionDeletedUnknown:
+				log.Printf("🧹 Retention %s removed records older than %s", result.Rule, result.Before.Format(time.RFC3339))
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+func newCollector() (ports.ContainerCollector, error) {
+	collector := os.Getenv("COLLECTOR")
+	switch collector {
+	case "docker":
+		return adapters.NewDockerCollectorAdapter()
+	case "cgroup":
+		return adapters.NewCgroupCollectorAdapter(getEnv("CGROUP_ROOT", "/sys/fs/cgroup"))
+	case "simulated":
+		return adapters.NewProcessCollectorAdapter()
+	}
+	procCollector, err := adapters.NewProcFSCollectorAdapter(
+		getEnv("PROC_ROOT", "/proc"),
+		splitList(getEnv("PROCESS_MATCH", "observability-agent,observability-server,redis-server,influxd")),
+	)
+	if err != nil && collector == "" {
+		log.Printf("⚠️ %v, falling back to the simulated collector", err)
+		return adapters.NewProcessCollectorAdapter()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return procCollector, nil
+}
+func reportRedactions(ctx context.Context, redactor *redact.Redactor, metricsRepo ports.MetricsRepository, recorders []ports.SampleRecorder) {
+	ticker := time.NewTicker(15 * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			samples := redactor.Samples(now)
+			if len(samples) == 0 {
+				continue
+			}
+			for _, recorder := range recorders {
+				recorder.RecordSamples(samples)
+			}
+			if err := metricsRepo.Save(ctx, samples); err != nil {
+				log.Printf("Error saving redaction counters: %v", err)
+			}
+		}
+	}
+}
+func newLogSources() ([]ports.LogSource, error) {
+	var sources []ports.LogSource
+	checkpointDir := getEnv("LOG_CHECKPOINT_DIR", "data/logs")
+	for _, name := range splitList(getEnv("LOG_SOURCES", "")) {
+		switch name {
+		case "docker":
+			source, err := adapters.NewDockerLogCollectorAdapter(
+				filepath.Join(checkpointDir, "docker-offsets.json"),
+				getEnvDuration("LOG_POLL_INTERVAL", 10*time.Second),
+			)
+			if err != nil {
+				return nil, err
+			}
+			sources = append(sources, source)
+		case "file":
+			source, err := adapters.NewFileTailerAdapter(
+				splitList(getEnv("LOG_FILE_PATTERNS", "/var/log/*.log")),
+				filepath.Join(checkpointDir, "file-offsets.json"),
+				getEnvDuration("LOG_FILE_POLL_INTERVAL", 1*time.Second),
+			)
+			if err != nil {
+				return nil, err
+			}
+			sources = append(sources, source)
+		case "syslog":
+			options := adapters.SyslogOptions{
+				UDPAddr: getEnv("SYSLOG_UDP_ADDR", ":5514"),
+				TCPAddr: getEnv("SYSLOG_TCP_ADDR", ":5514"),
+			}
+			if certFile := getEnv("SYSLOG_TLS_CERT", ""); certFile != "" {
+				cert, err := tls.LoadX509KeyPair(certFile, getEnv("SYSLOG_TLS_KEY", ""))
+				if err != nil {
+					return nil, fmt.Errorf("failed to load syslog TLS certificate: %w", err)
+				}
+				options.TLSConfig = &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}
+			}
+			source, err := adapters.NewSyslogReceiverAdapter(options)
+			if err != nil {
+				return nil, err
+			}
+			sources = append(sources, source)
+		default:
+			return nil, fmt.Errorf("unknown log source %q", name)
+		}
+	}
+	return sources, nil
+}
+func newLogParser() (*logparse.Parser, error) {
+	spec, err := readRuleSpec("LOG_PARSE_RULES")
+	if err != nil {
+		return nil, err
+	}
+	return logparse.NewParser(spec)
+}
+func newAlertRules() ([]entities.AlertRule, error) {
+	paths := splitList(getEnv("ALERT_RULES_FILES", ""))
+	if len(paths) == 0 {
+		return entities.DefaultAlertRules(), nil
+	}
+	rules, err := alertrules.LoadFiles(paths)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("📐 Loaded %d alert rules from %s", len(rules), strings.Join(paths, ", "))
+	return rules, nil
+}
+func newLogAlertRules() ([]entities.LogAlertRule, error) {
+	spec, err := readRuleSpec("LOG_ALERT_RULES")
+	if err != nil {
+		return nil, err
+	}
+	return logparse.ParseLogAlertRules(spec)
+}
+func newLogMetricRules() ([]entities.LogMetricRule, error) {
+	spec, err := readRuleSpec("LOG_METRIC_RULES")
+	if err != nil {
+		return nil, err
+	}
+	return logparse.ParseLogMetricRules(spec)
+}
+func newMaintenanceWindows() ([]entities.MaintenanceWindow, error) {
+	spec, err := readRuleSpec("MAINTENANCE_WINDOWS")
+	if err != nil {
+		return nil, err
+	}
+	return alertrules.ParseMaintenanceWindows(spec)
+}
+func newRedactor() (*redact.Redactor, error) {
+	var detectors []string
+	if names := getEnv("REDACT_DETECTORS", redact.DefaultDetectors); names != "none" {
+		detectors = splitList(names)
+	}
+	spec, err := readRuleSpec("REDACT_PATTERNS")
+	if err != nil {
+		return nil, err
+	}
+	custom, err := redact.ParseCustomDetectors(spec)
+	if err != nil {
+		return nil, err
+	}
+	mode, hashKey := redact.Mode(getEnv("REDACT_MODE", string(redact.ModeMask))), getEnv("REDACT_HASH_KEY", "")
+	if mode == redact.ModeHash && hashKey == "" {
+		log.Println("⚠️ REDACT_HASH_KEY is empty, hashed values can be recovered by brute force")
+	}
+	return redact.New(redact.Options{
+		Detectors: detectors,
+		Custom:    custom,
+		Mode:      mode,
+		HashKey:   hashKey,
+	})
+}
+func readRuleSpec(key string) (string, error) {
+	spec := getEnv(key, "")
+	if path := os.Getenv(key + "_FILE"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", err
+		}
+		if spec != "" {
+			spec += "\n"
+		}
+		spec += string(data)
+	}
+	return spec, nil
+}
+func serveMetrics(addr string) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	log.Printf("📈 Serving Prometheus metrics on %s/metrics", addr)
+	if err := http.ListenAndServe(addr, mux); err != nil {
+		log.Printf("Metrics endpoint stopped: %v", err)
+	}
+}
+func newRepositories(retentionRules []entities.RetentionRule) (ports.MetricsRepository, ports.AlertRepository, ports.LogRepository, error) {
+	if getEnv("STORAGE_BACKEND", "influxdb") == "embedded" {
+		dataDir := getEnv("EMBEDDED_DATA_DIR", "data/embedded")
+		db, err := tsdb.Open(tsdb.Options{
+			Dir:           filepath.Join(dataDir, "metrics"),
+			FlushInterval: getEnvDuration("EMBEDDED_FLUSH_INTERVAL", 10*time.Second),
+		})
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		alertRepo, err := adapters.NewEmbeddedAlertRepository(filepath.Join(dataDir, "alerts"))
+		if err != nil {
+			db.Close()
+			return nil, nil, nil, err
+		}
+		logDB, err := logstore.Open(logstore.Options{
+			Dir:       filepath.Join(dataDir, "logs"),
+			Retention: getEnvDuration("LOG_RETENTION", 7*24*time.Hour),
+		})
+		if err != nil {
+			db.Close()
+			alertRepo.Close()
+			return nil, nil, nil, err
+		}
+		return adapters.NewEmbeddedMetricsRepository(db), alertRepo, adapters.NewEmbeddedLogRepository(logDB), nil
+	}
+	influxRepo := adapters.NewInfluxDBRepositoryWithOptions(
+		getEnv("INFLUXDB_URL", "http://localhost:8086"),
+		getEnv("INFLUXDB_TOKEN", "my-super-secret-token"),
+		getEnv("INFLUXDB_ORG", "observability"),
+		getEnv("INFLUXDB_BUCKET", "metrics"),
+		adapters.InfluxDBWriteOptions{
+			MaxBatchSize:  getEnvInt("INFLUXDB_BATCH_SIZE", 500),
+			FlushInterval: getEnvDuration("INFLUXDB_FLUSH_INTERVAL", 1*time.Second),
+			BufferSize:    getEnvInt("INFLUXDB_BUFFER_SIZE", 10000),
+			RetryAttempts: getEnvInt("INFLUXDB_RETRY_ATTEMPTS", 3),
+			RetryDelay:    getEnvDuration("INFLUXDB_RETRY_DELAY", 1*time.Second),
+			LogFieldTags:  splitList(getEnv("LOG_FIELD_TAGS", "")),
+		},
+	)
+	metricsRepo, err := newSpoolingRepository(influxRepo)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	alertRepo := adapters.NewRedisAlertRepositoryWithRetention(
+		getEnv("REDIS_ADDR", "localhost:6379"),
+		entities.RetentionFor(retentionRules, entities.RetentionTierAlerts),
+	)
+	return metricsRepo, alertRepo, influxRepo, nil
+}
+func newSpoolingRepository(backend ports.MetricsRepository) (ports.MetricsRepository, error) {
+	syncPolicy, err := wal.ParseSyncPolicy(getEnv("WAL_SYNC", "interval"))
+	if err != nil {
+		return nil, err
+	}
+	return adapters.NewSpoolingMetricsRepository(backend, wal.Options{
+		Dir:           getEnv("WAL_DIR", "data/wal"),
+		MaxTotalBytes: int64(getEnvInt("WAL_MAX_BYTES", 512*1024*1024)),
+		MaxAge:        getEnvDuration("WAL_MAX_AGE", 72*time.Hour),
+		SyncPolicy:    syncPolicy,
+	}, getEnvDuration("WAL_REPLAY_INTERVAL", 10*time.Second))
+}
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
 	}
 	return defaultValue
+}
+func getEnvInt(key string, defaultValue int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+	if err != nil {
+		return defaultValue
+	}
+	return value
 }
\ No newline at end of file
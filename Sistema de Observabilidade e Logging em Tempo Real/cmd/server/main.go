@@ -2,14 +2,24 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
+	"net/url"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 	"github.com/gorilla/websocket"
 	"github.com/redis/go-redis/v9"
+	"observability-system/internal/application/usecases"
+	"observability-system/internal/domain/entities"
+	"observability-system/internal/domain/ports"
 	"observability-system/internal/infrastructure/adapters"
-	"observability-system/internal/storage"
+	"observability-system/internal/infrastructure/logstore"
+	"observability-system/internal/infrastructure/tsdb"
 	ws "observability-system/internal/websocket"
 )
 var upgrader = websocket.Upgrader{
@@ -18,37 +28,81 @@ var upgrader = websocket.Upgrader{
 	},
 }
 type Server struct {
-	hub         *ws.Hub
-	influxDB    *storage.InfluxDBStorage
-	redisClient *redis.Client
+	hub          *ws.Hub
+	metricsRepo  ports.MetricsRepository
+	queryMetrics *usecases.QueryMetricsUseCase
+	queryLogs    *usecases.QueryLogsUseCase
+	silences     *usecases.SilenceAlertsUseCase
+	redisClient  *redis.Client
 }
 func main() {
 	log.Println("🚀 Starting Observability Server...")
 
-	influxDB := storage.NewInfluxDBStorage(
-		getEnv("INFLUXDB_URL", "http://localhost:8086"),
-		getEnv("INFLUXDB_TOKEN", "my-super-secret-token"),
-		getEnv("INFLUXDB_ORG", "observability"),
-		getEnv("INFLUXDB_BUCKET", "metrics"),
-	)
-	defer influxDB.Close()
+	retentionRules, err := entities.ParseRetentionRules(getEnv("RETENTION_RULES", entities.DefaultRetentionRules))
+	if err != nil {
+		log.Fatalf("Invalid retention rules: %v", err)
+	}
+	rollupTiers := entities.RollupTiersWithRetention(entities.DefaultRollupTiers, retentionRules)
+	metricsRepo, rollupRepo, logRepo, err := newRepositories()
+	if err != nil {
+		log.Fatalf("Failed to open storage: %v", err)
+	}
+	defer metricsRepo.Close()
+	defer rollupRepo.Close()
+	defer logRepo.Close()
 
-	redisClient := redis.NewClient(&redis.Options{
-		Addr: getEnv("REDIS_ADDR", "localhost:6379"),
+	var redisClient *redis.Client
+	if getEnv("STORAGE_BACKEND", "influxdb") != "embedded" {
+		redisClient = redis.NewClient(&redis.Options{
+			Addr: getEnv("REDIS_ADDR", "localhost:6379"),
+		})
+		defer redisClient.Close()
+	}
+	silenceRepo, err := newSilenceRepository()
+	if err != nil {
+		log.Fatalf("Failed to open silence store: %v", err)
+	}
+	defer silenceRepo.Close()
+	hub := ws.NewHubWithOptions(ws.HubOptions{
+		LogRate:  float64(getEnvInt("WS_LOG_RATE", 100)),
+		LogBurst: getEnvInt("WS_LOG_BURST", 500),
 	})
-	defer redisClient.Close()
-	hub := ws.NewHub()
 	go hub.Run()
 	server := &Server{
-		hub:         hub,
-		influxDB:    influxDB,
-		redisClient: redisClient,
+		hub:          hub,
+		metricsRepo:  metricsRepo,
+		queryMetrics: usecases.NewQueryMetricsUseCase(metricsRepo, rollupRepo, rollupTiers),
+		queryLogs:    usecases.NewQueryLogsUseCase(logRepo),
+		silences:     usecases.NewSilenceAlertsUseCase(silenceRepo, nil),
+		redisClient:  redisClient,
 	}
 	go server.broadcastMetrics()
+	if feed, ok := logRepo.(ports.LogFeed); ok {
+		go server.tailLogs(usecases.NewTailLogsUseCase(feed, hub))
+	} else {
+		log.Printf("⚠️ Log backend cannot follow new entries; the live log tail is disabled")
+	}
+	go server.rollupMetrics(usecases.NewRollupMetricsUseCase(
+		metricsRepo,
+		rollupRepo,
+		rollupTiers,
+		getEnvDuration("ROLLUP_BACKFILL", 24*time.Hour),
+		getEnvDuration("ROLLUP_SETTLE", 30*time.Second),
+		getEnvDuration("ROLLUP_LOOKBACK", 10*time.Minute),
+	))
+	tierNames := make([]string, 0, len(rollupTiers))
+	for _, tier := range rollupTiers {
+		tierNames = append(tierNames, tier.Name)
+	}
+	retentionStore, _ := rollupRepo.(ports.MetricsRetentionStore)
+	go server.enforceRetention(usecases.NewEnforceRetentionUseCase(entities.RetentionRulesForTiers(retentionRules, tierNames...), retentionStore, nil))
 
 	http.HandleFunc("/ws", server.handleWebSocket)
 	http.HandleFunc("/api/containers", server.handleContainers)
 	http.HandleFunc("/api/metrics", server.handleMetrics)
+	http.HandleFunc("/api/logs", server.handleLogs)
+	http.HandleFunc("/api/silences", server.handleSilences)
+	http.HandleFunc("/api/silences/", server.handleExpireSilence)
 	http.Handle("/", http.FileServer(http.Dir("./web")))
 
 	port := getEnv("PORT", "8080")
@@ -91,13 +145,97 @@ func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "container_id required", http.StatusBadRequest)
 		return
 	}
-	metrics, err := s.influxDB.QueryMetrics(r.Context(), containerID, 1*time.Hour)
+	duration, err := parseLookback(r.URL.Query().Get("duration"), 1*time.Hour)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	step, err := parseLookback(r.URL.Query().Get("step"), duration/360)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	series, err := s.queryMetrics.Execute(r.Context(), map[string]string{"container_id": containerID}, duration, step)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(entities.ContainerMetricsFromSeries(series))
+}
+func (s *Server) handleLogs(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	query, err := parseLogQuery(r.URL.Query(), time.Now())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	page, err := s.queryLogs.Execute(r.Context(), query)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(metrics)
+	json.NewEncoder(w).Encode(page)
+}
+func (s *Server) handleSilences(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		silences, err := s.silences.List(r.Context(), r.URL.Query().Get("expired") == "true")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(silences)
+	case http.MethodPost:
+		r.Body = http.MaxBytesReader(w, r.Body, 64*1024)
+		silence, err := decodeSilence(r, time.Now())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		created, err := s.silences.Create(r.Context(), silence)
+		if errors.Is(err, usecases.ErrInvalidSilence) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		log.Printf("🔕 Silence %s created by %s until %s", created.ID, created.CreatedBy, created.EndsAt.Format(time.RFC3339))
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(created)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+func (s *Server) handleExpireSilence(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	id := strings.TrimPrefix(r.URL.Path, "/api/silences/")
+	if id == "" || strings.Contains(id, "/") {
+		http.Error(w, "silence id required", http.StatusBadRequest)
+		return
+	}
+	silence, err := s.silences.Expire(r.Context(), id)
+	if errors.Is(err, usecases.ErrSilenceNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(silence)
 }
 func (s *Server) broadcastMetrics() {
 	ticker := time.NewTicker(2 * time.Second)
@@ -110,42 +248,227 @@ func (s *Server) broadcastMetrics() {
 		if err != nil {
 			continue
 		}
-		type MetricData struct {
-			ContainerID   string    `json:"ContainerID"`
-			ContainerName string    `json:"ContainerName"`
-			CPUPercent    float64   `json:"CPUPercent"`
-			MemoryUsage   uint64    `json:"MemoryUsage"`
-			MemoryLimit   uint64    `json:"MemoryLimit"`
-			MemoryPercent float64   `json:"MemoryPercent"`
-			NetworkRx     uint64    `json:"NetworkRx"`
-			NetworkTx     uint64    `json:"NetworkTx"`
-			Timestamp     time.Time `json:"Timestamp"`
-		}
-		var allStats []MetricData
+		var samples []entities.Sample
 		for _, processName := range processes {
-			metrics, err := processCollector.CollectMetrics(ctx, processName)
-			if err != nil || metrics == nil {
+			collected, err := processCollector.CollectSamples(ctx, processName)
+			if err != nil {
 				continue
 			}
-			stats := MetricData{
-				ContainerID:   metrics.ContainerID,
-				ContainerName: metrics.ContainerName,
-				CPUPercent:    metrics.CPUPercent,
-				MemoryUsage:   metrics.MemoryUsage,
-				MemoryLimit:   metrics.MemoryLimit,
-				MemoryPercent: metrics.MemoryPercent,
-				NetworkRx:     metrics.NetworkRx,
-				NetworkTx:     metrics.NetworkTx,
-				Timestamp:     metrics.Timestamp,
+			samples = append(samples, collected...)
+		}
+		s.hub.BroadcastMetrics(entities.ContainerMetricsFromSamples(samples))
+	}
+}
+func (s *Server) tailLogs(uc *usecases.TailLogsUseCase) {
+	ticker := time.NewTicker(getEnvDuration("LOG_TAIL_INTERVAL", 1*time.Second))
+	defer ticker.Stop()
+	for range ticker.C {
+		if _, err := uc.Execute(context.Background()); err != nil {
+			log.Printf("Error tailing logs: %v", err)
+		}
+	}
+}
+func (s *Server) rollupMetrics(uc *usecases.RollupMetricsUseCase) {
+	ticker := time.NewTicker(getEnvDuration("ROLLUP_INTERVAL", 1*time.Minute))
+	defer ticker.Stop()
+	for range ticker.C {
+		written, err := uc.Execute(context.Background(), time.Now())
+		if err != nil {
+			log.Printf("Error computing rollups: %v", err)
+		}
+		if written > 0 {
+			log.Printf("📉 Wrote %d metric rollups", written)
+		}
+	}
+}
+func (s *Server) enforceRetention(uc *usecases.EnforceRetentionUseCase) {
+	ticker := time.NewTicker(getEnvDuration("RETENTION_INTERVAL", 1*time.Hour))
+	defer ticker.Stop()
+	for {
+		results, err := uc.Execute(context.Background(), time.Now())
+		if err != nil {
+			log.Printf("Error enforcing retention: %v", err)
+		}
+		for _, result := range results {
+			switch {
+			case result.Deleted > 0:
+				log.Printf("🧹 Retention %s removed %d records older than %s", result.Rule, result.Deleted, result.Before.Format(time.RFC3339))
+			case result.Deleted == entities.RetentionDeletedUnknown:
+				log.Printf("🧹 Retention %s removed records older than %s", result.Rule, result.Before.Format(time.RFC3339))
 			}
-			allStats = append(allStats, stats)
 		}
-		s.hub.BroadcastMetrics(allStats)
+		<-ticker.C
 	}
 }
+func newRepositories() (ports.MetricsRepository, ports.RollupRepository, ports.LogRepository, error) {
+	if getEnv("STORAGE_BACKEND", "influxdb") == "embedded" {
+		dataDir := getEnv("EMBEDDED_DATA_DIR", "data/embedded")
+		db, err := tsdb.Open(tsdb.Options{
+			Dir:      filepath.Join(dataDir, "metrics"),
+			ReadOnly: true,
+		})
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		rollupDB, err := tsdb.Open(tsdb.Options{
+			Dir:           filepath.Join(dataDir, "rollups"),
+			BlockDuration: 24 * time.Hour,
+		})
+		if err != nil {
+			db.Close()
+			return nil, nil, nil, err
+		}
+		logDB, err := logstore.Open(logstore.Options{
+			Dir:      filepath.Join(dataDir, "logs"),
+			ReadOnly: true,
+		})
+		if err != nil {
+			db.Close()
+			rollupDB.Close()
+			return nil, nil, nil, err
+		}
+		return adapters.NewEmbeddedMetricsRepository(db), adapters.NewEmbeddedRollupRepository(rollupDB), adapters.NewEmbeddedLogRepository(logDB), nil
+	}
+	repo := adapters.NewInfluxDBRepository(
+		getEnv("INFLUXDB_URL", "http://localhost:8086"),
+		getEnv("INFLUXDB_TOKEN", "my-super-secret-token"),
+		getEnv("INFLUXDB_ORG", "observability"),
+		getEnv("INFLUXDB_BUCKET", "metrics"),
+	)
+	return repo, repo, repo, nil
+}
+func newSilenceRepository() (ports.SilenceRepository, error) {
+	if getEnv("STORAGE_BACKEND", "influxdb") == "embedded" {
+		return adapters.NewEmbeddedSilenceRepository(filepath.Join(getEnv("EMBEDDED_DATA_DIR", "data/embedded"), "alerts"))
+	}
+	return adapters.NewRedisAlertRepository(getEnv("REDIS_ADDR", "localhost:6379")), nil
+}
+func decodeSilence(r *http.Request, now time.Time) (entities.Silence, error) {
+	var request struct {
+		Matchers  map[string]string `json:"matchers"`
+		StartsAt  time.Time         `json:"starts_at"`
+		EndsAt    time.Time         `json:"ends_at"`
+		Duration  string            `json:"duration"`
+		CreatedBy string            `json:"created_by"`
+		Comment   string            `json:"comment"`
+	}
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&request); err != nil {
+		return entities.Silence{}, fmt.Errorf("invalid silence: %v", err)
+	}
+	silence := entities.Silence{
+		Matchers:  request.Matchers,
+		StartsAt:  request.StartsAt,
+		EndsAt:    request.EndsAt,
+		CreatedBy: request.CreatedBy,
+		Comment:   request.Comment,
+	}
+	if request.Duration != "" {
+		if !request.EndsAt.IsZero() {
+			return silence, fmt.Errorf("set either ends_at or duration, not both")
+		}
+		duration, err := parseLookback(request.Duration, 0)
+		if err != nil {
+			return silence, err
+		}
+		if silence.StartsAt.IsZero() {
+			silence.StartsAt = now
+		}
+		silence.EndsAt = silence.StartsAt.Add(duration)
+	}
+	return silence, nil
+}
+func parseLogQuery(values url.Values, now time.Time) (entities.LogQuery, error) {
+	query := entities.LogQuery{
+		ContainerIDs:   splitList(values.Get("container_id")),
+		ContainerNames: splitList(values.Get("container_name")),
+		Levels:         splitList(values.Get("level")),
+		Contains:       values.Get("q"),
+		Regex:          values.Get("regex"),
+		Cursor:         values.Get("cursor"),
+		End:            now,
+	}
+	for name, value := range values {
+		if label, ok := strings.CutPrefix(name, "label."); ok && label != "" && len(value) > 0 {
+			if query.Labels == nil {
+				query.Labels = make(map[string]string)
+			}
+			query.Labels[label] = value[0]
+		}
+	}
+	if end := values.Get("end"); end != "" {
+		parsed, err := time.Parse(time.RFC3339Nano, end)
+		if err != nil {
+			return query, fmt.Errorf("invalid end %q", end)
+		}
+		query.End = parsed
+	}
+	if start := values.Get("start"); start != "" {
+		parsed, err := time.Parse(time.RFC3339Nano, start)
+		if err != nil {
+			return query, fmt.Errorf("invalid start %q", start)
+		}
+		query.Start = parsed
+	} else {
+		duration, err := parseLookback(values.Get("duration"), 1*time.Hour)
+		if err != nil {
+			return query, err
+		}
+		query.Start = query.End.Add(-duration)
+	}
+	if limit := values.Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n <= 0 {
+			return query, fmt.Errorf("invalid limit %q", limit)
+		}
+		query.Limit = n
+	}
+	return query, query.Validate()
+}
+func parseLookback(value string, defaultValue time.Duration) (time.Duration, error) {
+	if value == "" {
+		return defaultValue, nil
+	}
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("invalid duration %q", value)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 {
+		return 0, fmt.Errorf("invalid duration %q", value)
+	}
+	return duration, nil
+}
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
 	}
 	return defaultValue
+}
+func getEnvInt(key string, defaultValue int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+	if err != nil {
+		return defaultValue
+	}
+	return value
 }
\ No newline at end of file
@@ -0,0 +1,200 @@
+package main
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+	"observability-system/internal/application/usecases"
+	"observability-system/internal/domain/entities"
+	"observability-system/internal/infrastructure/adapters"
+	"observability-system/internal/infrastructure/logparse"
+	"observability-system/internal/infrastructure/logstore"
+)
+func newLogServer(t *testing.T, entries ...*entities.LogEntry) *Server {
+	t.Helper()
+	db, err := logstore.Open(logstore.Options{Dir: t.TempDir()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := adapters.NewEmbeddedLogRepository(db)
+	t.Cleanup(func() { repo.Close() })
+	if err := repo.WriteLogs(context.Background(), entries); err != nil {
+		t.Fatal(err)
+	}
+	return &Server{queryLogs: usecases.NewQueryLogsUseCase(repo)}
+}
+func getLogs(t *testing.T, server *Server, query string) entities.LogPage {
+	t.Helper()
+	recorder := httptest.NewRecorder()
+	server.handleLogs(recorder, httptest.NewRequest(http.MethodGet, "/api/logs?"+query, nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("GET /api/logs?%s: %d %s", query, recorder.Code, recorder.Body.String())
+	}
+	var page entities.LogPage
+	if err := json.NewDecoder(recorder.Body).Decode(&page); err != nil {
+		t.Fatal(err)
+	}
+	return page
+}
+func TestHandleLogsFindsEntriesByParsedField(t *testing.T) {
+	parser, err := logparse.NewParser("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now().UTC().Truncate(time.Second)
+	var entries []*entities.LogEntry
+	for i, line := range []string{
+		`{"msg":"login ok","user_id":"42"}`,
+		`user_id=7 msg="login failed" level=warn`,
+	} {
+		entry := &entities.LogEntry{Timestamp: now.Add(time.Duration(i-2) * time.Second), Source: "docker", ContainerID: "abc", Message: line}
+		parser.Parse(entry)
+		entries = append(entries, entry)
+	}
+	server := newLogServer(t, entries...)
+	page := getLogs(t, server, "label.user_id=42")
+	if len(page.Entries) != 1 || page.Entries[0].Message != "login ok" || page.Entries[0].Fields["user_id"] != "42" {
+		t.Fatalf("expected the parsed entry for user 42, got %+v", page.Entries)
+	}
+	page = getLogs(t, server, "label.user_id=7&level=warn")
+	if len(page.Entries) != 1 || page.Entries[0].Message != "login failed" {
+		t.Fatalf("expected the logfmt entry for user 7, got %+v", page.Entries)
+	}
+	if page := getLogs(t, server, "label.user_id=99"); len(page.Entries) != 0 {
+		t.Fatalf("expected no entries for an unknown field value, got %+v", page.Entries)
+	}
+}
+func TestParseLogQuery(t *testing.T) {
+	now := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+	query, err := parseLogQuery(url.Values{
+		"container_id":   {"abc, def"},
+		"container_name": {"web"},
+		"level":          {"error,warn"},
+		"q":              {"timeout"},
+		"regex":          {`^db\s`},
+		"label.app":      {"api"},
+		"label.user_id":  {"42"},
+		"label.":         {"ignored"},
+		"duration":       {"2d"},
+		"limit":          {"50"},
+	}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(query.ContainerIDs, query.ContainerNames, query.Levels) != "[abc def] [web] [error warn]" {
+		t.Fatalf("unexpected list filters %+v", query)
+	}
+	if query.Contains != "timeout" || query.Regex != `^db\s` || query.Limit != 50 {
+		t.Fatalf("unexpected text filters %+v", query)
+	}
+	if len(query.Labels) != 2 || query.Labels["app"] != "api" || query.Labels["user_id"] != "42" {
+		t.Fatalf("unexpected labels %v", query.Labels)
+	}
+	if !query.End.Equal(now) || !query.Start.Equal(now.Add(-48*time.Hour)) {
+		t.Fatalf("unexpected range %s - %s", query.Start, query.End)
+	}
+	query, err = parseLogQuery(url.Values{"start": {"2024-01-10T10:00:00Z"}, "end": {"2024-01-10T11:00:00.5Z"}}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !query.Start.Equal(now.Add(-2*time.Hour)) || !query.End.Equal(now.Add(-time.Hour+500*time.Millisecond)) {
+		t.Fatalf("unexpected explicit range %s - %s", query.Start, query.End)
+	}
+	if query, err = parseLogQuery(url.Values{}, now); err != nil || !query.Start.Equal(now.Add(-time.Hour)) || query.Limit != 0 {
+		t.Fatalf("expected a one hour default window, got %+v %v", query, err)
+	}
+}
+func TestParseLogQueryRejectsInvalidInput(t *testing.T) {
+	now := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+	for _, values := range []url.Values{
+		{"start": {"yesterday"}},
+		{"end": {"10:00"}},
+		{"start": {"2024-01-10T11:00:00Z"}, "end": {"2024-01-10T10:00:00Z"}},
+		{"duration": {"-1h"}},
+		{"duration": {"0d"}},
+		{"limit": {"0"}},
+		{"limit": {"ten"}},
+		{"limit": {"1001"}},
+		{"regex": {"("}},
+		{"cursor": {"not-a-cursor"}},
+	} {
+		if _, err := parseLogQuery(values, now); err == nil {
+			t.Fatalf("expected %v to be rejected", values)
+		}
+	}
+}
+func serveSilences(server *Server, method, target, body string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(method, target, strings.NewReader(body))
+	if strings.HasPrefix(target, "/api/silences/") {
+		server.handleExpireSilence(recorder, request)
+	} else {
+		server.handleSilences(recorder, request)
+	}
+	return recorder
+}
+func TestHandleSilences(t *testing.T) {
+	repo, err := adapters.NewEmbeddedSilenceRepository(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := &Server{silences: usecases.NewSilenceAlertsUseCase(repo, nil)}
+	for _, tt := range []struct {
+		method, target, body string
+		want                 int
+	}{
+		{http.MethodPost, "/api/silences", `{"matchers":{"container_name":"api-*"},"created_by":"ops"`, http.StatusBadRequest},
+		{http.MethodPost, "/api/silences", `{"matchers":{"container_name":"api-*"},"created_by":"ops","duration":"1h","owner":"x"}`, http.StatusBadRequest},
+		{http.MethodPost, "/api/silences", `{"matchers":{"container_name":"api-*"},"duration":"1h"}`, http.StatusBadRequest},
+		{http.MethodPost, "/api/silences", `{"matchers":{"container_name":"["},"created_by":"ops","duration":"1h"}`, http.StatusBadRequest},
+		{http.MethodPost, "/api/silences", `{"matchers":{"container_name":"api-*"},"created_by":"ops","duration":"1h","ends_at":"2030-01-01T00:00:00Z"}`, http.StatusBadRequest},
+		{http.MethodPut, "/api/silences", ``, http.StatusMethodNotAllowed},
+		{http.MethodGet, "/api/silences/abc", ``, http.StatusMethodNotAllowed},
+		{http.MethodDelete, "/api/silences/", ``, http.StatusBadRequest},
+		{http.MethodDelete, "/api/silences/missing", ``, http.StatusNotFound},
+	} {
+		if recorder := serveSilences(server, tt.method, tt.target, tt.body); recorder.Code != tt.want {
+			t.Errorf("%s %s %s: got %d %s, want %d", tt.method, tt.target, tt.body, recorder.Code, recorder.Body.String(), tt.want)
+		}
+	}
+	recorder := serveSilences(server, http.MethodPost, "/api/silences", `{"matchers":{"container_name":"api-*"},"created_by":"ops","comment":"deploy","duration":"1h"}`)
+	if recorder.Code != http.StatusCreated {
+		t.Fatalf("POST /api/silences: %d %s", recorder.Code, recorder.Body.String())
+	}
+	var created entities.Silence
+	if err := json.NewDecoder(recorder.Body).Decode(&created); err != nil {
+		t.Fatal(err)
+	}
+	if created.ID == "" || created.EndsAt.Sub(created.StartsAt) != time.Hour || created.Comment != "deploy" {
+		t.Fatalf("unexpected silence %+v", created)
+	}
+	listSilences := func(query string) []entities.Silence {
+		recorder := serveSilences(server, http.MethodGet, "/api/silences"+query, "")
+		if recorder.Code != http.StatusOK {
+			t.Fatalf("GET /api/silences%s: %d %s", query, recorder.Code, recorder.Body.String())
+		}
+		var silences []entities.Silence
+		if err := json.NewDecoder(recorder.Body).Decode(&silences); err != nil {
+			t.Fatal(err)
+		}
+		return silences
+	}
+	if silences := listSilences(""); len(silences) != 1 || silences[0].ID != created.ID {
+		t.Fatalf("expected the created silence to be listed, got %+v", silences)
+	}
+	recorder = serveSilences(server, http.MethodDelete, "/api/silences/"+created.ID, "")
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("DELETE /api/silences/%s: %d %s", created.ID, recorder.Code, recorder.Body.String())
+	}
+	if silences := listSilences(""); len(silences) != 0 {
+		t.Fatalf("expected the expired silence to be hidden, got %+v", silences)
+	}
+	if silences := listSilences("?expired=true"); len(silences) != 1 || silences[0].EndsAt.After(time.Now()) {
+		t.Fatalf("expected the expired silence with expired=true, got %+v", silences)
+	}
+}
\ No newline at end of file
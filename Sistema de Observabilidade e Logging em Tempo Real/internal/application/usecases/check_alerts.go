@@ -1,51 +1,247 @@
 package usecases
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log"
+	"sync"
+	"time"
 	"observability-system/internal/domain/entities"
 	"observability-system/internal/domain/ports"
 )
+const (
+	alertStaleAfter        = 15 * time.Minute
+	alertMissedEvaluations = 3
+)
 type CheckAlertsUseCase struct {
-	alertRepo     ports.AlertRepository
-	notifier      ports.Notifier
-	cpuThreshold  float64
-	memThreshold  float64
+	alertRepo ports.AlertRepository
+	stateRepo ports.AlertStateRepository
+	notifier  ports.Notifier
+	rules     []entities.AlertRule
+	maxGap    time.Duration
+	instances map[string]*entities.AlertInstance
+	dirty     map[string]*entities.AlertInstance
+	removed   map[string]bool
+	loaded    bool
+	mu        sync.Mutex
+}
+type sampleGroup struct {
+	labels    map[string]string
+	values    map[string]float64
+	timestamp time.Time
 }
-func NewCheckAlertsUseCase(alertRepo ports.AlertRepository, notifier ports.Notifier, cpuThreshold, memThreshold float64) *CheckAlertsUseCase {
+func NewCheckAlertsUseCase(alertRepo ports.AlertRepository, stateRepo ports.AlertStateRepository, notifier ports.Notifier, rules []entities.AlertRule, interval time.Duration) *CheckAlertsUseCase {
 	return &CheckAlertsUseCase{
-		alertRepo:    alertRepo,
-		notifier:     notifier,
-		cpuThreshold: cpuThreshold,
-		memThreshold: memThreshold,
+		alertRepo: alertRepo,
+		stateRepo: stateRepo,
+		notifier:  notifier,
+		rules:     rules,
+		maxGap:    alertMissedEvaluations * interval,
+		instances: make(map[string]*entities.AlertInstance),
+		dirty:     make(map[string]*entities.AlertInstance),
+		removed:   make(map[string]bool),
+	}
+}
+func (uc *CheckAlertsUseCase) RecordSamples(samples []entities.Sample) {
+	uc.Execute(context.Background(), samples)
+}
+func (uc *CheckAlertsUseCase) Execute(ctx context.Context, samples []entities.Sample) {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+	uc.loadStates(ctx)
+	defer uc.flushStates(ctx)
+	for _, group := range groupSamples(samples) {
+		for _, rule := range uc.rules {
+			if !entities.MatchLabelSelector(rule.Selector, group.labels) {
+				continue
+			}
+			value, ok := rule.Expr.Eval(group.values)
+			if !ok {
+				continue
+			}
+			fingerprint := entities.AlertFingerprint(rule.Name, group.labels)
+			instance, ok := uc.instances[fingerprint]
+			if !rule.Breached(value, ok && instance.State == entities.AlertStateFiring) {
+				if err := uc.resolve(ctx, rule, fingerprint, group.timestamp, value, ""); err != nil {
+					log.Printf("Error resolving alert %s for %s: %v", rule.Name, entities.SeriesKey("", group.labels), err)
+				}
+				continue
+			}
+			if !ok {
+				instance = entities.NewAlertInstance(rule.Name, group.labels)
+				uc.instances[fingerprint] = instance
+			}
+			firing := instance.Observe(group.timestamp, value, rule.For, uc.maxGap)
+			if firing && instance.NotificationDue(group.timestamp, rule.RepeatInterval) {
+				notified, err := uc.fire(ctx, rule, instance, value)
+				if notified {
+					instance.NotifiedAt = group.timestamp
+				}
+				if err != nil {
+					log.Printf("Error firing alert %s for %s: %v", rule.Name, entities.SeriesKey("", group.labels), err)
+				}
+			}
+			uc.saveState(instance)
+		}
 	}
+	uc.resolveStale(ctx, time.Now())
 }
-func (uc *CheckAlertsUseCase) Execute(ctx context.Context, metrics *entities.ContainerMetrics) error {
-	violations := metrics.ExceedsThreshold(uc.cpuThreshold, uc.memThreshold)
-	for _, violation := range violations {
-		alertType := entities.AlertType(violation)
-		inCooldown, err := uc.alertRepo.IsInCooldown(ctx, metrics.ContainerID, alertType)
-		if err != nil {
-			return fmt.Errorf("failed to check cooldown: %w", err)
+func (uc *CheckAlertsUseCase) fire(ctx context.Context, rule entities.AlertRule, instance *entities.AlertInstance, value float64) (bool, error) {
+	alert := uc.newAlert(rule, instance, value)
+	inCooldown, err := uc.alertRepo.IsInCooldown(ctx, alert.ContainerID, alert.Type)
+	if err != nil {
+		return false, fmt.Errorf("failed to check cooldown: %w", err)
+	}
+	if inCooldown {
+		return false, nil
+	}
+	if alert.Message == "" {
+		alert.Message = fmt.Sprintf("%s (%.2f) %s threshold (%.2f)", rule.Expr, value, rule.Comparator, rule.Threshold)
+	}
+	if err := uc.notifier.Notify(ctx, alert); errors.Is(err, ports.ErrSilenced) {
+		return false, nil
+	} else if err != nil {
+		return false, fmt.Errorf("failed to send notification: %w", err)
+	}
+	if err := uc.alertRepo.Save(ctx, alert); err != nil {
+		return true, fmt.Errorf("failed to save alert: %w", err)
+	}
+	if rule.Cooldown > 0 {
+		if err := uc.alertRepo.SetCooldown(ctx, alert.ContainerID, alert.Type, rule.Cooldown); err != nil {
+			return true, fmt.Errorf("failed to set cooldown: %w", err)
+		}
+	}
+	return true, nil
+}
+func (uc *CheckAlertsUseCase) resolve(ctx context.Context, rule entities.AlertRule, fingerprint string, at time.Time, value float64, reason string) error {
+	instance, ok := uc.instances[fingerprint]
+	if !ok {
+		return nil
+	}
+	if instance.Notified() {
+		alert := uc.newAlert(rule, instance, value)
+		alert.Status = entities.AlertStatusResolved
+		if reason != "" {
+			alert.Message = reason
+		} else if alert.Message == "" && rule.ClearThreshold != nil {
+			alert.Message = fmt.Sprintf("%s (%.2f) crossed clear threshold (%.2f)", rule.Expr, value, *rule.ClearThreshold)
+		} else if alert.Message == "" {
+			alert.Message = fmt.Sprintf("%s (%.2f) is back within threshold (%s %.2f)", rule.Expr, value, rule.Comparator, rule.Threshold)
+		}
+		if err := uc.notifier.Notify(ctx, alert); errors.Is(err, ports.ErrSilenced) {
+			log.Printf("Resolved alert %s for %s was silenced", rule.Name, alert.ContainerID)
+		} else if err != nil {
+			return fmt.Errorf("failed to send resolved notification: %w", err)
+		} else if err := uc.alertRepo.Save(ctx, alert); err != nil {
+			log.Printf("Failed to save resolved alert %s: %v", rule.Name, err)
+		}
+	}
+	instance.Resolve(at, value)
+	delete(uc.instances, fingerprint)
+	uc.deleteState(fingerprint)
+	return nil
+}
+func (uc *CheckAlertsUseCase) resolveStale(ctx context.Context, now time.Time) {
+	for fingerprint, instance := range uc.instances {
+		if now.Sub(instance.LastBreachAt) < alertStaleAfter {
+			continue
 		}
-		if inCooldown {
+		rule, ok := uc.rule(instance.Rule)
+		if !ok {
+			delete(uc.instances, fingerprint)
+			uc.deleteState(fingerprint)
 			continue
 		}
-		var value, threshold float64
-		if alertType == entities.AlertTypeCPU {
-			value = metrics.CPUPercent
-			threshold = uc.cpuThreshold
-		} else {
-			value = metrics.MemoryPercent
-			threshold = uc.memThreshold
+		reason := fmt.Sprintf("%s stopped reporting for %s", rule.Expr, alertStaleAfter)
+		if err := uc.resolve(ctx, rule, fingerprint, now, instance.Value, reason); err != nil {
+			log.Printf("Error resolving stale alert %s for %s: %v", rule.Name, entities.SeriesKey("", instance.Labels), err)
 		}
-		alert := entities.NewAlert(metrics.ContainerID, metrics.ContainerName, alertType, value, threshold)
-		alert.Message = fmt.Sprintf("%s usage (%.2f%%) exceeded threshold (%.2f%%)", alertType, value, threshold)
-		if err := uc.alertRepo.Save(ctx, alert); err != nil {
-			return fmt.Errorf("failed to save alert: %w", err)
+	}
+}
+func (uc *CheckAlertsUseCase) newAlert(rule entities.AlertRule, instance *entities.AlertInstance, value float64) *entities.Alert {
+	containerID := instance.ContainerID
+	if containerID == "" {
+		containerID = entities.SeriesKey("", instance.Labels)
+	}
+	alert := entities.NewAlert(containerID, instance.Labels["container_name"], rule.AlertType(), value, rule.Threshold)
+	alert.Unit = rule.Expr.Unit()
+	alert.Fingerprint = instance.Fingerprint
+	alert.StartsAt = instance.FiredAt
+	alert.Severity = rule.Severity
+	alert.Labels = instance.Labels
+	alert.Annotations = rule.RenderAnnotations(value, instance.Labels)
+	alert.Message = alert.Annotations["summary"]
+	return alert
+}
+func (uc *CheckAlertsUseCase) rule(name string) (entities.AlertRule, bool) {
+	for _, rule := range uc.rules {
+		if rule.Name == name {
+			return rule, true
 		}
-		if err := uc.notifier.Notify(ctx, alert); err != nil {
-			return fmt.Errorf("failed to send notification: %w", err)
+	}
+	return entities.AlertRule{}, false
+}
+func (uc *CheckAlertsUseCase) saveState(instance *entities.AlertInstance) {
+	delete(uc.removed, instance.Fingerprint)
+	uc.dirty[instance.Fingerprint] = instance
+}
+func (uc *CheckAlertsUseCase) deleteState(fingerprint string) {
+	delete(uc.dirty, fingerprint)
+	uc.removed[fingerprint] = true
+}
+func (uc *CheckAlertsUseCase) flushStates(ctx context.Context) {
+	if uc.stateRepo == nil || len(uc.dirty) == 0 && len(uc.removed) == 0 {
+		return
+	}
+	saved := make([]*entities.AlertInstance, 0, len(uc.dirty))
+	for _, instance := range uc.dirty {
+		saved = append(saved, instance)
+	}
+	deleted := make([]string, 0, len(uc.removed))
+	for fingerprint := range uc.removed {
+		deleted = append(deleted, fingerprint)
+	}
+	if err := uc.stateRepo.SaveAlertStates(ctx, saved, deleted); err != nil {
+		log.Printf("Failed to persist %d alert states, retrying on next evaluation: %v", len(saved)+len(deleted), err)
+		return
+	}
+	uc.dirty = make(map[string]*entities.AlertInstance)
+	uc.removed = make(map[string]bool)
+}
+func (uc *CheckAlertsUseCase) loadStates(ctx context.Context) {
+	if uc.loaded || uc.stateRepo == nil {
+		return
+	}
+	instances, err := uc.stateRepo.ListAlertStates(ctx)
+	if err != nil {
+		log.Printf("Failed to load alert states, retrying on next evaluation: %v", err)
+		return
+	}
+	for _, instance := range instances {
+		uc.instances[instance.Fingerprint] = instance
+	}
+	uc.loaded = true
+}
+func groupSamples(samples []entities.Sample) []*sampleGroup {
+	var groups []*sampleGroup
+	index := make(map[string]*sampleGroup)
+	for _, sample := range samples {
+		key := entities.SeriesKey("", sample.Labels)
+		group, ok := index[key]
+		if !ok {
+			group = &sampleGroup{labels: sample.Labels, values: make(map[string]float64)}
+			index[key] = group
+			groups = append(groups, group)
+		}
+		group.values[sample.Name] = sample.Value
+		if sample.Timestamp.After(group.timestamp) {
+			group.timestamp = sample.Timestamp
 		}
 	}
-	return nil
+	for _, group := range groups {
+		if group.timestamp.IsZero() {
+			group.timestamp = time.Now()
+		}
+	}
+	return groups
 }
\ No newline at end of file
@@ -0,0 +1,252 @@
+package usecases
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+	"observability-system/internal/domain/entities"
+)
+func cpuSamples(at time.Time, values map[string]float64) []entities.Sample {
+	var samples []entities.Sample
+	for container, value := range values {
+		samples = append(samples, entities.Sample{
+			Name:      "container_cpu_usage_percent",
+			Labels:    map[string]string{"container_id": container, "container_name": container},
+			Value:     value,
+			Timestamp: at,
+		})
+	}
+	return samples
+}
+func TestCheckAlertsBatchesStateWritesPerEvaluation(t *testing.T) {
+	states := newMemAlertStateRepository()
+	rules := []entities.AlertRule{{Name: "CPU", Expr: entities.MustParseMetricExpr("container_cpu_usage_percent"), Comparator: entities.ComparatorGreater, Threshold: 90, For: time.Minute}}
+	uc := NewCheckAlertsUseCase(newMemAlertRepository(), states, &memNotifier{}, rules, 5*time.Second)
+	start := time.Now().Truncate(time.Second)
+	ctx := context.Background()
+	uc.Execute(ctx, cpuSamples(start, map[string]float64{"a": 95, "b": 96, "c": 97}))
+	if states.writes != 1 || len(states.states) != 3 {
+		t.Fatalf("expected one write with 3 pending states, got %d writes and %d states", states.writes, len(states.states))
+	}
+	states.err = errors.New("disk full")
+	uc.Execute(ctx, cpuSamples(start.Add(5*time.Second), map[string]float64{"a": 50, "b": 96, "c": 97}))
+	states.err = nil
+	uc.Execute(ctx, cpuSamples(start.Add(10*time.Second), map[string]float64{"b": 96}))
+	if states.writes != 3 || len(states.states) != 2 {
+		t.Fatalf("expected the failed batch to be retried with the next one, got %d writes and %d states", states.writes, len(states.states))
+	}
+	if _, ok := states.states[entities.AlertFingerprint("CPU", map[string]string{"container_id": "a", "container_name": "a"})]; ok {
+		t.Fatal("expected the resolved instance to be deleted")
+	}
+	uc.Execute(ctx, nil)
+	if states.writes != 3 {
+		t.Fatalf("expected no write when nothing changed, got %d writes", states.writes)
+	}
+}
+func TestCheckAlertsRestartsPendingAfterMissedEvaluations(t *testing.T) {
+	notifier := &memNotifier{}
+	rules := []entities.AlertRule{{Name: "CPU", Expr: entities.MustParseMetricExpr("container_cpu_usage_percent"), Comparator: entities.ComparatorGreater, Threshold: 90, For: time.Minute}}
+	uc := NewCheckAlertsUseCase(newMemAlertRepository(), nil, notifier, rules, 5*time.Second)
+	start := time.Now().Truncate(time.Second)
+	ctx := context.Background()
+	for _, offset := range []time.Duration{0, 5 * time.Second, 50 * time.Second, 65 * time.Second, 105 * time.Second} {
+		uc.Execute(ctx, cpuSamples(start.Add(offset), map[string]float64{"a": 95}))
+	}
+	if len(notifier.alerts) != 0 {
+		t.Fatalf("expected gaps of more than 3 intervals to restart the hold, got %d notifications", len(notifier.alerts))
+	}
+	offset := 105 * time.Second
+	for len(notifier.alerts) == 0 && offset < 5*time.Minute {
+		offset += 5 * time.Second
+		uc.Execute(ctx, cpuSamples(start.Add(offset), map[string]float64{"a": 95}))
+	}
+	if offset != 165*time.Second {
+		t.Fatalf("expected the alert to fire a minute after the last gap, fired after %s", offset)
+	}
+}
+func TestCheckAlertsLifecycle(t *testing.T) {
+	labels := map[string]string{"container_id": "a", "container_name": "a"}
+	fingerprint := entities.AlertFingerprint("CPU", labels)
+	type step struct {
+		at    time.Duration
+		value float64
+	}
+	cases := []struct {
+		name     string
+		rule     entities.AlertRule
+		setup    func(alerts *memAlertRepository, states *memAlertStateRepository, start time.Time)
+		steps    []step
+		statuses []entities.AlertStatus
+		states   int
+	}{
+		{
+			name:     "pending then firing then resolved",
+			rule:     entities.AlertRule{For: 10 * time.Second},
+			steps:    []step{{0, 95}, {5 * time.Second, 95}, {10 * time.Second, 95}, {15 * time.Second, 95}, {20 * time.Second, 95}, {25 * time.Second, 50}},
+			statuses: []entities.AlertStatus{entities.AlertStatusFiring, entities.AlertStatusResolved},
+		},
+		{
+			name: "cooldown suppressed then resolved",
+			rule: entities.AlertRule{Cooldown: time.Hour},
+			setup: func(alerts *memAlertRepository, states *memAlertStateRepository, start time.Time) {
+				alerts.SetCooldown(context.Background(), "a", "CPU", time.Hour)
+			},
+			steps: []step{{0, 95}, {5 * time.Second, 95}, {10 * time.Second, 50}},
+		},
+		{
+			name:     "repeat interval",
+			rule:     entities.AlertRule{RepeatInterval: 10 * time.Second},
+			steps:    []step{{0, 95}, {5 * time.Second, 95}, {10 * time.Second, 95}, {15 * time.Second, 95}, {20 * time.Second, 95}},
+			statuses: []entities.AlertStatus{entities.AlertStatusFiring, entities.AlertStatusFiring, entities.AlertStatusFiring},
+			states:   1,
+		},
+		{
+			name:     "stale resolution",
+			steps:    []step{{-20 * time.Minute, 95}, {0, -1}},
+			statuses: []entities.AlertStatus{entities.AlertStatusFiring, entities.AlertStatusResolved},
+		},
+		{
+			name: "firing state reload",
+			setup: func(alerts *memAlertRepository, states *memAlertStateRepository, start time.Time) {
+				states.states[fingerprint] = entities.AlertInstance{Fingerprint: fingerprint, Rule: "CPU", ContainerID: "a", Labels: labels, State: entities.AlertStateFiring, ActiveAt: start.Add(-time.Minute), LastBreachAt: start.Add(-5 * time.Second), FiredAt: start.Add(-time.Minute), NotifiedAt: start.Add(-time.Minute), Value: 95}
+			},
+			steps:    []step{{0, 95}, {5 * time.Second, 50}},
+			statuses: []entities.AlertStatus{entities.AlertStatusResolved},
+		},
+		{
+			name: "pending state reload",
+			rule: entities.AlertRule{For: time.Minute},
+			setup: func(alerts *memAlertRepository, states *memAlertStateRepository, start time.Time) {
+				states.states[fingerprint] = entities.AlertInstance{Fingerprint: fingerprint, Rule: "CPU", ContainerID: "a", Labels: labels, State: entities.AlertStatePending, ActiveAt: start.Add(-55 * time.Second), LastBreachAt: start.Add(-5 * time.Second), Value: 95}
+			},
+			steps:    []step{{0, 95}, {5 * time.Second, 95}},
+			statuses: []entities.AlertStatus{entities.AlertStatusFiring},
+			states:   1,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rule := tc.rule
+			rule.Name = "CPU"
+			rule.Expr = entities.MustParseMetricExpr("container_cpu_usage_percent")
+			rule.Comparator = entities.ComparatorGreater
+			rule.Threshold = 90
+			alerts := newMemAlertRepository()
+			states := newMemAlertStateRepository()
+			notifier := &memNotifier{}
+			start := time.Now().Truncate(time.Second)
+			if tc.setup != nil {
+				tc.setup(alerts, states, start)
+			}
+			uc := NewCheckAlertsUseCase(alerts, states, notifier, []entities.AlertRule{rule}, 5*time.Second)
+			for _, s := range tc.steps {
+				var samples []entities.Sample
+				if s.value >= 0 {
+					samples = cpuSamples(start.Add(s.at), map[string]float64{"a": s.value})
+				}
+				uc.Execute(context.Background(), samples)
+			}
+			var statuses []entities.AlertStatus
+			for _, alert := range notifier.alerts {
+				statuses = append(statuses, alert.Status)
+			}
+			if fmt.Sprint(statuses) != fmt.Sprint(tc.statuses) {
+				t.Fatalf("expected notifications %v, got %v", tc.statuses, statuses)
+			}
+			if len(states.states) != tc.states {
+				t.Fatalf("expected %d persisted states, got %d", tc.states, len(states.states))
+			}
+		})
+	}
+}
+func TestCheckAlertsKeepsStateUntilResolutionIsDelivered(t *testing.T) {
+	states := newMemAlertStateRepository()
+	notifier := &memNotifier{}
+	rules := []entities.AlertRule{{Name: "CPU", Expr: entities.MustParseMetricExpr("container_cpu_usage_percent"), Comparator: entities.ComparatorGreater, Threshold: 90}}
+	uc := NewCheckAlertsUseCase(newMemAlertRepository(), states, notifier, rules, 5*time.Second)
+	start := time.Now().Truncate(time.Second)
+	ctx := context.Background()
+	uc.Execute(ctx, cpuSamples(start, map[string]float64{"a": 95, "b": 95}))
+	notifier.err = errors.New("webhook down")
+	uc.Execute(ctx, cpuSamples(start.Add(5*time.Second), map[string]float64{"a": 50, "b": 50}))
+	if len(states.states) != 2 {
+		t.Fatalf("expected both states to survive the failed resolution, got %d", len(states.states))
+	}
+	notifier.err = nil
+	uc.Execute(ctx, cpuSamples(start.Add(10*time.Second), map[string]float64{"a": 50, "b": 50}))
+	if len(notifier.alerts) != 4 || notifier.alerts[2].Status != entities.AlertStatusResolved || notifier.alerts[3].Status != entities.AlertStatusResolved {
+		t.Fatalf("expected both resolutions to be retried, got %d notifications", len(notifier.alerts))
+	}
+	if len(states.states) != 0 {
+		t.Fatalf("expected the resolved states to be deleted, got %d", len(states.states))
+	}
+}
+func TestCheckAlertsResolvesOnlyBelowClearThreshold(t *testing.T) {
+	clearThreshold := 80.0
+	states := newMemAlertStateRepository()
+	notifier := &memNotifier{}
+	rules := []entities.AlertRule{{Name: "CPU", Expr: entities.MustParseMetricExpr("container_cpu_usage_percent"), Comparator: entities.ComparatorGreater, Threshold: 90, ClearThreshold: &clearThreshold}}
+	uc := NewCheckAlertsUseCase(newMemAlertRepository(), states, notifier, rules, 5*time.Second)
+	start := time.Now().Truncate(time.Second)
+	ctx := context.Background()
+	uc.Execute(ctx, cpuSamples(start, map[string]float64{"a": 95}))
+	if len(notifier.alerts) != 1 || notifier.alerts[0].Status != entities.AlertStatusFiring {
+		t.Fatalf("expected the alert to fire above the threshold, got %+v", notifier.alerts)
+	}
+	uc.Execute(ctx, cpuSamples(start.Add(5*time.Second), map[string]float64{"a": 85}))
+	if len(notifier.alerts) != 1 {
+		t.Fatalf("expected no resolution between the clear threshold and the threshold, got %+v", notifier.alerts[1:])
+	}
+	if len(states.states) != 1 {
+		t.Fatalf("expected the instance to stay firing, got %d states", len(states.states))
+	}
+	uc.Execute(ctx, cpuSamples(start.Add(10*time.Second), map[string]float64{"a": 79}))
+	if len(notifier.alerts) != 2 || notifier.alerts[1].Status != entities.AlertStatusResolved {
+		t.Fatalf("expected the alert to resolve below the clear threshold, got %+v", notifier.alerts)
+	}
+	if notifier.alerts[1].Message != "container_cpu_usage_percent (79.00) crossed clear threshold (80.00)" {
+		t.Fatalf("unexpected resolved message %q", notifier.alerts[1].Message)
+	}
+	if len(states.states) != 0 {
+		t.Fatalf("expected the resolved state to be deleted, got %d", len(states.states))
+	}
+}
+func TestCheckAlertsDoesNotRecordSilencedNotifications(t *testing.T) {
+	ctx := context.Background()
+	silences := newMemSilenceRepository()
+	silencer := NewSilenceAlertsUseCase(silences, nil)
+	silences.silences["cpu"] = entities.Silence{ID: "cpu", Matchers: map[string]string{"alertname": "CPU"}, StartsAt: time.Now().Add(-time.Minute), EndsAt: time.Now().Add(time.Hour)}
+	alerts := newMemAlertRepository()
+	states := newMemAlertStateRepository()
+	notifier := &memNotifier{silencer: silencer}
+	rules := []entities.AlertRule{{Name: "CPU", Expr: entities.MustParseMetricExpr("container_cpu_usage_percent"), Comparator: entities.ComparatorGreater, Threshold: 90, Cooldown: time.Hour}}
+	uc := NewCheckAlertsUseCase(alerts, states, notifier, rules, 5*time.Second)
+	start := time.Now().Truncate(time.Second)
+	uc.Execute(ctx, cpuSamples(start, map[string]float64{"a": 95}))
+	uc.Execute(ctx, cpuSamples(start.Add(5*time.Second), map[string]float64{"a": 50}))
+	uc.Execute(ctx, cpuSamples(start.Add(10*time.Second), map[string]float64{"a": 95}))
+	if len(notifier.alerts) != 0 || len(alerts.alerts) != 0 || len(alerts.cooldowns) != 0 {
+		t.Fatalf("expected silenced alerts to be neither delivered nor recorded, got %d notified, %d saved, %d cooldowns", len(notifier.alerts), len(alerts.alerts), len(alerts.cooldowns))
+	}
+	for _, instance := range states.states {
+		if !instance.NotifiedAt.IsZero() {
+			t.Fatalf("expected the silenced instance to stay un-notified, got %+v", instance)
+		}
+	}
+	if _, err := silencer.Expire(ctx, "cpu"); err != nil {
+		t.Fatal(err)
+	}
+	uc.Execute(ctx, cpuSamples(start.Add(15*time.Second), map[string]float64{"a": 95}))
+	uc.Execute(ctx, cpuSamples(start.Add(20*time.Second), map[string]float64{"a": 50}))
+	var statuses []entities.AlertStatus
+	for _, alert := range notifier.alerts {
+		statuses = append(statuses, alert.Status)
+	}
+	if fmt.Sprint(statuses) != fmt.Sprint([]entities.AlertStatus{entities.AlertStatusFiring, entities.AlertStatusResolved}) {
+		t.Fatalf("expected the alert to fire once unsilenced and then resolve, got %v", statuses)
+	}
+	if len(alerts.alerts) != 2 || len(alerts.cooldowns) != 1 {
+		t.Fatalf("expected the delivered alerts to be recorded, got %d saved, %d cooldowns", len(alerts.alerts), len(alerts.cooldowns))
+	}
+}
\ No newline at end of file
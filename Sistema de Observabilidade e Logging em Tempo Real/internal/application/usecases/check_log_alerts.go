@@ -0,0 +1,117 @@
+package usecases
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"unicode/utf8"
+	"observability-system/internal/domain/entities"
+	"observability-system/internal/domain/ports"
+)
+const logAlertSampleLength = 200
+type CheckLogAlertsUseCase struct {
+	rules     []entities.LogAlertRule
+	alertRepo ports.AlertRepository
+	notifier  ports.Notifier
+	windows   map[string][]time.Time
+}
+func NewCheckLogAlertsUseCase(rules []entities.LogAlertRule, alertRepo ports.AlertRepository, notifier ports.Notifier) *CheckLogAlertsUseCase {
+	return &CheckLogAlertsUseCase{
+		rules:     rules,
+		alertRepo: alertRepo,
+		notifier:  notifier,
+		windows:   make(map[string][]time.Time),
+	}
+}
+func (uc *CheckLogAlertsUseCase) WriteLogs(ctx context.Context, entries []*entities.LogEntry) error {
+	return uc.Execute(ctx, entries)
+}
+func (uc *CheckLogAlertsUseCase) Execute(ctx context.Context, entries []*entities.LogEntry) error {
+	var firstErr error
+	var latest time.Time
+	for _, entry := range entries {
+		if entry.Timestamp.After(latest) {
+			latest = entry.Timestamp
+		}
+		for _, rule := range uc.rules {
+			if !rule.Matches(entry) {
+				continue
+			}
+			if err := uc.observe(ctx, rule, entry); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	uc.expire(latest)
+	return firstErr
+}
+func (uc *CheckLogAlertsUseCase) expire(now time.Time) {
+	var longest time.Duration
+	for _, rule := range uc.rules {
+		longest = max(longest, rule.Window)
+	}
+	if longest == 0 {
+		return
+	}
+	for key, window := range uc.windows {
+		if now.Sub(window[len(window)-1]) > longest {
+			delete(uc.windows, key)
+		}
+	}
+}
+func (uc *CheckLogAlertsUseCase) observe(ctx context.Context, rule entities.LogAlertRule, entry *entities.LogEntry) error {
+	subjectID, subjectName := entities.LogAlertSubject(entry)
+	key := rule.Name + "\x00" + subjectID
+	window := append(uc.windows[key], entry.Timestamp)
+	if len(window) > rule.Threshold+1 {
+		window = window[len(window)-rule.Threshold-1:]
+	}
+	for len(window) > 0 && rule.Window > 0 && entry.Timestamp.Sub(window[0]) > rule.Window {
+		window = window[1:]
+	}
+	if len(window) <= rule.Threshold {
+		uc.windows[key] = window
+		return nil
+	}
+	delete(uc.windows, key)
+	alertType := rule.AlertType()
+	inCooldown, err := uc.alertRepo.IsInCooldown(ctx, subjectID, alertType)
+	if err != nil {
+		return fmt.Errorf("failed to check cooldown: %w", err)
+	}
+	if inCooldown {
+		return nil
+	}
+	alert := entities.NewAlert(subjectID, subjectName, alertType, float64(len(window)), float64(rule.Threshold))
+	alert.Unit = "lines"
+	sample := truncateSample(entry.Message, logAlertSampleLength)
+	if rule.Threshold == 0 {
+		alert.Message = fmt.Sprintf("log rule %s matched: %s", rule.Name, sample)
+	} else {
+		alert.Message = fmt.Sprintf("log rule %s matched more than %d lines within %s: %s", rule.Name, rule.Threshold, rule.Window, sample)
+	}
+	if err := uc.notifier.Notify(ctx, alert); errors.Is(err, ports.ErrSilenced) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to send notification: %w", err)
+	}
+	if err := uc.alertRepo.Save(ctx, alert); err != nil {
+		return fmt.Errorf("failed to save alert: %w", err)
+	}
+	if rule.Cooldown > 0 {
+		if err := uc.alertRepo.SetCooldown(ctx, subjectID, alertType, rule.Cooldown); err != nil {
+			return fmt.Errorf("failed to set cooldown: %w", err)
+		}
+	}
+	return nil
+}
+func truncateSample(text string, limit int) string {
+	if len(text) <= limit {
+		return text
+	}
+	cut := limit
+	for cut > 0 && !utf8.RuneStart(text[cut]) {
+		cut--
+	}
+	return text[:cut] + "..."
+}
\ No newline at end of file
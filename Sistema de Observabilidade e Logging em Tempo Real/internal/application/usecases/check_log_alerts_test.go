@@ -0,0 +1,158 @@
+package usecases
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+	"observability-system/internal/domain/entities"
+)
+func TestTruncateSampleKeepsRunesWhole(t *testing.T) {
+	for _, tc := range []struct {
+		text     string
+		limit    int
+		expected string
+	}{
+		{"short", 10, "short"},
+		{"exactly10!", 10, "exactly10!"},
+		{"abcdefghijk", 10, "abcdefghij..."},
+		{"ação concluída", 2, "a..."},
+		{"日本語のログ", 4, "日..."},
+		{"🚨 alerta", 2, "..."},
+	} {
+		got := truncateSample(tc.text, tc.limit)
+		if got != tc.expected {
+			t.Fatalf("truncateSample(%q, %d): expected %q, got %q", tc.text, tc.limit, tc.expected, got)
+		}
+		if !utf8.ValidString(got) || len(strings.TrimSuffix(got, "...")) > tc.limit {
+			t.Fatalf("truncateSample(%q, %d) produced %q", tc.text, tc.limit, got)
+		}
+	}
+}
+func logLine(at time.Time, containerID, message string) *entities.LogEntry {
+	return &entities.LogEntry{Timestamp: at, ContainerID: containerID, ContainerName: containerID + "-name", Message: message}
+}
+func TestCheckLogAlertsCountsMatchesWithinWindow(t *testing.T) {
+	ctx := context.Background()
+	base := time.Now()
+	repo, notifier := newMemAlertRepository(), &memNotifier{}
+	rule := entities.LogAlertRule{Name: "timeouts", Pattern: regexp.MustCompile("timeout"), Threshold: 2, Window: time.Minute}
+	uc := NewCheckLogAlertsUseCase([]entities.LogAlertRule{rule}, repo, notifier)
+	err := uc.Execute(ctx, []*entities.LogEntry{
+		logLine(base, "c1", "db timeout"),
+		logLine(base.Add(10*time.Second), "c1", "request served"),
+		logLine(base.Add(20*time.Second), "c1", "db timeout"),
+		logLine(base.Add(90*time.Second), "c1", "db timeout"),
+		logLine(base.Add(100*time.Second), "c2", "db timeout"),
+		logLine(base.Add(110*time.Second), "c2", "db timeout"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(notifier.alerts) != 0 {
+		t.Fatalf("expected no alert without more than 2 matches per container within a minute, got %+v", notifier.alerts)
+	}
+	err = uc.Execute(ctx, []*entities.LogEntry{
+		logLine(base.Add(100*time.Second), "c1", "db timeout"),
+		logLine(base.Add(110*time.Second), "c1", "db timeout again"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(notifier.alerts) != 1 || len(repo.alerts) != 1 {
+		t.Fatalf("expected one alert once c1 matched 3 times within a minute, got %d notified, %d saved", len(notifier.alerts), len(repo.alerts))
+	}
+	alert := notifier.alerts[0]
+	if alert.ContainerID != "c1" || alert.ContainerName != "c1-name" || alert.Type != rule.AlertType() || alert.Value != 3 || alert.Threshold != 2 || alert.Unit != "lines" {
+		t.Fatalf("unexpected alert %+v", alert)
+	}
+	if !strings.Contains(alert.Message, "more than 2 lines within 1m0s: db timeout again") {
+		t.Fatalf("unexpected alert message %q", alert.Message)
+	}
+}
+func TestCheckLogAlertsFiresOnAnyMatchWithZeroThreshold(t *testing.T) {
+	repo, notifier := newMemAlertRepository(), &memNotifier{}
+	rule := entities.LogAlertRule{Name: "fatal", Selector: map[string]string{"level": "FATAL"}}
+	uc := NewCheckLogAlertsUseCase([]entities.LogAlertRule{rule}, repo, notifier)
+	info := logLine(time.Now(), "c1", "all good")
+	info.Level = "INFO"
+	fatal := logLine(time.Now(), "c1", "out of memory")
+	fatal.Level = "FATAL"
+	if err := uc.Execute(context.Background(), []*entities.LogEntry{info, fatal}); err != nil {
+		t.Fatal(err)
+	}
+	if len(notifier.alerts) != 1 || notifier.alerts[0].Message != "log rule fatal matched: out of memory" {
+		t.Fatalf("expected a single alert for the FATAL line, got %+v", notifier.alerts)
+	}
+}
+func TestCheckLogAlertsCooldownSuppressesRepeats(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	repo, notifier := newMemAlertRepository(), &memNotifier{}
+	repo.now = func() time.Time { return now }
+	rule := entities.LogAlertRule{Name: "panic", Pattern: regexp.MustCompile("panic"), Cooldown: 5 * time.Minute}
+	uc := NewCheckLogAlertsUseCase([]entities.LogAlertRule{rule}, repo, notifier)
+	for i := 0; i < 3; i++ {
+		if err := uc.Execute(ctx, []*entities.LogEntry{logLine(now, "c1", "panic: nil map")}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := uc.Execute(ctx, []*entities.LogEntry{logLine(now, "c2", "panic: nil map")}); err != nil {
+		t.Fatal(err)
+	}
+	if len(notifier.alerts) != 2 || notifier.alerts[0].ContainerID != "c1" || notifier.alerts[1].ContainerID != "c2" {
+		t.Fatalf("expected one alert per container while in cooldown, got %+v", notifier.alerts)
+	}
+	now = now.Add(6 * time.Minute)
+	if err := uc.Execute(ctx, []*entities.LogEntry{logLine(now, "c1", "panic: nil map")}); err != nil {
+		t.Fatal(err)
+	}
+	if len(notifier.alerts) != 3 {
+		t.Fatalf("expected the rule to fire again after the cooldown, got %d alerts", len(notifier.alerts))
+	}
+}
+func TestCheckLogAlertsDoesNotRecordUndeliveredAlerts(t *testing.T) {
+	ctx := context.Background()
+	repo, notifier := newMemAlertRepository(), &memNotifier{err: errors.New("webhook down")}
+	rule := entities.LogAlertRule{Name: "panic", Pattern: regexp.MustCompile("panic"), Cooldown: 5 * time.Minute}
+	uc := NewCheckLogAlertsUseCase([]entities.LogAlertRule{rule}, repo, notifier)
+	if err := uc.Execute(ctx, []*entities.LogEntry{logLine(time.Now(), "c1", "panic")}); err == nil {
+		t.Fatal("expected the notification error to be returned")
+	}
+	if len(repo.alerts) != 0 || len(repo.cooldowns) != 0 {
+		t.Fatalf("expected no saved alert or cooldown for an undelivered notification, got %d alerts, %d cooldowns", len(repo.alerts), len(repo.cooldowns))
+	}
+	notifier.err = nil
+	if err := uc.Execute(ctx, []*entities.LogEntry{logLine(time.Now(), "c1", "panic")}); err != nil {
+		t.Fatal(err)
+	}
+	if len(notifier.alerts) != 1 || len(repo.alerts) != 1 {
+		t.Fatalf("expected the next match to be delivered, got %d notified, %d saved", len(notifier.alerts), len(repo.alerts))
+	}
+}
+func TestCheckLogAlertsDoesNotRecordSilencedAlerts(t *testing.T) {
+	ctx := context.Background()
+	silences := newMemSilenceRepository()
+	silencer := NewSilenceAlertsUseCase(silences, nil)
+	silences.silences["c1"] = entities.Silence{ID: "c1", Matchers: map[string]string{"container_name": "c1-*"}, StartsAt: time.Now().Add(-time.Minute), EndsAt: time.Now().Add(time.Hour)}
+	repo, notifier := newMemAlertRepository(), &memNotifier{silencer: silencer}
+	rule := entities.LogAlertRule{Name: "panic", Pattern: regexp.MustCompile("panic"), Cooldown: 5 * time.Minute}
+	uc := NewCheckLogAlertsUseCase([]entities.LogAlertRule{rule}, repo, notifier)
+	if err := uc.Execute(ctx, []*entities.LogEntry{logLine(time.Now(), "c1", "panic")}); err != nil {
+		t.Fatal(err)
+	}
+	if len(notifier.alerts) != 0 || len(repo.alerts) != 0 || len(repo.cooldowns) != 0 {
+		t.Fatalf("expected a silenced alert to be neither delivered nor recorded, got %d notified, %d saved, %d cooldowns", len(notifier.alerts), len(repo.alerts), len(repo.cooldowns))
+	}
+	if _, err := silencer.Expire(ctx, "c1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := uc.Execute(ctx, []*entities.LogEntry{logLine(time.Now(), "c1", "panic")}); err != nil {
+		t.Fatal(err)
+	}
+	if len(notifier.alerts) != 1 || len(repo.alerts) != 1 || len(repo.cooldowns) != 1 {
+		t.Fatalf("expected the alert to be delivered once unsilenced, got %d notified, %d saved, %d cooldowns", len(notifier.alerts), len(repo.alerts), len(repo.cooldowns))
+	}
+}
\ No newline at end of file
@@ -0,0 +1,112 @@
+package usecases
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+	"observability-system/internal/domain/entities"
+	"observability-system/internal/domain/ports"
+)
+type CollectLogsUseCase struct {
+	sources       []ports.LogSource
+	aggregator    ports.LogAggregator
+	parser        ports.LogParser
+	redactor      ports.Redactor
+	sink          ports.LogSink
+	acknowledgers []ports.LogAcknowledger
+	batchSize     int
+	flushInterval time.Duration
+}
+func NewCollectLogsUseCase(sources []ports.LogSource, aggregator ports.LogAggregator, parser ports.LogParser, redactor ports.Redactor, sink ports.LogSink, batchSize int, flushInterval time.Duration) *CollectLogsUseCase {
+	if batchSize <= 0 {
+		batchSize = 500
+	}
+	if flushInterval <= 0 {
+		flushInterval = time.Second
+	}
+	var acknowledgers []ports.LogAcknowledger
+	for _, source := range sources {
+		if acknowledger, ok := source.(ports.LogAcknowledger); ok {
+			acknowledgers = append(acknowledgers, acknowledger)
+		}
+	}
+	return &CollectLogsUseCase{
+		sources:       sources,
+		aggregator:    aggregator,
+		parser:        parser,
+		redactor:      redactor,
+		sink:          sink,
+		acknowledgers: acknowledgers,
+		batchSize:     batchSize,
+		flushInterval: flushInterval,
+	}
+}
+func (uc *CollectLogsUseCase) Execute(ctx context.Context) {
+	entries := make(chan *entities.LogEntry, uc.batchSize)
+	var wg sync.WaitGroup
+	for _, source := range uc.sources {
+		wg.Add(1)
+		go func(source ports.LogSource) {
+			defer wg.Done()
+			if err := source.Run(ctx, entries); err != nil && ctx.Err() == nil {
+				log.Printf("Log source stopped: %v", err)
+			}
+		}(source)
+	}
+	go func() {
+		wg.Wait()
+		close(entries)
+	}()
+	ticker := time.NewTicker(uc.flushInterval)
+	defer ticker.Stop()
+	batch := make([]*entities.LogEntry, 0, uc.batchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := uc.sink.WriteLogs(context.WithoutCancel(ctx), batch); err != nil {
+			log.Printf("Failed to write %d log entries: %v", len(batch), err)
+		} else {
+			for _, acknowledger := range uc.acknowledgers {
+				acknowledger.Acknowledge(batch)
+			}
+		}
+		batch = make([]*entities.LogEntry, 0, uc.batchSize)
+	}
+	emit := func(completed []*entities.LogEntry) {
+		for _, entry := range completed {
+			if uc.parser != nil {
+				uc.parser.Parse(entry)
+			}
+			if uc.redactor != nil {
+				uc.redactor.RedactLog(entry)
+			}
+			batch = append(batch, entry)
+			if len(batch) >= uc.batchSize {
+				flush()
+			}
+		}
+	}
+	for {
+		select {
+		case entry, ok := <-entries:
+			if !ok {
+				if uc.aggregator != nil {
+					emit(uc.aggregator.Drain())
+				}
+				flush()
+				return
+			}
+			if uc.aggregator != nil {
+				emit(uc.aggregator.Add(entry))
+			} else {
+				emit([]*entities.LogEntry{entry})
+			}
+		case now := <-ticker.C:
+			if uc.aggregator != nil {
+				emit(uc.aggregator.Expire(now))
+			}
+			flush()
+		}
+	}
+}
\ No newline at end of file
@@ -0,0 +1,55 @@
+package usecases
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"observability-system/internal/domain/entities"
+	"observability-system/internal/domain/ports"
+)
+type staticLogSource struct {
+	entries []*entities.LogEntry
+	acked   []*entities.LogEntry
+}
+func (s *staticLogSource) Run(ctx context.Context, entries chan<- *entities.LogEntry) error {
+	for _, entry := range s.entries {
+		entries <- entry
+	}
+	return nil
+}
+func (s *staticLogSource) Close() error {
+	return nil
+}
+func (s *staticLogSource) Acknowledge(entries []*entities.LogEntry) {
+	s.acked = append(s.acked, entries...)
+}
+type failingLogSink struct {
+	err    error
+	writes int
+}
+func (s *failingLogSink) WriteLogs(ctx context.Context, entries []*entities.LogEntry) error {
+	s.writes++
+	return s.err
+}
+func TestCollectLogsAcknowledgesOnlyStoredEntries(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		err   error
+		acked int
+	}{
+		{"stored", nil, 2},
+		{"write failed", errors.New("backend down"), 0},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			source := &staticLogSource{entries: []*entities.LogEntry{{Message: "a"}, {Message: "b"}}}
+			sink := &failingLogSink{err: tc.err}
+			NewCollectLogsUseCase([]ports.LogSource{source}, nil, nil, nil, sink, 10, time.Hour).Execute(context.Background())
+			if sink.writes != 1 {
+				t.Fatalf("expected one write, got %d", sink.writes)
+			}
+			if len(source.acked) != tc.acked {
+				t.Fatalf("expected %d acknowledged entries, got %d", tc.acked, len(source.acked))
+			}
+		})
+	}
+}
\ No newline at end of file
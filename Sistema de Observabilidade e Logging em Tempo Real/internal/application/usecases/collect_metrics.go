@@ -15,25 +15,25 @@ func NewCollectMetricsUseCase(collector ports.ContainerCollector, repository por
 		repository: repository,
 	}
 }
-func (uc *CollectMetricsUseCase) Execute(ctx context.Context) ([]*entities.ContainerMetrics, error) {
+func (uc *CollectMetricsUseCase) Execute(ctx context.Context) ([]entities.Sample, error) {
 	containers, err := uc.collector.ListContainers(ctx)
 	if err != nil {
 		return nil, err
 	}
-	var allMetrics []*entities.ContainerMetrics
+	var allSamples []entities.Sample
 	for _, containerID := range containers {
-		metrics, err := uc.collector.CollectMetrics(ctx, containerID)
+		samples, err := uc.collector.CollectSamples(ctx, containerID)
 		if err != nil {
 			log.Printf("Failed to collect metrics for %s: %v", containerID, err)
 			continue
 		}
-		if metrics == nil {
+		if len(samples) == 0 {
 			continue
 		}
-		if err := uc.repository.Save(ctx, metrics); err != nil {
+		if err := uc.repository.Save(ctx, samples); err != nil {
 			log.Printf("Failed to save metrics for %s: %v", containerID, err)
 		}
-		allMetrics = append(allMetrics, metrics)
+		allSamples = append(allSamples, samples...)
 	}
-	return allMetrics, nil
+	return allSamples, nil
 }
\ No newline at end of file
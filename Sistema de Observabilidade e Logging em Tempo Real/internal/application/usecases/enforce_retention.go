@@ -0,0 +1,44 @@
+package usecases
+import (
+	"context"
+	"fmt"
+	"time"
+	"observability-system/internal/domain/entities"
+	"observability-system/internal/domain/ports"
+)
+type EnforceRetentionUseCase struct {
+	rules       []entities.RetentionRule
+	metricStore ports.MetricsRetentionStore
+	alertStore  ports.AlertRetentionStore
+}
+func NewEnforceRetentionUseCase(rules []entities.RetentionRule, metricStore ports.MetricsRetentionStore, alertStore ports.AlertRetentionStore) *EnforceRetentionUseCase {
+	return &EnforceRetentionUseCase{
+		rules:       rules,
+		metricStore: metricStore,
+		alertStore:  alertStore,
+	}
+}
+func (uc *EnforceRetentionUseCase) Execute(ctx context.Context, now time.Time) ([]entities.RetentionResult, error) {
+	var results []entities.RetentionResult
+	for _, rule := range uc.rules {
+		before := now.Add(-rule.MaxAge)
+		var deleted int64
+		var err error
+		if rule.Tier == entities.RetentionTierAlerts {
+			if uc.alertStore == nil {
+				continue
+			}
+			deleted, err = uc.alertStore.DeleteAlertsBefore(ctx, before)
+		} else {
+			if uc.metricStore == nil {
+				continue
+			}
+			deleted, err = uc.metricStore.DeleteMetrics(ctx, rule.Tier, rule.Selector, before)
+		}
+		if err != nil {
+			return results, fmt.Errorf("failed to apply retention %s: %w", rule, err)
+		}
+		results = append(results, entities.RetentionResult{Rule: rule, Before: before, Deleted: deleted})
+	}
+	return results, nil
+}
\ No newline at end of file
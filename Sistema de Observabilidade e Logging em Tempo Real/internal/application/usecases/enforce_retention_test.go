@@ -0,0 +1,58 @@
+package usecases
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+	"observability-system/internal/domain/entities"
+)
+type recordingRetentionStore struct {
+	calls []string
+	err   error
+}
+func (s *recordingRetentionStore) DeleteMetrics(ctx context.Context, tier string, selector map[string]string, before time.Time) (int64, error) {
+	rule := entities.RetentionRule{Tier: tier, Selector: selector}
+	s.calls = append(s.calls, fmt.Sprintf("%s<%s", rule, before.Format(time.RFC3339)))
+	return int64(len(s.calls)), s.err
+}
+func (s *recordingRetentionStore) DeleteAlertsBefore(ctx context.Context, before time.Time) (int64, error) {
+	s.calls = append(s.calls, "alerts<"+before.Format(time.RFC3339))
+	return 10, s.err
+}
+func TestEnforceRetentionRoutesRulesToStores(t *testing.T) {
+	rules, err := entities.ParseRetentionRules("raw=7d; raw{env=dev}=1d; 1h=30d; alerts=1d")
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	metrics, alerts := &recordingRetentionStore{}, &recordingRetentionStore{}
+	results, err := NewEnforceRetentionUseCase(rules, metrics, alerts).Execute(context.Background(), now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := fmt.Sprint(metrics.calls); got != "[raw<2024-01-25T00:00:00Z raw{env=dev}<2024-01-31T00:00:00Z 1h<2024-01-02T00:00:00Z]" {
+		t.Fatalf("unexpected metric deletes %s", got)
+	}
+	if got := fmt.Sprint(alerts.calls); got != "[alerts<2024-01-31T00:00:00Z]" {
+		t.Fatalf("unexpected alert deletes %s", got)
+	}
+	if len(results) != 4 || results[1].Deleted != 2 || results[3].Deleted != 10 || !results[3].Before.Equal(now.Add(-24*time.Hour)) {
+		t.Fatalf("unexpected results %+v", results)
+	}
+}
+func TestEnforceRetentionSkipsMissingStoresAndStopsOnError(t *testing.T) {
+	rules, err := entities.ParseRetentionRules("alerts=1d; raw=7d; 1m=30d")
+	if err != nil {
+		t.Fatal(err)
+	}
+	failing := &recordingRetentionStore{err: errors.New("delete failed")}
+	results, err := NewEnforceRetentionUseCase(rules, failing, nil).Execute(context.Background(), time.Now())
+	if err == nil || len(failing.calls) != 1 || len(results) != 0 {
+		t.Fatalf("expected the first metric delete error to stop enforcement, got %v %v %+v", err, failing.calls, results)
+	}
+	results, err = NewEnforceRetentionUseCase(rules, nil, nil).Execute(context.Background(), time.Now())
+	if err != nil || len(results) != 0 {
+		t.Fatalf("expected rules without a store to be skipped, got %v %+v", err, results)
+	}
+}
\ No newline at end of file
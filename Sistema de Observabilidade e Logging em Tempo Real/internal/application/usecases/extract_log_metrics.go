@@ -0,0 +1,42 @@
+package usecases
+import (
+	"context"
+	"fmt"
+	"time"
+	"observability-system/internal/domain/entities"
+	"observability-system/internal/domain/ports"
+)
+type ExtractLogMetricsUseCase struct {
+	extractor  ports.LogMetricExtractor
+	repository ports.MetricsRepository
+	recorders  []ports.SampleRecorder
+	now        func() time.Time
+}
+func NewExtractLogMetricsUseCase(extractor ports.LogMetricExtractor, repository ports.MetricsRepository, recorders ...ports.SampleRecorder) *ExtractLogMetricsUseCase {
+	return &ExtractLogMetricsUseCase{
+		extractor:  extractor,
+		repository: repository,
+		recorders:  recorders,
+		now:        time.Now,
+	}
+}
+func (uc *ExtractLogMetricsUseCase) WriteLogs(ctx context.Context, entries []*entities.LogEntry) error {
+	_, err := uc.Execute(ctx, entries)
+	return err
+}
+func (uc *ExtractLogMetricsUseCase) Execute(ctx context.Context, entries []*entities.LogEntry) ([]entities.Sample, error) {
+	for _, entry := range entries {
+		uc.extractor.Observe(entry)
+	}
+	samples := uc.extractor.Collect(uc.now())
+	if len(samples) == 0 {
+		return nil, nil
+	}
+	for _, recorder := range uc.recorders {
+		recorder.RecordSamples(samples)
+	}
+	if err := uc.repository.Save(ctx, samples); err != nil {
+		return samples, fmt.Errorf("failed to save log metrics: %w", err)
+	}
+	return samples, nil
+}
\ No newline at end of file
@@ -0,0 +1,206 @@
+package usecases
+import (
+	"context"
+	"sort"
+	"time"
+	"observability-system/internal/domain/entities"
+	"observability-system/internal/domain/ports"
+)
+type memMetricsRepository struct {
+	series []*entities.Series
+	now    func() time.Time
+}
+func (r *memMetricsRepository) add(name string, labels map[string]string, at time.Time, value float64) {
+	for _, s := range r.series {
+		if entities.SeriesKey(s.Name, s.Labels) == entities.SeriesKey(name, labels) {
+			s.Points = append(s.Points, entities.SeriesPoint{Timestamp: at, Value: value})
+			return
+		}
+	}
+	r.series = append(r.series, &entities.Series{Name: name, Labels: labels, Points: []entities.SeriesPoint{{Timestamp: at, Value: value}}})
+}
+func (r *memMetricsRepository) Save(ctx context.Context, samples []entities.Sample) error {
+	for _, sample := range samples {
+		r.add(sample.Name, sample.Labels, sample.Timestamp, sample.Value)
+	}
+	return nil
+}
+func (r *memMetricsRepository) FindSeries(ctx context.Context, selector map[string]string, duration time.Duration) ([]*entities.Series, error) {
+	now := time.Now
+	if r.now != nil {
+		now = r.now
+	}
+	since := now().Add(-duration)
+	var result []*entities.Series
+	for _, s := range r.series {
+		if !matchesSelector(s.Labels, selector) {
+			continue
+		}
+		found := &entities.Series{Name: s.Name, Labels: s.Labels, Type: s.Type, Unit: s.Unit}
+		for _, p := range s.Points {
+			if !p.Timestamp.Before(since) {
+				found.Points = append(found.Points, p)
+			}
+		}
+		if len(found.Points) > 0 {
+			result = append(result, found)
+		}
+	}
+	entities.SortSeries(result)
+	return result, nil
+}
+func (r *memMetricsRepository) Close() error {
+	return nil
+}
+type memRollupRepository struct {
+	rollups map[string]*entities.MetricsRollup
+	saves   int
+}
+func newMemRollupRepository() *memRollupRepository {
+	return &memRollupRepository{rollups: make(map[string]*entities.MetricsRollup)}
+}
+func (r *memRollupRepository) SaveRollups(ctx context.Context, rollups []*entities.MetricsRollup) error {
+	for _, rollup := range rollups {
+		r.rollups[rollup.Tier+"/"+rollupKey(rollup)] = rollup
+		r.saves++
+	}
+	return nil
+}
+func (r *memRollupRepository) FindRollups(ctx context.Context, tier string, selector map[string]string, duration time.Duration) ([]*entities.MetricsRollup, error) {
+	since := time.Now().Add(-duration)
+	var result []*entities.MetricsRollup
+	for _, rollup := range r.rollups {
+		if rollup.Tier == tier && matchesSelector(rollup.Labels, selector) && !rollup.Timestamp.Before(since) {
+			result = append(result, rollup)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Timestamp.Before(result[j].Timestamp)
+	})
+	return result, nil
+}
+func (r *memRollupRepository) LastRollupTime(ctx context.Context, tier string, lookback time.Duration) (time.Time, error) {
+	var last time.Time
+	for _, rollup := range r.rollups {
+		if rollup.Tier == tier && rollup.Timestamp.After(last) {
+			last = rollup.Timestamp
+		}
+	}
+	return last, nil
+}
+func (r *memRollupRepository) Close() error {
+	return nil
+}
+func (r *memRollupRepository) find(tier string, at time.Time) *entities.MetricsRollup {
+	for _, rollup := range r.rollups {
+		if rollup.Tier == tier && rollup.Timestamp.Equal(at) {
+			return rollup
+		}
+	}
+	return nil
+}
+func matchesSelector(labels, selector map[string]string) bool {
+	for name, value := range selector {
+		if labels[name] != value {
+			return false
+		}
+	}
+	return true
+}
+type memAlertRepository struct {
+	alerts    []*entities.Alert
+	cooldowns map[string]time.Time
+	now       func() time.Time
+}
+func newMemAlertRepository() *memAlertRepository {
+	return &memAlertRepository{cooldowns: make(map[string]time.Time), now: time.Now}
+}
+func (r *memAlertRepository) Save(ctx context.Context, alert *entities.Alert) error {
+	r.alerts = append(r.alerts, alert)
+	return nil
+}
+func (r *memAlertRepository) IsInCooldown(ctx context.Context, containerID string, alertType entities.AlertType) (bool, error) {
+	return r.now().Before(r.cooldowns[containerID+"|"+string(alertType)]), nil
+}
+func (r *memAlertRepository) SetCooldown(ctx context.Context, containerID string, alertType entities.AlertType, duration time.Duration) error {
+	r.cooldowns[containerID+"|"+string(alertType)] = r.now().Add(duration)
+	return nil
+}
+func (r *memAlertRepository) Close() error {
+	return nil
+}
+type memAlertStateRepository struct {
+	states map[string]entities.AlertInstance
+	writes int
+	err    error
+}
+func newMemAlertStateRepository() *memAlertStateRepository {
+	return &memAlertStateRepository{states: make(map[string]entities.AlertInstance)}
+}
+func (r *memAlertStateRepository) ListAlertStates(ctx context.Context) ([]*entities.AlertInstance, error) {
+	var instances []*entities.AlertInstance
+	for _, instance := range r.states {
+		copied := instance
+		instances = append(instances, &copied)
+	}
+	return instances, nil
+}
+func (r *memAlertStateRepository) SaveAlertStates(ctx context.Context, saved []*entities.AlertInstance, deleted []string) error {
+	r.writes++
+	if r.err != nil {
+		return r.err
+	}
+	for _, instance := range saved {
+		r.states[instance.Fingerprint] = *instance
+	}
+	for _, fingerprint := range deleted {
+		delete(r.states, fingerprint)
+	}
+	return nil
+}
+type memNotifier struct {
+	alerts   []*entities.Alert
+	err      error
+	silencer ports.AlertSilencer
+}
+func (n *memNotifier) Notify(ctx context.Context, alert *entities.Alert) error {
+	if n.err != nil {
+		return n.err
+	}
+	if n.silencer != nil {
+		if silenced, _ := n.silencer.IsSilenced(ctx, alert); silenced {
+			return ports.ErrSilenced
+		}
+	}
+	copied := *alert
+	n.alerts = append(n.alerts, &copied)
+	return nil
+}
+type memSilenceRepository struct {
+	silences map[string]entities.Silence
+	err      error
+}
+func newMemSilenceRepository() *memSilenceRepository {
+	return &memSilenceRepository{silences: make(map[string]entities.Silence)}
+}
+func (r *memSilenceRepository) SaveSilence(ctx context.Context, silence *entities.Silence) error {
+	if r.err != nil {
+		return r.err
+	}
+	r.silences[silence.ID] = *silence
+	return nil
+}
+func (r *memSilenceRepository) ListSilences(ctx context.Context) ([]*entities.Silence, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	var silences []*entities.Silence
+	for _, silence := range r.silences {
+		copied := silence
+		silences = append(silences, &copied)
+	}
+	return silences, nil
+}
+func (r *memSilenceRepository) Close() error {
+	return nil
+}
\ No newline at end of file
@@ -0,0 +1,24 @@
+package usecases
+import (
+	"context"
+	"fmt"
+	"observability-system/internal/domain/entities"
+	"observability-system/internal/domain/ports"
+)
+type QueryLogsUseCase struct {
+	logRepo ports.LogRepository
+}
+func NewQueryLogsUseCase(logRepo ports.LogRepository) *QueryLogsUseCase {
+	return &QueryLogsUseCase{logRepo: logRepo}
+}
+func (uc *QueryLogsUseCase) Execute(ctx context.Context, query entities.LogQuery) (*entities.LogPage, error) {
+	fetch, skip, err := query.Resume()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := uc.logRepo.QueryLogs(ctx, fetch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query logs: %w", err)
+	}
+	return query.Paginate(entries, skip), nil
+}
\ No newline at end of file
@@ -0,0 +1,73 @@
+package usecases
+import (
+	"context"
+	"fmt"
+	"time"
+	"observability-system/internal/domain/entities"
+	"observability-system/internal/domain/ports"
+)
+type QueryMetricsUseCase struct {
+	metricsRepo ports.MetricsRepository
+	rollupRepo  ports.RollupRepository
+	tiers       []entities.RollupTier
+}
+func NewQueryMetricsUseCase(metricsRepo ports.MetricsRepository, rollupRepo ports.RollupRepository, tiers []entities.RollupTier) *QueryMetricsUseCase {
+	return &QueryMetricsUseCase{
+		metricsRepo: metricsRepo,
+		rollupRepo:  rollupRepo,
+		tiers:       tiers,
+	}
+}
+func (uc *QueryMetricsUseCase) Execute(ctx context.Context, selector map[string]string, duration, step time.Duration) ([]*entities.Series, error) {
+	tier, ok := entities.SelectRollupTier(uc.tiers, duration, step)
+	if !ok || uc.rollupRepo == nil {
+		return uc.metricsRepo.FindSeries(ctx, selector, duration)
+	}
+	rollups, err := uc.rollupRepo.FindRollups(ctx, tier.Name, selector, duration)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query %s rollups: %w", tier.Name, err)
+	}
+	if len(rollups) == 0 {
+		return uc.metricsRepo.FindSeries(ctx, selector, duration)
+	}
+	rollups, err = uc.fillFromRaw(ctx, tier, selector, duration, rollups)
+	if err != nil {
+		return nil, err
+	}
+	return entities.RollupsToSeries(rollups), nil
+}
+func (uc *QueryMetricsUseCase) fillFromRaw(ctx context.Context, tier entities.RollupTier, selector map[string]string, duration time.Duration, rollups []*entities.MetricsRollup) ([]*entities.MetricsRollup, error) {
+	now := time.Now()
+	from := now.Add(-duration)
+	covered := make(map[string]bool, len(rollups))
+	first, last := rollups[0].Timestamp, rollups[0].Timestamp
+	for _, rollup := range rollups {
+		covered[rollupKey(rollup)] = true
+		if rollup.Timestamp.Before(first) {
+			first = rollup.Timestamp
+		}
+		if rollup.Timestamp.After(last) {
+			last = rollup.Timestamp
+		}
+	}
+	rawSince := last.Add(tier.Resolution)
+	if first.After(from.Add(tier.Resolution)) {
+		rawSince = from
+	}
+	if !rawSince.Before(now) {
+		return rollups, nil
+	}
+	raw, err := uc.metricsRepo.FindSeries(ctx, selector, now.Sub(rawSince)+tier.Resolution)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read raw metrics outside %s rollups: %w", tier.Name, err)
+	}
+	for _, fill := range buildRollups(tier, raw, rawSince, now.Add(tier.Resolution)) {
+		if !covered[rollupKey(fill)] {
+			rollups = append(rollups, fill)
+		}
+	}
+	return rollups, nil
+}
+func rollupKey(rollup *entities.MetricsRollup) string {
+	return entities.SeriesKey(rollup.Name, rollup.Labels) + "@" + rollup.Timestamp.UTC().Format(time.RFC3339)
+}
\ No newline at end of file
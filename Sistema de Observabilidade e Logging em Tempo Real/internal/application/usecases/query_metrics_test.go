@@ -0,0 +1,76 @@
+package usecases
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+	"observability-system/internal/domain/entities"
+)
+func pointValues(series []*entities.Series) string {
+	var values []string
+	for _, s := range series {
+		for _, p := range s.Points {
+			values = append(values, fmt.Sprintf("%s=%g", s.Labels["container_id"], p.Value))
+		}
+	}
+	return fmt.Sprint(values)
+}
+func TestQueryMetricsFillsRecentWindowsFromRaw(t *testing.T) {
+	ctx := context.Background()
+	labels := map[string]string{"container_id": "c1"}
+	now := time.Now().Truncate(time.Minute)
+	raw := &memMetricsRepository{}
+	rollups := newMemRollupRepository()
+	for minute := 10; minute >= 1; minute-- {
+		raw.add("cpu", labels, now.Add(-time.Duration(minute)*time.Minute), float64(minute))
+	}
+	rollups.SaveRollups(ctx, []*entities.MetricsRollup{
+		{Name: "cpu", Labels: labels, Tier: "1m", Timestamp: now.Add(-10 * time.Minute), Mean: 100, Count: 1},
+		{Name: "cpu", Labels: labels, Tier: "1m", Timestamp: now.Add(-9 * time.Minute), Mean: 90, Count: 1},
+	})
+	uc := NewQueryMetricsUseCase(raw, rollups, minuteTier)
+	series, err := uc.Execute(ctx, labels, time.Since(now.Add(-10*time.Minute))+time.Second, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := pointValues(series); got != "[c1=100 c1=90 c1=8 c1=7 c1=6 c1=5 c1=4 c1=3 c1=2 c1=1]" {
+		t.Fatalf("expected rollups followed by raw windows, got %s", got)
+	}
+}
+func TestQueryMetricsFillsLeadingGapFromRaw(t *testing.T) {
+	ctx := context.Background()
+	labels := map[string]string{"container_id": "c1"}
+	now := time.Now().Truncate(time.Minute)
+	raw := &memMetricsRepository{}
+	rollups := newMemRollupRepository()
+	for minute := 6; minute >= 1; minute-- {
+		raw.add("cpu", labels, now.Add(-time.Duration(minute)*time.Minute), float64(minute))
+	}
+	rollups.SaveRollups(ctx, []*entities.MetricsRollup{
+		{Name: "cpu", Labels: labels, Tier: "1m", Timestamp: now.Add(-2 * time.Minute), Mean: 20, Count: 1},
+		{Name: "cpu", Labels: labels, Tier: "1m", Timestamp: now.Add(-1 * time.Minute), Mean: 10, Count: 1},
+	})
+	uc := NewQueryMetricsUseCase(raw, rollups, minuteTier)
+	series, err := uc.Execute(ctx, labels, time.Since(now.Add(-6*time.Minute))+time.Second, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := pointValues(series); got != "[c1=6 c1=5 c1=4 c1=3 c1=20 c1=10]" {
+		t.Fatalf("expected raw windows before the first rollup, got %s", got)
+	}
+}
+func TestQueryMetricsUsesRawWhenNoRollupTierFits(t *testing.T) {
+	ctx := context.Background()
+	labels := map[string]string{"container_id": "c1"}
+	raw := &memMetricsRepository{}
+	raw.add("cpu", labels, time.Now().Add(-10*time.Second), 1)
+	raw.add("cpu", labels, time.Now().Add(-5*time.Second), 2)
+	uc := NewQueryMetricsUseCase(raw, newMemRollupRepository(), minuteTier)
+	series, err := uc.Execute(ctx, labels, time.Minute, 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := pointValues(series); got != "[c1=1 c1=2]" {
+		t.Fatalf("expected raw points, got %s", got)
+	}
+}
\ No newline at end of file
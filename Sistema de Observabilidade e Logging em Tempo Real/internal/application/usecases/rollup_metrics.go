@@ -0,0 +1,93 @@
+package usecases
+import (
+	"context"
+	"fmt"
+	"time"
+	"observability-system/internal/domain/entities"
+	"observability-system/internal/domain/ports"
+)
+type RollupMetricsUseCase struct {
+	metricsRepo ports.MetricsRepository
+	rollupRepo  ports.RollupRepository
+	tiers       []entities.RollupTier
+	backfill    time.Duration
+	settle      time.Duration
+	lookback    time.Duration
+	watermarks  map[string]time.Time
+}
+func NewRollupMetricsUseCase(metricsRepo ports.MetricsRepository, rollupRepo ports.RollupRepository, tiers []entities.RollupTier, backfill, settle, lookback time.Duration) *RollupMetricsUseCase {
+	return &RollupMetricsUseCase{
+		metricsRepo: metricsRepo,
+		rollupRepo:  rollupRepo,
+		tiers:       tiers,
+		backfill:    backfill,
+		settle:      settle,
+		lookback:    lookback,
+		watermarks:  make(map[string]time.Time),
+	}
+}
+func (uc *RollupMetricsUseCase) Execute(ctx context.Context, now time.Time) (int, error) {
+	written := 0
+	for _, tier := range uc.tiers {
+		start, err := uc.watermark(ctx, tier, now)
+		if err != nil {
+			return written, err
+		}
+		end := now.Add(-uc.settle).Truncate(tier.Resolution)
+		if !end.After(start) {
+			continue
+		}
+		if recheck := end.Add(-uc.lookback).Truncate(tier.Resolution); uc.lookback > 0 && recheck.Before(start) {
+			start = recheck
+		}
+		raw, err := uc.metricsRepo.FindSeries(ctx, nil, now.Sub(start))
+		if err != nil {
+			return written, fmt.Errorf("failed to read raw metrics for %s rollup: %w", tier.Name, err)
+		}
+		rollups := buildRollups(tier, raw, start, end)
+		if len(rollups) > 0 {
+			if err := uc.rollupRepo.SaveRollups(ctx, rollups); err != nil {
+				return written, fmt.Errorf("failed to save %s rollups: %w", tier.Name, err)
+			}
+		}
+		uc.watermarks[tier.Name] = end
+		written += len(rollups)
+	}
+	return written, nil
+}
+func (uc *RollupMetricsUseCase) watermark(ctx context.Context, tier entities.RollupTier, now time.Time) (time.Time, error) {
+	if start, ok := uc.watermarks[tier.Name]; ok {
+		return start, nil
+	}
+	last, err := uc.rollupRepo.LastRollupTime(ctx, tier.Name, uc.backfill)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to find last %s rollup: %w", tier.Name, err)
+	}
+	if last.IsZero() {
+		return now.Add(-uc.backfill).Truncate(tier.Resolution), nil
+	}
+	return last.Add(tier.Resolution), nil
+}
+func buildRollups(tier entities.RollupTier, raw []*entities.Series, start, end time.Time) []*entities.MetricsRollup {
+	var rollups []*entities.MetricsRollup
+	for _, series := range raw {
+		var windowStart time.Time
+		var values []float64
+		for _, point := range series.Points {
+			if point.Timestamp.Before(start) || !point.Timestamp.Before(end) {
+				continue
+			}
+			pointWindow := point.Timestamp.Truncate(tier.Resolution)
+			if len(values) > 0 && !pointWindow.Equal(windowStart) {
+				rollups = append(rollups, entities.NewMetricsRollup(tier, series, windowStart, values))
+				values = nil
+			}
+			windowStart = pointWindow
+			values = append(values, point.Value)
+		}
+		if len(values) > 0 {
+			rollups = append(rollups, entities.NewMetricsRollup(tier, series, windowStart, values))
+		}
+	}
+	return rollups
+}
\ No newline at end of file
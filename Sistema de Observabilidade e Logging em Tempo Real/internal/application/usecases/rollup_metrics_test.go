@@ -0,0 +1,126 @@
+package usecases
+import (
+	"context"
+	"testing"
+	"time"
+	"observability-system/internal/domain/entities"
+)
+var minuteTier = []entities.RollupTier{{Name: "1m", Resolution: time.Minute}}
+func TestRollupMetricsRollsClosedBucketsOnce(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Minute)
+	labels := map[string]string{"container_id": "c1"}
+	raw := &memMetricsRepository{}
+	bucket := now.Add(-2 * time.Minute)
+	raw.add("cpu", labels, bucket.Add(10*time.Second), 10)
+	raw.add("cpu", labels, bucket.Add(40*time.Second), 30)
+	raw.add("cpu", labels, now.Add(-time.Minute+10*time.Second), 20)
+	raw.add("cpu", labels, now.Add(10*time.Second), 50)
+	rollups := newMemRollupRepository()
+	uc := NewRollupMetricsUseCase(raw, rollups, minuteTier, time.Hour, 0, 0)
+	if _, err := uc.Execute(ctx, now); err != nil {
+		t.Fatal(err)
+	}
+	rollup := rollups.find("1m", bucket)
+	if rollup == nil || rollup.Count != 2 || rollup.Min != 10 || rollup.Max != 30 || rollup.Mean != 20 || rollup.Last != 30 {
+		t.Fatalf("expected both points rolled into their bucket, got %+v", rollup)
+	}
+	if rollups.find("1m", now) != nil {
+		t.Fatal("expected the open bucket to be left for a later run")
+	}
+	saves := rollups.saves
+	if written, err := uc.Execute(ctx, now); err != nil || written != 0 || rollups.saves != saves {
+		t.Fatalf("expected closed buckets to be rolled once, got %d written, %d saves, %v", written, rollups.saves-saves, err)
+	}
+	if _, err := uc.Execute(ctx, now.Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if rollup := rollups.find("1m", now); rollup == nil || rollup.Count != 1 || rollup.Last != 50 {
+		t.Fatalf("expected the bucket to be rolled once it closed, got %+v", rollup)
+	}
+}
+func TestRollupMetricsRerollsLatePointsWithinLookback(t *testing.T) {
+	ctx := context.Background()
+	labels := map[string]string{"container_id": "c1"}
+	now := time.Now().Truncate(time.Minute)
+	raw := &memMetricsRepository{}
+	raw.add("cpu", labels, now.Add(-3*time.Minute+10*time.Second), 10)
+	raw.add("cpu", labels, now.Add(-2*time.Minute+10*time.Second), 20)
+	rollups := newMemRollupRepository()
+	uc := NewRollupMetricsUseCase(raw, rollups, minuteTier, time.Hour, 0, 5*time.Minute)
+	if _, err := uc.Execute(ctx, now); err != nil {
+		t.Fatal(err)
+	}
+	bucket := now.Add(-3 * time.Minute)
+	if rollup := rollups.find("1m", bucket); rollup == nil || rollup.Count != 1 {
+		t.Fatalf("expected initial rollup of one point, got %+v", rollup)
+	}
+	raw.add("cpu", labels, bucket.Add(40*time.Second), 30)
+	if _, err := uc.Execute(ctx, now.Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	rollup := rollups.find("1m", bucket)
+	if rollup == nil || rollup.Count != 2 || rollup.Max != 30 || rollup.Mean != 20 {
+		t.Fatalf("expected late point to be rolled into its bucket, got %+v", rollup)
+	}
+}
+func TestRollupMetricsWithoutLookbackOnlyMovesForward(t *testing.T) {
+	ctx := context.Background()
+	labels := map[string]string{"container_id": "c1"}
+	now := time.Now().Truncate(time.Minute)
+	raw := &memMetricsRepository{}
+	raw.add("cpu", labels, now.Add(-2*time.Minute), 10)
+	rollups := newMemRollupRepository()
+	uc := NewRollupMetricsUseCase(raw, rollups, minuteTier, time.Hour, 0, 0)
+	if _, err := uc.Execute(ctx, now); err != nil {
+		t.Fatal(err)
+	}
+	saves := rollups.saves
+	if _, err := uc.Execute(ctx, now); err != nil {
+		t.Fatal(err)
+	}
+	if rollups.saves != saves {
+		t.Fatalf("expected no rewrites without a lookback, got %d saves", rollups.saves-saves)
+	}
+}
+func TestRollupMetricsOnlyRollsClosedBuckets(t *testing.T) {
+	ctx := context.Background()
+	labels := map[string]string{"container_id": "c1"}
+	hour := time.Now().Truncate(time.Hour)
+	now := hour
+	raw := &memMetricsRepository{now: func() time.Time { return now }}
+	raw.add("cpu", labels, hour.Add(-30*time.Minute), 10)
+	raw.add("cpu", labels, hour.Add(30*time.Minute), 20)
+	rollups := newMemRollupRepository()
+	tiers := []entities.RollupTier{{Name: "1m", Resolution: time.Minute}, {Name: "1h", Resolution: time.Hour}}
+	uc := NewRollupMetricsUseCase(raw, rollups, tiers, 2*time.Hour, 0, 10*time.Minute)
+	for minute := 1; minute < 60; minute++ {
+		now = hour.Add(time.Duration(minute) * time.Minute)
+		if _, err := uc.Execute(ctx, now); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if rollups.find("1h", hour.Add(-time.Hour)) == nil {
+		t.Fatal("expected the closed hour to be rolled up")
+	}
+	if rollups.find("1h", hour) != nil {
+		t.Fatal("expected the open hour not to be rolled up")
+	}
+	now = hour.Add(time.Hour)
+	if _, err := uc.Execute(ctx, now); err != nil {
+		t.Fatal(err)
+	}
+	closed := rollups.find("1h", hour)
+	if closed == nil || closed.Count != 1 {
+		t.Fatalf("expected the hour to be rolled up once it closed, got %+v", closed)
+	}
+	for minute := 61; minute < 120; minute++ {
+		now = hour.Add(time.Duration(minute) * time.Minute)
+		if _, err := uc.Execute(ctx, now); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if rollups.find("1h", hour) != closed {
+		t.Fatal("expected the closed hour not to be rewritten on every tick")
+	}
+}
\ No newline at end of file
@@ -0,0 +1,114 @@
+package usecases
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+	"observability-system/internal/domain/entities"
+	"observability-system/internal/domain/ports"
+)
+var (
+	ErrInvalidSilence  = errors.New("invalid silence")
+	ErrSilenceNotFound = errors.New("silence not found")
+)
+type SilenceAlertsUseCase struct {
+	silenceRepo ports.SilenceRepository
+	windows     []entities.MaintenanceWindow
+	now         func() time.Time
+}
+func NewSilenceAlertsUseCase(silenceRepo ports.SilenceRepository, windows []entities.MaintenanceWindow) *SilenceAlertsUseCase {
+	return &SilenceAlertsUseCase{
+		silenceRepo: silenceRepo,
+		windows:     windows,
+		now:         time.Now,
+	}
+}
+func (uc *SilenceAlertsUseCase) Create(ctx context.Context, silence entities.Silence) (*entities.Silence, error) {
+	now := uc.now()
+	silence.ID = newSilenceID()
+	silence.CreatedAt = now
+	if silence.StartsAt.IsZero() {
+		silence.StartsAt = now
+	}
+	if err := silence.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSilence, err)
+	}
+	if !silence.EndsAt.After(now) {
+		return nil, fmt.Errorf("%w: silence must end in the future", ErrInvalidSilence)
+	}
+	if err := uc.silenceRepo.SaveSilence(ctx, &silence); err != nil {
+		return nil, fmt.Errorf("failed to save silence: %w", err)
+	}
+	return &silence, nil
+}
+func (uc *SilenceAlertsUseCase) List(ctx context.Context, includeExpired bool) ([]*entities.Silence, error) {
+	silences, err := uc.silenceRepo.ListSilences(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list silences: %w", err)
+	}
+	now := uc.now()
+	result := make([]*entities.Silence, 0, len(silences))
+	for _, silence := range silences {
+		if includeExpired || !silence.Expired(now) {
+			result = append(result, silence)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].StartsAt.Before(result[j].StartsAt)
+	})
+	return result, nil
+}
+func (uc *SilenceAlertsUseCase) Expire(ctx context.Context, id string) (*entities.Silence, error) {
+	silences, err := uc.silenceRepo.ListSilences(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list silences: %w", err)
+	}
+	now := uc.now()
+	for _, silence := range silences {
+		if silence.ID != id {
+			continue
+		}
+		if silence.Expired(now) {
+			return silence, nil
+		}
+		silence.EndsAt = now
+		if silence.StartsAt.After(now) {
+			silence.StartsAt = now
+		}
+		if err := uc.silenceRepo.SaveSilence(ctx, silence); err != nil {
+			return nil, fmt.Errorf("failed to expire silence: %w", err)
+		}
+		return silence, nil
+	}
+	return nil, ErrSilenceNotFound
+}
+func (uc *SilenceAlertsUseCase) IsSilenced(ctx context.Context, alert *entities.Alert) (bool, error) {
+	labels := entities.AlertSilenceLabels(alert)
+	now := uc.now()
+	for _, window := range uc.windows {
+		if window.Matches(labels) && window.Active(now) {
+			return true, nil
+		}
+	}
+	if uc.silenceRepo == nil {
+		return false, nil
+	}
+	silences, err := uc.silenceRepo.ListSilences(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to list silences: %w", err)
+	}
+	for _, silence := range silences {
+		if silence.Active(now) && silence.Matches(labels) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+func newSilenceID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
\ No newline at end of file
@@ -0,0 +1,137 @@
+package usecases
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"observability-system/internal/domain/entities"
+)
+func TestSilenceAlertsCreate(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	valid := entities.Silence{Matchers: map[string]string{"container_name": "api-*"}, EndsAt: now.Add(time.Hour), CreatedBy: "ops"}
+	tests := []struct {
+		name    string
+		change  func(s *entities.Silence)
+		invalid bool
+	}{
+		{name: "valid"},
+		{name: "no matchers", change: func(s *entities.Silence) { s.Matchers = nil }, invalid: true},
+		{name: "bad pattern", change: func(s *entities.Silence) { s.Matchers = map[string]string{"container_name": "["} }, invalid: true},
+		{name: "no creator", change: func(s *entities.Silence) { s.CreatedBy = "" }, invalid: true},
+		{name: "ends before start", change: func(s *entities.Silence) { s.StartsAt = now.Add(2 * time.Hour) }, invalid: true},
+		{name: "already ended", change: func(s *entities.Silence) { s.StartsAt, s.EndsAt = now.Add(-2*time.Hour), now.Add(-time.Hour) }, invalid: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMemSilenceRepository()
+			uc := NewSilenceAlertsUseCase(repo, nil)
+			uc.now = func() time.Time { return now }
+			silence := valid
+			if tt.change != nil {
+				tt.change(&silence)
+			}
+			created, err := uc.Create(context.Background(), silence)
+			if tt.invalid {
+				if !errors.Is(err, ErrInvalidSilence) || len(repo.silences) != 0 {
+					t.Fatalf("expected ErrInvalidSilence and nothing saved, got %v with %d saved", err, len(repo.silences))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if created.ID == "" || !created.StartsAt.Equal(now) || !created.CreatedAt.Equal(now) {
+				t.Fatalf("expected an ID and start defaulting to now, got %+v", created)
+			}
+			if _, ok := repo.silences[created.ID]; !ok {
+				t.Fatal("expected the silence to be saved")
+			}
+		})
+	}
+	repo := newMemSilenceRepository()
+	repo.err = errors.New("redis down")
+	uc := NewSilenceAlertsUseCase(repo, nil)
+	uc.now = func() time.Time { return now }
+	if _, err := uc.Create(context.Background(), valid); err == nil || errors.Is(err, ErrInvalidSilence) {
+		t.Fatalf("expected a storage error, got %v", err)
+	}
+}
+func TestSilenceAlertsExpire(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	repo := newMemSilenceRepository()
+	repo.silences["active"] = entities.Silence{ID: "active", StartsAt: now.Add(-time.Hour), EndsAt: now.Add(time.Hour)}
+	repo.silences["future"] = entities.Silence{ID: "future", StartsAt: now.Add(time.Hour), EndsAt: now.Add(2 * time.Hour)}
+	repo.silences["expired"] = entities.Silence{ID: "expired", StartsAt: now.Add(-2 * time.Hour), EndsAt: now.Add(-time.Hour)}
+	uc := NewSilenceAlertsUseCase(repo, nil)
+	uc.now = func() time.Time { return now }
+	ctx := context.Background()
+	for _, id := range []string{"active", "future"} {
+		silence, err := uc.Expire(ctx, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !silence.EndsAt.Equal(now) || silence.StartsAt.After(now) || !repo.silences[id].EndsAt.Equal(now) {
+			t.Fatalf("expected %s to end now, got %+v", id, repo.silences[id])
+		}
+	}
+	silence, err := uc.Expire(ctx, "expired")
+	if err != nil || !silence.EndsAt.Equal(now.Add(-time.Hour)) {
+		t.Fatalf("expected an expired silence to be returned unchanged, got %+v, %v", silence, err)
+	}
+	if _, err := uc.Expire(ctx, "missing"); !errors.Is(err, ErrSilenceNotFound) {
+		t.Fatalf("expected ErrSilenceNotFound, got %v", err)
+	}
+	listed, err := uc.List(ctx, false)
+	if err != nil || len(listed) != 0 {
+		t.Fatalf("expected no unexpired silences, got %d, %v", len(listed), err)
+	}
+	if listed, _ := uc.List(ctx, true); len(listed) != 3 {
+		t.Fatalf("expected 3 silences including expired, got %d", len(listed))
+	}
+}
+func TestSilenceAlertsIsSilenced(t *testing.T) {
+	now := time.Date(2024, 6, 1, 2, 45, 0, 0, time.UTC)
+	schedule, err := entities.ParseCronSchedule("30 2 * * 6")
+	if err != nil {
+		t.Fatal(err)
+	}
+	window := entities.MaintenanceWindow{Name: "deploys", Matchers: map[string]string{"container_name": "web-*"}, Schedule: schedule, Duration: time.Hour, Location: time.UTC}
+	repo := newMemSilenceRepository()
+	repo.silences["api"] = entities.Silence{ID: "api", Matchers: map[string]string{"container_name": "api-*", "alertname": "CPU"}, StartsAt: now.Add(-time.Minute), EndsAt: now.Add(time.Hour)}
+	repo.silences["future"] = entities.Silence{ID: "future", Matchers: map[string]string{"severity": "critical"}, StartsAt: now.Add(time.Hour), EndsAt: now.Add(2 * time.Hour)}
+	uc := NewSilenceAlertsUseCase(repo, []entities.MaintenanceWindow{window})
+	uc.now = func() time.Time { return now }
+	tests := []struct {
+		name  string
+		alert *entities.Alert
+		want  bool
+	}{
+		{"matching silence", &entities.Alert{Type: "CPU", ContainerName: "api-1", Severity: "critical"}, true},
+		{"other alert type", &entities.Alert{Type: "MEMORY", ContainerName: "api-1", Severity: "critical"}, false},
+		{"future silence", &entities.Alert{Type: "DISK", ContainerName: "db", Severity: "critical"}, false},
+		{"maintenance window", &entities.Alert{Type: "MEMORY", ContainerName: "web-2"}, true},
+		{"series label", &entities.Alert{Type: "MEMORY", Labels: map[string]string{"container_name": "web-3"}}, true},
+	}
+	for _, tt := range tests {
+		got, err := uc.IsSilenced(context.Background(), tt.alert)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("%s: IsSilenced = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+	uc.now = func() time.Time { return now.Add(2 * time.Hour) }
+	if got, _ := uc.IsSilenced(context.Background(), &entities.Alert{Type: "MEMORY", ContainerName: "web-2"}); got {
+		t.Fatal("expected the maintenance window to close after its duration")
+	}
+	repo.err = errors.New("redis down")
+	if _, err := uc.IsSilenced(context.Background(), &entities.Alert{Type: "CPU", ContainerName: "api-1"}); err == nil {
+		t.Fatal("expected the repository error to be returned")
+	}
+	windowsOnly := NewSilenceAlertsUseCase(nil, []entities.MaintenanceWindow{window})
+	windowsOnly.now = func() time.Time { return now }
+	if got, err := windowsOnly.IsSilenced(context.Background(), &entities.Alert{Type: "CPU", ContainerName: "api-1"}); got || err != nil {
+		t.Fatalf("expected no silence without a repository, got %v, %v", got, err)
+	}
+}
\ No newline at end of file
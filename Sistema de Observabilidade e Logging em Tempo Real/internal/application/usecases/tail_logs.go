@@ -0,0 +1,48 @@
+package usecases
+import (
+	"context"
+	"fmt"
+	"observability-system/internal/domain/ports"
+)
+const tailPageSize = 5000
+type TailLogsUseCase struct {
+	feed        ports.LogFeed
+	broadcaster ports.LogBroadcaster
+	cursor      string
+	primed      bool
+}
+func NewTailLogsUseCase(feed ports.LogFeed, broadcaster ports.LogBroadcaster) *TailLogsUseCase {
+	return &TailLogsUseCase{
+		feed:        feed,
+		broadcaster: broadcaster,
+	}
+}
+func (uc *TailLogsUseCase) Execute(ctx context.Context) (int, error) {
+	if uc.broadcaster.LogSubscribers() == 0 {
+		uc.cursor, uc.primed = "", false
+		return 0, nil
+	}
+	if !uc.primed {
+		cursor, err := uc.feed.LatestLogCursor(ctx)
+		if err != nil {
+			return 0, fmt.Errorf("failed to find the end of the log stream: %w", err)
+		}
+		uc.cursor, uc.primed = cursor, true
+		return 0, nil
+	}
+	sent := 0
+	for {
+		entries, cursor, err := uc.feed.ReadLogsSince(ctx, uc.cursor, tailPageSize)
+		if err != nil {
+			return sent, fmt.Errorf("failed to read new logs: %w", err)
+		}
+		uc.cursor = cursor
+		if len(entries) > 0 {
+			uc.broadcaster.BroadcastLogs(entries)
+			sent += len(entries)
+		}
+		if len(entries) < tailPageSize {
+			return sent, nil
+		}
+	}
+}
\ No newline at end of file
@@ -0,0 +1,94 @@
+package usecases
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+	"observability-system/internal/domain/entities"
+)
+type memLogFeed struct {
+	entries []*entities.LogEntry
+	reads   int
+}
+func (f *memLogFeed) LatestLogCursor(ctx context.Context) (string, error) {
+	return strconv.Itoa(len(f.entries)), nil
+}
+func (f *memLogFeed) ReadLogsSince(ctx context.Context, cursor string, limit int) ([]*entities.LogEntry, string, error) {
+	f.reads++
+	start, err := strconv.Atoi(cursor)
+	if err != nil {
+		return nil, cursor, err
+	}
+	end := min(start+limit, len(f.entries))
+	return f.entries[start:end], strconv.Itoa(end), nil
+}
+type recordingLogBroadcaster struct {
+	subscribers int
+	entries     []*entities.LogEntry
+}
+func (b *recordingLogBroadcaster) LogSubscribers() int {
+	return b.subscribers
+}
+func (b *recordingLogBroadcaster) BroadcastLogs(entries []*entities.LogEntry) {
+	b.entries = append(b.entries, entries...)
+}
+func TestTailLogsPagesThroughBursts(t *testing.T) {
+	feed := &memLogFeed{entries: []*entities.LogEntry{{Message: "before subscribing"}}}
+	broadcaster := &recordingLogBroadcaster{subscribers: 1}
+	uc := NewTailLogsUseCase(feed, broadcaster)
+	if sent, err := uc.Execute(context.Background()); err != nil || sent != 0 {
+		t.Fatalf("expected the first run to only find the end of the stream, got %d %v", sent, err)
+	}
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 2*tailPageSize+10; i++ {
+		feed.entries = append(feed.entries, &entities.LogEntry{Timestamp: base.Add(time.Duration(i/3) * time.Microsecond), ContainerID: "abc", Message: fmt.Sprintf("line %d", i)})
+	}
+	sent, err := uc.Execute(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sent != 2*tailPageSize+10 || len(broadcaster.entries) != sent {
+		t.Fatalf("expected all %d entries to be tailed, sent %d", 2*tailPageSize+10, sent)
+	}
+	for i, entry := range broadcaster.entries {
+		if entry.Message != fmt.Sprintf("line %d", i) {
+			t.Fatalf("expected entries in ingest order, got %q at %d", entry.Message, i)
+		}
+	}
+	if feed.reads != 3 {
+		t.Fatalf("expected 3 pages, got %d reads", feed.reads)
+	}
+	if sent, _ := uc.Execute(context.Background()); sent != 0 {
+		t.Fatalf("expected no duplicates on the next poll, got %d", sent)
+	}
+}
+func TestTailLogsPushesLateArrivingEntries(t *testing.T) {
+	feed := &memLogFeed{}
+	broadcaster := &recordingLogBroadcaster{subscribers: 1}
+	uc := NewTailLogsUseCase(feed, broadcaster)
+	if _, err := uc.Execute(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	feed.entries = append(feed.entries,
+		&entities.LogEntry{Timestamp: now, Message: "on time"},
+		&entities.LogEntry{Timestamp: now.Add(-time.Hour), Message: "replayed after an agent outage"},
+	)
+	if sent, err := uc.Execute(context.Background()); err != nil || sent != 2 {
+		t.Fatalf("expected both entries to be pushed regardless of their timestamps, got %d %v", sent, err)
+	}
+	broadcaster.subscribers = 0
+	feed.entries = append(feed.entries, &entities.LogEntry{Timestamp: now, Message: "nobody listening"})
+	if _, err := uc.Execute(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	broadcaster.subscribers = 1
+	if sent, _ := uc.Execute(context.Background()); sent != 0 {
+		t.Fatalf("expected a new subscriber to start at the end of the stream, got %d", sent)
+	}
+	feed.entries = append(feed.entries, &entities.LogEntry{Timestamp: now, Message: "after resubscribing"})
+	if sent, _ := uc.Execute(context.Background()); sent != 1 || broadcaster.entries[len(broadcaster.entries)-1].Message != "after resubscribing" {
+		t.Fatalf("expected only the new entry, got %d", sent)
+	}
+}
\ No newline at end of file
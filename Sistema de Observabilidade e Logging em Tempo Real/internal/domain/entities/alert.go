@@ -1,19 +1,35 @@
 package entities
-import "time"
+import (
+	"fmt"
+	"strings"
+	"time"
+)
 type AlertType string
 const (
 	AlertTypeCPU    AlertType = "CPU"
 	AlertTypeMemory AlertType = "MEMORY"
 )
+type AlertStatus string
+const (
+	AlertStatusFiring   AlertStatus = "firing"
+	AlertStatusResolved AlertStatus = "resolved"
+)
 type Alert struct {
 	ID            string
 	ContainerID   string
 	ContainerName string
 	Type          AlertType
+	Status        AlertStatus
+	Fingerprint   string
 	Value         float64
 	Threshold     float64
+	Unit          string
 	Timestamp     time.Time
 	Message       string
+	Severity      string
+	Labels        map[string]string
+	Annotations   map[string]string
+	StartsAt      time.Time
 }
 func NewAlert(containerID, containerName string, alertType AlertType, value, threshold float64) *Alert {
 	return &Alert{
@@ -22,6 +38,33 @@ func NewAlert(containerID, containerName string, alertType AlertType, value, thr
 		Type:          alertType,
 		Value:         value,
 		Threshold:     threshold,
+		Status:        AlertStatusFiring,
 		Timestamp:     time.Now(),
 	}
+}
+func (a *Alert) Resolved() bool {
+	return a.Status == AlertStatusResolved
+}
+func (a *Alert) FormatValue(value float64) string {
+	unit := a.Unit
+	if unit == "" {
+		switch {
+		case a.Type == AlertTypeCPU || a.Type == AlertTypeMemory:
+			unit = "percent"
+		case strings.HasPrefix(string(a.Type), "LOG_"):
+			unit = "lines"
+		}
+	}
+	switch unit {
+	case "":
+		return fmt.Sprintf("%.2f", value)
+	case "percent":
+		return fmt.Sprintf("%.2f%%", value)
+	case "lines":
+		return fmt.Sprintf("%.0f lines", value)
+	case "bytes", "seconds":
+		return fmt.Sprintf("%.0f %s", value, unit)
+	default:
+		return fmt.Sprintf("%.2f %s", value, unit)
+	}
 }
\ No newline at end of file
@@ -0,0 +1,300 @@
+package entities
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+type Comparator string
+const (
+	ComparatorGreater      Comparator = ">"
+	ComparatorGreaterEqual Comparator = ">="
+	ComparatorLess         Comparator = "<"
+	ComparatorLessEqual    Comparator = "<="
+	ComparatorEqual        Comparator = "=="
+	ComparatorNotEqual     Comparator = "!="
+)
+const DefaultAlertCooldown = 5 * time.Minute
+type AlertRule struct {
+	Name           string
+	Expr           *MetricExpr
+	Comparator     Comparator
+	Threshold      float64
+	ClearThreshold *float64
+	Selector       map[string]string
+	Severity       string
+	Annotations    map[string]string
+	Cooldown       time.Duration
+	RepeatInterval time.Duration
+	For            time.Duration
+}
+type MetricExpr struct {
+	text  string
+	names []string
+	eval  func(values map[string]float64) (float64, bool)
+}
+func DefaultAlertRules() []AlertRule {
+	clearBelow := func(value float64) *float64 { return &value }
+	return []AlertRule{
+		{
+			Name:           string(AlertTypeCPU),
+			Expr:           MustParseMetricExpr("container_cpu_usage_percent"),
+			Comparator:     ComparatorGreater,
+			Threshold:      90,
+			ClearThreshold: clearBelow(80),
+			Severity:       "warning",
+			Cooldown:       DefaultAlertCooldown,
+		},
+		{
+			Name:           string(AlertTypeMemory),
+			Expr:           MustParseMetricExpr("container_memory_usage_percent"),
+			Comparator:     ComparatorGreater,
+			Threshold:      85,
+			ClearThreshold: clearBelow(75),
+			Severity:       "warning",
+			Cooldown:       DefaultAlertCooldown,
+		},
+	}
+}
+func (r AlertRule) AlertType() AlertType {
+	return AlertType(r.Name)
+}
+func (r AlertRule) Breached(value float64, firing bool) bool {
+	if firing && r.ClearThreshold != nil {
+		return !r.Comparator.Clears(value, *r.ClearThreshold)
+	}
+	return r.Comparator.Compare(value, r.Threshold)
+}
+func ParseAnnotationTemplate(text string) (*template.Template, error) {
+	return template.New("annotation").Option("missingkey=zero").Parse("{{$value := .Value}}{{$threshold := .Threshold}}{{$labels := .Labels}}" + text)
+}
+func (r AlertRule) RenderAnnotations(value float64, labels map[string]string) map[string]string {
+	if len(r.Annotations) == 0 {
+		return nil
+	}
+	data := struct {
+		Value     string
+		Threshold string
+		Labels    map[string]string
+	}{strconv.FormatFloat(value, 'f', -1, 64), strconv.FormatFloat(r.Threshold, 'f', -1, 64), labels}
+	rendered := make(map[string]string, len(r.Annotations))
+	for name, text := range r.Annotations {
+		tmpl, err := ParseAnnotationTemplate(text)
+		if err != nil {
+			rendered[name] = text
+			continue
+		}
+		var b strings.Builder
+		if err := tmpl.Execute(&b, data); err != nil {
+			rendered[name] = text
+			continue
+		}
+		rendered[name] = b.String()
+	}
+	return rendered
+}
+func (c Comparator) Valid() bool {
+	switch c {
+	case ComparatorGreater, ComparatorGreaterEqual, ComparatorLess, ComparatorLessEqual, ComparatorEqual, ComparatorNotEqual:
+		return true
+	}
+	return false
+}
+func (c Comparator) Compare(value, threshold float64) bool {
+	switch c {
+	case ComparatorGreater:
+		return value > threshold
+	case ComparatorGreaterEqual:
+		return value >= threshold
+	case ComparatorLess:
+		return value < threshold
+	case ComparatorLessEqual:
+		return value <= threshold
+	case ComparatorEqual:
+		return value == threshold
+	case ComparatorNotEqual:
+		return value != threshold
+	}
+	return false
+}
+func (c Comparator) Clears(value, clear float64) bool {
+	switch c {
+	case ComparatorGreater, ComparatorGreaterEqual:
+		return value < clear
+	case ComparatorLess, ComparatorLessEqual:
+		return value > clear
+	}
+	return !c.Compare(value, clear)
+}
+func (c Comparator) ValidClear(threshold, clear float64) bool {
+	switch c {
+	case ComparatorGreater, ComparatorGreaterEqual:
+		return clear <= threshold
+	case ComparatorLess, ComparatorLessEqual:
+		return clear >= threshold
+	}
+	return false
+}
+func ParseMetricExpr(text string) (*MetricExpr, error) {
+	p := &exprParser{text: text, seen: make(map[string]bool)}
+	eval, err := p.parseSum()
+	if err == nil && p.skipSpace() < len(text) {
+		err = fmt.Errorf("unexpected %q at position %d", text[p.pos:], p.pos+1)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid expression %q: %w", text, err)
+	}
+	if len(p.names) == 0 {
+		return nil, fmt.Errorf("invalid expression %q: no metric referenced", text)
+	}
+	return &MetricExpr{text: text, names: p.names, eval: eval}, nil
+}
+func MustParseMetricExpr(text string) *MetricExpr {
+	expr, err := ParseMetricExpr(text)
+	if err != nil {
+		panic(err)
+	}
+	return expr
+}
+func (e *MetricExpr) String() string {
+	return e.text
+}
+func (e *MetricExpr) Metrics() []string {
+	return e.names
+}
+func (e *MetricExpr) Unit() string {
+	if len(e.names) != 1 || strings.TrimSpace(e.text) != e.names[0] {
+		return ""
+	}
+	_, unit, _ := ContainerMetricInfo(e.names[0])
+	return unit
+}
+func (e *MetricExpr) Eval(values map[string]float64) (float64, bool) {
+	return e.eval(values)
+}
+type exprParser struct {
+	text  string
+	pos   int
+	names []string
+	seen  map[string]bool
+}
+type exprFunc = func(values map[string]float64) (float64, bool)
+func (p *exprParser) skipSpace() int {
+	for p.pos < len(p.text) && (p.text[p.pos] == ' ' || p.text[p.pos] == '\t') {
+		p.pos++
+	}
+	return p.pos
+}
+func (p *exprParser) parseSum() (exprFunc, error) {
+	left, err := p.parseProduct()
+	if err != nil {
+		return nil, err
+	}
+	for p.skipSpace() < len(p.text) && (p.text[p.pos] == '+' || p.text[p.pos] == '-') {
+		op := p.text[p.pos]
+		p.pos++
+		right, err := p.parseProduct()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryExpr(op, left, right)
+	}
+	return left, nil
+}
+func (p *exprParser) parseProduct() (exprFunc, error) {
+	left, err := p.parseFactor()
+	if err != nil {
+		return nil, err
+	}
+	for p.skipSpace() < len(p.text) && (p.text[p.pos] == '*' || p.text[p.pos] == '/') {
+		op := p.text[p.pos]
+		p.pos++
+		right, err := p.parseFactor()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryExpr(op, left, right)
+	}
+	return left, nil
+}
+func (p *exprParser) parseFactor() (exprFunc, error) {
+	if p.skipSpace() >= len(p.text) {
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+	start := p.pos
+	switch c := p.text[p.pos]; {
+	case c == '(':
+		p.pos++
+		inner, err := p.parseSum()
+		if err != nil {
+			return nil, err
+		}
+		if p.skipSpace() >= len(p.text) || p.text[p.pos] != ')' {
+			return nil, fmt.Errorf("missing ')' for '(' at position %d", start+1)
+		}
+		p.pos++
+		return inner, nil
+	case c == '-':
+		p.pos++
+		inner, err := p.parseFactor()
+		if err != nil {
+			return nil, err
+		}
+		return func(values map[string]float64) (float64, bool) {
+			v, ok := inner(values)
+			return -v, ok
+		}, nil
+	case c >= '0' && c <= '9' || c == '.':
+		for p.pos < len(p.text) && strings.IndexByte("0123456789.eE", p.text[p.pos]) >= 0 {
+			p.pos++
+		}
+		number, err := strconv.ParseFloat(p.text[start:p.pos], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", p.text[start:p.pos])
+		}
+		return func(map[string]float64) (float64, bool) { return number, true }, nil
+	case c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_' || c == ':':
+		for p.pos < len(p.text) {
+			c := p.text[p.pos]
+			if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == ':') {
+				break
+			}
+			p.pos++
+		}
+		name := p.text[start:p.pos]
+		if !p.seen[name] {
+			p.seen[name] = true
+			p.names = append(p.names, name)
+		}
+		return func(values map[string]float64) (float64, bool) {
+			v, ok := values[name]
+			return v, ok
+		}, nil
+	}
+	return nil, fmt.Errorf("unexpected %q at position %d", p.text[p.pos:p.pos+1], p.pos+1)
+}
+func binaryExpr(op byte, left, right exprFunc) exprFunc {
+	return func(values map[string]float64) (float64, bool) {
+		a, ok := left(values)
+		if !ok {
+			return 0, false
+		}
+		b, ok := right(values)
+		if !ok {
+			return 0, false
+		}
+		switch op {
+		case '+':
+			return a + b, true
+		case '-':
+			return a - b, true
+		case '*':
+			return a * b, true
+		}
+		if b == 0 {
+			return 0, false
+		}
+		return a / b, true
+	}
+}
\ No newline at end of file
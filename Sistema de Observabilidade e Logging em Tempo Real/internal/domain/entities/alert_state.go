@@ -0,0 +1,71 @@
+package entities
+import (
+	"fmt"
+	"hash/fnv"
+	"time"
+)
+type AlertState string
+const (
+	AlertStateInactive AlertState = "inactive"
+	AlertStatePending  AlertState = "pending"
+	AlertStateFiring   AlertState = "firing"
+	AlertStateResolved AlertState = "resolved"
+)
+type AlertInstance struct {
+	Fingerprint  string
+	Rule         string
+	ContainerID  string
+	Labels       map[string]string
+	State        AlertState
+	ActiveAt     time.Time
+	LastBreachAt time.Time
+	FiredAt      time.Time
+	NotifiedAt   time.Time
+	ResolvedAt   time.Time
+	Value        float64
+}
+func NewAlertInstance(rule string, labels map[string]string) *AlertInstance {
+	return &AlertInstance{
+		Fingerprint: AlertFingerprint(rule, labels),
+		Rule:        rule,
+		ContainerID: labels["container_id"],
+		Labels:      labels,
+		State:       AlertStateInactive,
+	}
+}
+func AlertFingerprint(rule string, labels map[string]string) string {
+	h := fnv.New64a()
+	h.Write([]byte(rule))
+	h.Write([]byte{0})
+	h.Write([]byte(SeriesKey("", labels)))
+	return fmt.Sprintf("%016x", h.Sum64())
+}
+func (i *AlertInstance) Observe(at time.Time, value float64, hold, maxGap time.Duration) bool {
+	if i.State != AlertStateFiring && (i.State != AlertStatePending || maxGap > 0 && at.Sub(i.LastBreachAt) > maxGap) {
+		i.State = AlertStatePending
+		i.ActiveAt = at
+	}
+	i.LastBreachAt = at
+	i.Value = value
+	if i.State == AlertStatePending && at.Sub(i.ActiveAt) >= hold {
+		i.State = AlertStateFiring
+		i.FiredAt = at
+	}
+	return i.State == AlertStateFiring
+}
+func (i *AlertInstance) NotificationDue(at time.Time, repeat time.Duration) bool {
+	if i.State != AlertStateFiring {
+		return false
+	}
+	return i.NotifiedAt.IsZero() || repeat > 0 && at.Sub(i.NotifiedAt) >= repeat
+}
+func (i *AlertInstance) Notified() bool {
+	return i.State == AlertStateFiring && !i.NotifiedAt.IsZero()
+}
+func (i *AlertInstance) Resolve(at time.Time, value float64) bool {
+	wasFiring := i.Notified()
+	i.State = AlertStateResolved
+	i.ResolvedAt = at
+	i.Value = value
+	return wasFiring
+}
\ No newline at end of file
@@ -0,0 +1,29 @@
+package entities
+import (
+	"testing"
+	"time"
+)
+func TestAlertInstanceObserveResetsPendingAfterGap(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	hold, maxGap := 5*time.Minute, 15*time.Second
+	instance := NewAlertInstance("CPU", map[string]string{"container_id": "c1"})
+	for at := start; at.Before(start.Add(time.Minute)); at = at.Add(5 * time.Second) {
+		if instance.Observe(at, 95, hold, maxGap) {
+			t.Fatal("expected pending before the hold elapsed")
+		}
+	}
+	resumed := start.Add(3 * time.Minute)
+	instance.Observe(resumed, 95, hold, maxGap)
+	if instance.State != AlertStatePending || !instance.ActiveAt.Equal(resumed) {
+		t.Fatalf("expected a gap longer than %s to restart pending, got %s since %s", maxGap, instance.State, instance.ActiveAt)
+	}
+	for at := resumed.Add(10 * time.Second); at.Before(resumed.Add(hold)); at = at.Add(10 * time.Second) {
+		if instance.Observe(at, 95, hold, maxGap) {
+			t.Fatalf("expected the restarted hold not to count time before the gap, fired at %s", at)
+		}
+	}
+	instance.Observe(resumed.Add(hold), 95, hold, maxGap)
+	if instance.State != AlertStateFiring || !instance.FiredAt.Equal(resumed.Add(hold)) {
+		t.Fatalf("expected firing once breaches lasted the hold without gaps, got %s at %s", instance.State, instance.FiredAt)
+	}
+}
\ No newline at end of file
@@ -0,0 +1,30 @@
+package entities
+import "testing"
+func TestAlertFormatValue(t *testing.T) {
+	for _, tc := range []struct {
+		alertType AlertType
+		unit      string
+		value     float64
+		expected  string
+	}{
+		{AlertTypeCPU, "", 91.234, "91.23%"},
+		{AlertTypeMemory, "", 85, "85.00%"},
+		{LogAlertType("oom"), "", 12, "12 lines"},
+		{"disk_io", "", 1.5, "1.50"},
+		{"HIGH_MEMORY", "bytes", 1048576, "1048576 bytes"},
+		{"HIGH_CPU", "percent", 95, "95.00%"},
+		{"PROCESS_CPU", "cores", 1.5, "1.50 cores"},
+	} {
+		alert := NewAlert("abc", "web", tc.alertType, tc.value, 0)
+		alert.Unit = tc.unit
+		if got := alert.FormatValue(tc.value); got != tc.expected {
+			t.Fatalf("%s %q: expected %q, got %q", tc.alertType, tc.unit, tc.expected, got)
+		}
+	}
+	if unit := MustParseMetricExpr("container_memory_usage_bytes").Unit(); unit != "bytes" {
+		t.Fatalf("expected single metric expression to carry its unit, got %q", unit)
+	}
+	if unit := MustParseMetricExpr("container_memory_usage_bytes / 1024").Unit(); unit != "" {
+		t.Fatalf("expected derived expression to have no unit, got %q", unit)
+	}
+}
\ No newline at end of file
@@ -0,0 +1,99 @@
+package entities
+import (
+	"fmt"
+	"path"
+	"time"
+)
+type LogStream string
+const (
+	LogStreamStdout LogStream = "stdout"
+	LogStreamStderr LogStream = "stderr"
+)
+type LogEntry struct {
+	Timestamp     time.Time
+	Source        string
+	Stream        LogStream
+	ContainerID   string
+	ContainerName string
+	Labels        map[string]string
+	Level         string
+	Message       string
+	Fields        map[string]string
+}
+func (e *LogEntry) LabelSet() map[string]string {
+	labels := make(map[string]string, len(e.Labels)+len(e.Fields)+5)
+	for name, value := range e.Fields {
+		labels[name] = value
+	}
+	for name, value := range e.StreamLabels() {
+		labels[name] = value
+	}
+	return labels
+}
+func (e *LogEntry) StreamLabels() map[string]string {
+	labels := make(map[string]string, len(e.Labels)+5)
+	for name, value := range e.Labels {
+		labels[name] = value
+	}
+	set := func(name, value string) {
+		if value != "" {
+			labels[name] = value
+		}
+	}
+	set("source", e.Source)
+	set("stream", string(e.Stream))
+	set("container_id", e.ContainerID)
+	set("container_name", e.ContainerName)
+	set("level", e.Level)
+	return labels
+}
+func LogEntryFromLabels(timestamp time.Time, labels map[string]string, message string, fields map[string]string) *LogEntry {
+	entry := &LogEntry{
+		Timestamp: timestamp,
+		Message:   message,
+		Fields:    fields,
+	}
+	for name, value := range labels {
+		switch name {
+		case "source":
+			entry.Source = value
+		case "stream":
+			entry.Stream = LogStream(value)
+		case "container_id":
+			entry.ContainerID = value
+		case "container_name":
+			entry.ContainerName = value
+		case "level":
+			entry.Level = value
+		default:
+			if entry.Labels == nil {
+				entry.Labels = make(map[string]string)
+			}
+			entry.Labels[name] = value
+		}
+	}
+	return entry
+}
+func MatchLabelSelector(selector map[string]string, labels map[string]string) bool {
+	for name, value := range selector {
+		actual, ok := labels[name]
+		if !ok {
+			return false
+		}
+		if matched, _ := path.Match(value, actual); !matched {
+			return false
+		}
+	}
+	return true
+}
+func ValidateLabelSelector(selector map[string]string) error {
+	for name, value := range selector {
+		if name == "" {
+			return fmt.Errorf("matcher needs a label name")
+		}
+		if _, err := path.Match(value, ""); err != nil {
+			return fmt.Errorf("invalid matcher %s=%q: %w", name, value, err)
+		}
+	}
+	return nil
+}
\ No newline at end of file
@@ -0,0 +1,41 @@
+package entities
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+const (
+	DefaultLogAlertWindow   = time.Minute
+	DefaultLogAlertCooldown = 5 * time.Minute
+)
+type LogAlertRule struct {
+	Name      string
+	Selector  map[string]string
+	Pattern   *regexp.Regexp
+	Threshold int
+	Window    time.Duration
+	Cooldown  time.Duration
+}
+func LogAlertType(rule string) AlertType {
+	return AlertType("LOG_" + strings.ToUpper(rule))
+}
+func (r LogAlertRule) AlertType() AlertType {
+	return LogAlertType(r.Name)
+}
+func (r LogAlertRule) Matches(entry *LogEntry) bool {
+	if len(r.Selector) > 0 && !MatchLabelSelector(r.Selector, entry.LabelSet()) {
+		return false
+	}
+	return r.Pattern == nil || r.Pattern.MatchString(entry.Message)
+}
+func LogAlertSubject(entry *LogEntry) (string, string) {
+	if entry.ContainerID != "" {
+		return entry.ContainerID, entry.ContainerName
+	}
+	for _, name := range []string{"hostname", "filename"} {
+		if value := entry.Labels[name]; value != "" {
+			return entry.Source + ":" + value, value
+		}
+	}
+	return entry.Source, entry.Source
+}
\ No newline at end of file
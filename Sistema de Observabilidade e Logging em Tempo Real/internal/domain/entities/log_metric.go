@@ -0,0 +1,34 @@
+package entities
+import "regexp"
+var DefaultLogMetricBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+type LogMetricRule struct {
+	Name       string
+	Type       MetricType
+	Selector   map[string]string
+	Pattern    *regexp.Regexp
+	ValueField string
+	Labels     []string
+	Buckets    []float64
+	Unit       string
+}
+func (r LogMetricRule) Matches(entry *LogEntry, labels map[string]string) bool {
+	if len(r.Selector) > 0 && !MatchLabelSelector(r.Selector, labels) {
+		return false
+	}
+	return r.Pattern == nil || r.Pattern.MatchString(entry.Message)
+}
+func (r LogMetricRule) SeriesLabels(entry *LogEntry, labels map[string]string) map[string]string {
+	series := make(map[string]string, len(r.Labels)+2)
+	if entry.ContainerID != "" {
+		series["container_id"] = entry.ContainerID
+	}
+	if entry.ContainerName != "" {
+		series["container_name"] = entry.ContainerName
+	}
+	for _, name := range r.Labels {
+		if value := labels[name]; value != "" {
+			series[name] = value
+		}
+	}
+	return series
+}
\ No newline at end of file
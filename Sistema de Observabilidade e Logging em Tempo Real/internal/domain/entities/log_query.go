@@ -0,0 +1,165 @@
+package entities
+import (
+	"encoding/base64"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+const (
+	DefaultLogQueryLimit = 100
+	MaxLogQueryLimit     = 1000
+)
+type LogQuery struct {
+	ContainerIDs   []string
+	ContainerNames []string
+	Labels         map[string]string
+	Levels         []string
+	Start          time.Time
+	End            time.Time
+	Contains       string
+	Regex          string
+	Limit          int
+	Cursor         string
+}
+type LogPage struct {
+	Entries    []*LogEntry
+	NextCursor string
+}
+func (q LogQuery) Validate() error {
+	if q.Limit < 0 || q.Limit > MaxLogQueryLimit {
+		return fmt.Errorf("limit must be between 1 and %d", MaxLogQueryLimit)
+	}
+	if !q.Start.IsZero() && !q.End.IsZero() && !q.End.After(q.Start) {
+		return fmt.Errorf("end must be after start")
+	}
+	if _, err := NewLogMatcher(q); err != nil {
+		return err
+	}
+	if q.Cursor != "" {
+		if _, _, err := DecodeLogCursor(q.Cursor); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+func (q LogQuery) PageSize() int {
+	if q.Limit <= 0 {
+		return DefaultLogQueryLimit
+	}
+	return q.Limit
+}
+func (q LogQuery) Resume() (LogQuery, int, error) {
+	fetch := q
+	fetch.Cursor = ""
+	fetch.Limit = q.PageSize() + 1
+	if q.Cursor == "" {
+		return fetch, 0, nil
+	}
+	last, skip, err := DecodeLogCursor(q.Cursor)
+	if err != nil {
+		return fetch, 0, err
+	}
+	fetch.End = last.Add(time.Nanosecond)
+	fetch.Limit += skip
+	return fetch, skip, nil
+}
+func (q LogQuery) Paginate(entries []*LogEntry, skip int) *LogPage {
+	if skip > len(entries) {
+		skip = len(entries)
+	}
+	entries = entries[skip:]
+	page := &LogPage{Entries: entries}
+	size := q.PageSize()
+	if len(entries) <= size {
+		return page
+	}
+	page.Entries = entries[:size]
+	last := page.Entries[size-1].Timestamp
+	ties := 0
+	for _, entry := range page.Entries {
+		if entry.Timestamp.Equal(last) {
+			ties++
+		}
+	}
+	if q.Cursor != "" {
+		if previous, _, err := DecodeLogCursor(q.Cursor); err == nil && previous.Equal(last) {
+			ties += skip
+		}
+	}
+	page.NextCursor = EncodeLogCursor(last, ties)
+	return page
+}
+func EncodeLogCursor(last time.Time, skip int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(last.UnixNano(), 10) + ":" + strconv.Itoa(skip)))
+}
+func DecodeLogCursor(cursor string) (time.Time, int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, 0, fmt.Errorf("invalid cursor")
+	}
+	tsText, skipText, ok := strings.Cut(string(raw), ":")
+	if !ok {
+		return time.Time{}, 0, fmt.Errorf("invalid cursor")
+	}
+	ts, err := strconv.ParseInt(tsText, 10, 64)
+	if err != nil {
+		return time.Time{}, 0, fmt.Errorf("invalid cursor")
+	}
+	skip, err := strconv.Atoi(skipText)
+	if err != nil || skip < 0 {
+		return time.Time{}, 0, fmt.Errorf("invalid cursor")
+	}
+	return time.Unix(0, ts).UTC(), skip, nil
+}
+type LogMatcher struct {
+	query    LogQuery
+	contains string
+	regex    *regexp.Regexp
+}
+func NewLogMatcher(query LogQuery) (*LogMatcher, error) {
+	matcher := &LogMatcher{query: query, contains: strings.ToLower(query.Contains)}
+	if query.Regex != "" {
+		regex, err := regexp.Compile(query.Regex)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regex: %w", err)
+		}
+		matcher.regex = regex
+	}
+	return matcher, nil
+}
+func (m *LogMatcher) Matches(entry *LogEntry) bool {
+	if !m.query.Start.IsZero() && entry.Timestamp.Before(m.query.Start) {
+		return false
+	}
+	if !m.query.End.IsZero() && !entry.Timestamp.Before(m.query.End) {
+		return false
+	}
+	if !matchesAny(m.query.ContainerIDs, entry.ContainerID) || !matchesAny(m.query.ContainerNames, entry.ContainerName) || !matchesAny(m.query.Levels, entry.Level) {
+		return false
+	}
+	if len(m.query.Labels) > 0 {
+		labels := entry.LabelSet()
+		for name, value := range m.query.Labels {
+			if labels[name] != value {
+				return false
+			}
+		}
+	}
+	if m.contains != "" && !strings.Contains(strings.ToLower(entry.Message), m.contains) {
+		return false
+	}
+	return m.regex == nil || m.regex.MatchString(entry.Message)
+}
+func matchesAny(values []string, value string) bool {
+	if len(values) == 0 {
+		return true
+	}
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
\ No newline at end of file
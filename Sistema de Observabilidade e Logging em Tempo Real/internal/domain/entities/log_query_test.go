@@ -0,0 +1,98 @@
+package entities
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+func TestLogMatcherMatchesParsedFields(t *testing.T) {
+	entry := &LogEntry{
+		Timestamp:   time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		ContainerID: "abc",
+		Level:       "info",
+		Labels:      map[string]string{"app": "web"},
+		Fields:      map[string]string{"user_id": "42", "level": "debug"},
+	}
+	for _, tc := range []struct {
+		labels map[string]string
+		match  bool
+	}{
+		{map[string]string{"user_id": "42"}, true},
+		{map[string]string{"user_id": "42", "app": "web"}, true},
+		{map[string]string{"user_id": "7"}, false},
+		{map[string]string{"level": "debug"}, false},
+		{map[string]string{"level": "info"}, true},
+	} {
+		matcher, err := NewLogMatcher(LogQuery{Labels: tc.labels})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := matcher.Matches(entry); got != tc.match {
+			t.Fatalf("labels %v: expected match=%v, got %v", tc.labels, tc.match, got)
+		}
+	}
+}
+func fetchLogs(stored []*LogEntry, query LogQuery) []*LogEntry {
+	matcher, _ := NewLogMatcher(query)
+	var out []*LogEntry
+	for _, entry := range stored {
+		if matcher.Matches(entry) {
+			out = append(out, entry)
+		}
+		if query.Limit > 0 && len(out) == query.Limit {
+			break
+		}
+	}
+	return out
+}
+func TestLogQueryPaginatesAcrossEqualTimestamps(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(second int, message string) *LogEntry {
+		return &LogEntry{Timestamp: base.Add(time.Duration(second) * time.Second), Message: message}
+	}
+	stored := []*LogEntry{at(5, "e"), at(4, "d1"), at(4, "d2"), at(4, "d3"), at(4, "d4"), at(4, "d5"), at(3, "c"), at(2, "b1"), at(2, "b2"), at(1, "a")}
+	for _, size := range []int{1, 2, 3, 4, 6} {
+		query := LogQuery{Limit: size}
+		var got []string
+		for pages := 0; ; pages++ {
+			if pages > len(stored) {
+				t.Fatalf("size %d: pagination did not terminate", size)
+			}
+			fetch, skip, err := query.Resume()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if fetch.Cursor != "" || fetch.Limit != size+1+skip {
+				t.Fatalf("size %d: unexpected fetch query %+v", size, fetch)
+			}
+			page := query.Paginate(fetchLogs(stored, fetch), skip)
+			if len(page.Entries) > size {
+				t.Fatalf("size %d: page of %d entries", size, len(page.Entries))
+			}
+			for _, entry := range page.Entries {
+				got = append(got, entry.Message)
+			}
+			if page.NextCursor == "" {
+				break
+			}
+			query.Cursor = page.NextCursor
+		}
+		if fmt.Sprint(got) != "[e d1 d2 d3 d4 d5 c b1 b2 a]" {
+			t.Fatalf("size %d: expected every entry once in order, got %v", size, got)
+		}
+	}
+}
+func TestLogQueryResumeRejectsInvalidCursor(t *testing.T) {
+	for _, cursor := range []string{"!!", EncodeLogCursor(time.Unix(1, 0), 0)[:3], "MTIzOi0x"} {
+		if _, _, err := (LogQuery{Cursor: cursor}).Resume(); err == nil {
+			t.Fatalf("expected cursor %q to be rejected", cursor)
+		}
+		if err := (LogQuery{Cursor: cursor}).Validate(); err == nil {
+			t.Fatalf("expected Validate to reject cursor %q", cursor)
+		}
+	}
+	last := time.Date(2024, 1, 1, 0, 0, 4, 0, time.UTC)
+	fetch, skip, err := (LogQuery{Limit: 10, Cursor: EncodeLogCursor(last, 3)}).Resume()
+	if err != nil || skip != 3 || fetch.Limit != 14 || !fetch.End.Equal(last.Add(time.Nanosecond)) {
+		t.Fatalf("unexpected resume %+v skip=%d err=%v", fetch, skip, err)
+	}
+}
\ No newline at end of file
@@ -1,26 +1,161 @@
 package entities
-import "time"
+import (
+	"math"
+	"sort"
+	"strconv"
+	"time"
+)
 type ContainerMetrics struct {
 	ContainerID   string
 	ContainerName string
+	Labels        map[string]string
 	CPUPercent    float64
 	MemoryUsage   uint64
 	MemoryLimit   uint64
 	MemoryPercent float64
 	NetworkRx     uint64
 	NetworkTx     uint64
+	DiskRead      uint64
+	DiskWrite     uint64
+	PIDs          uint64
 	Timestamp     time.Time
 }
-func (m *ContainerMetrics) IsHealthy(cpuThreshold, memoryThreshold float64) bool {
-	return m.CPUPercent <= cpuThreshold && m.MemoryPercent <= memoryThreshold
+type containerMetricDescriptor struct {
+	name       string
+	metricType MetricType
+	unit       string
+	get        func(m *ContainerMetrics) float64
+	set        func(m *ContainerMetrics, value float64)
 }
-func (m *ContainerMetrics) ExceedsThreshold(cpuThreshold, memoryThreshold float64) []string {
-	var violations []string
-	if m.CPUPercent > cpuThreshold {
-		violations = append(violations, "CPU")
+var containerMetricDescriptors = []containerMetricDescriptor{
+	{"container_cpu_usage_percent", MetricTypeGauge, "percent",
+		func(m *ContainerMetrics) float64 { return m.CPUPercent },
+		func(m *ContainerMetrics, v float64) { m.CPUPercent = v }},
+	{"container_memory_usage_bytes", MetricTypeGauge, "bytes",
+		func(m *ContainerMetrics) float64 { return float64(m.MemoryUsage) },
+		func(m *ContainerMetrics, v float64) { m.MemoryUsage = roundUint(v) }},
+	{"container_memory_limit_bytes", MetricTypeGauge, "bytes",
+		func(m *ContainerMetrics) float64 { return float64(m.MemoryLimit) },
+		func(m *ContainerMetrics, v float64) { m.MemoryLimit = roundUint(v) }},
+	{"container_memory_usage_percent", MetricTypeGauge, "percent",
+		func(m *ContainerMetrics) float64 { return m.MemoryPercent },
+		func(m *ContainerMetrics, v float64) { m.MemoryPercent = v }},
+	{"container_network_rx_bytes_total", MetricTypeCounter, "bytes",
+		func(m *ContainerMetrics) float64 { return float64(m.NetworkRx) },
+		func(m *ContainerMetrics, v float64) { m.NetworkRx = roundUint(v) }},
+	{"container_network_tx_bytes_total", MetricTypeCounter, "bytes",
+		func(m *ContainerMetrics) float64 { return float64(m.NetworkTx) },
+		func(m *ContainerMetrics, v float64) { m.NetworkTx = roundUint(v) }},
+	{"container_disk_read_bytes_total", MetricTypeCounter, "bytes",
+		func(m *ContainerMetrics) float64 { return float64(m.DiskRead) },
+		func(m *ContainerMetrics, v float64) { m.DiskRead = roundUint(v) }},
+	{"container_disk_write_bytes_total", MetricTypeCounter, "bytes",
+		func(m *ContainerMetrics) float64 { return float64(m.DiskWrite) },
+		func(m *ContainerMetrics, v float64) { m.DiskWrite = roundUint(v) }},
+	{"container_pids", MetricTypeGauge, "",
+		func(m *ContainerMetrics) float64 { return float64(m.PIDs) },
+		func(m *ContainerMetrics, v float64) { m.PIDs = roundUint(v) }},
+}
+func (m *ContainerMetrics) Samples() []Sample {
+	labels := make(map[string]string, len(m.Labels)+2)
+	for name, value := range m.Labels {
+		labels[name] = value
+	}
+	labels["container_id"] = m.ContainerID
+	labels["container_name"] = m.ContainerName
+	samples := make([]Sample, len(containerMetricDescriptors))
+	for i, descriptor := range containerMetricDescriptors {
+		samples[i] = Sample{
+			Name:      descriptor.name,
+			Labels:    labels,
+			Value:     descriptor.get(m),
+			Type:      descriptor.metricType,
+			Unit:      descriptor.unit,
+			Timestamp: m.Timestamp,
+		}
+	}
+	return samples
+}
+func ContainerMetricInfo(name string) (MetricType, string, bool) {
+	for _, descriptor := range containerMetricDescriptors {
+		if descriptor.name == name {
+			return descriptor.metricType, descriptor.unit, true
+		}
+	}
+	return "", "", false
+}
+func ContainerMetricsFromSamples(samples []Sample) []*ContainerMetrics {
+	shim := newContainerMetricsShim()
+	for _, sample := range samples {
+		shim.add(sample.Name, sample.Labels, sample.Timestamp, sample.Value)
+	}
+	return shim.metrics()
+}
+func ContainerMetricsFromSeries(series []*Series) []*ContainerMetrics {
+	shim := newContainerMetricsShim()
+	for _, s := range series {
+		for _, point := range s.Points {
+			shim.add(s.Name, s.Labels, point.Timestamp, point.Value)
+		}
+	}
+	return shim.metrics()
+}
+type containerMetricsShim struct {
+	rows  map[string]*ContainerMetrics
+	order []string
+}
+func newContainerMetricsShim() *containerMetricsShim {
+	return &containerMetricsShim{rows: make(map[string]*ContainerMetrics)}
+}
+func (s *containerMetricsShim) add(name string, labels map[string]string, timestamp time.Time, value float64) {
+	var descriptor *containerMetricDescriptor
+	for i := range containerMetricDescriptors {
+		if containerMetricDescriptors[i].name == name {
+			descriptor = &containerMetricDescriptors[i]
+			break
+		}
+	}
+	containerID := labels["container_id"]
+	if descriptor == nil || containerID == "" {
+		return
+	}
+	key := containerID + "|" + strconv.FormatInt(timestamp.UnixNano(), 10)
+	row, ok := s.rows[key]
+	if !ok {
+		row = &ContainerMetrics{ContainerID: containerID, Timestamp: timestamp}
+		for label, labelValue := range labels {
+			if label == "container_id" || label == "container_name" {
+				continue
+			}
+			if row.Labels == nil {
+				row.Labels = make(map[string]string)
+			}
+			row.Labels[label] = labelValue
+		}
+		s.rows[key] = row
+		s.order = append(s.order, key)
 	}
-	if m.MemoryPercent > memoryThreshold {
-		violations = append(violations, "MEMORY")
+	if containerName := labels["container_name"]; containerName != "" {
+		row.ContainerName = containerName
+	}
+	descriptor.set(row, value)
+}
+func (s *containerMetricsShim) metrics() []*ContainerMetrics {
+	result := make([]*ContainerMetrics, 0, len(s.order))
+	for _, key := range s.order {
+		result = append(result, s.rows[key])
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Timestamp.Equal(result[j].Timestamp) {
+			return result[i].ContainerID < result[j].ContainerID
+		}
+		return result[i].Timestamp.Before(result[j].Timestamp)
+	})
+	return result
+}
+func roundUint(value float64) uint64 {
+	if value <= 0 {
+		return 0
 	}
-	return violations
+	return uint64(math.Round(value))
 }
\ No newline at end of file
@@ -0,0 +1,146 @@
+package entities
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+const (
+	RetentionTierRaw    = "raw"
+	RetentionTierAlerts = "alerts"
+)
+const DefaultRetentionRules = "raw=7d; 1m=30d; 5m=90d; 1h=400d; alerts=1d"
+const RetentionDeletedUnknown int64 = -1
+type RetentionRule struct {
+	Tier     string
+	Selector map[string]string
+	MaxAge   time.Duration
+}
+type RetentionResult struct {
+	Rule    RetentionRule
+	Before  time.Time
+	Deleted int64
+}
+func (r RetentionRule) String() string {
+	if len(r.Selector) == 0 {
+		return r.Tier
+	}
+	pairs := make([]string, 0, len(r.Selector))
+	for name, value := range r.Selector {
+		pairs = append(pairs, name+"="+value)
+	}
+	sort.Strings(pairs)
+	return r.Tier + "{" + strings.Join(pairs, ",") + "}"
+}
+func (r RetentionRule) Matches(labels map[string]string) bool {
+	for name, value := range r.Selector {
+		if labels[name] != value {
+			return false
+		}
+	}
+	return true
+}
+func ParseRetentionRules(spec string) ([]RetentionRule, error) {
+	var rules []RetentionRule
+	base := make(map[string]time.Duration)
+	for _, entry := range strings.FieldsFunc(spec, func(r rune) bool { return r == ';' || r == '\n' }) {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		split := strings.LastIndex(entry, "=")
+		if split < 0 {
+			return nil, fmt.Errorf("retention rule %q: expected <tier>=<age>", entry)
+		}
+		target, ageText := strings.TrimSpace(entry[:split]), strings.TrimSpace(entry[split+1:])
+		maxAge, err := ParseRetentionAge(ageText)
+		if err != nil {
+			return nil, fmt.Errorf("retention rule %q: %w", entry, err)
+		}
+		rule := RetentionRule{Tier: target, MaxAge: maxAge}
+		if open := strings.Index(target, "{"); open >= 0 {
+			if !strings.HasSuffix(target, "}") {
+				return nil, fmt.Errorf("retention rule %q: unterminated selector", entry)
+			}
+			rule.Tier = strings.TrimSpace(target[:open])
+			rule.Selector = make(map[string]string)
+			for _, matcher := range strings.Split(target[open+1:len(target)-1], ",") {
+				name, value, ok := strings.Cut(matcher, "=")
+				name, value = strings.TrimSpace(name), strings.Trim(strings.TrimSpace(value), `"`)
+				if !ok || name == "" {
+					return nil, fmt.Errorf("retention rule %q: invalid matcher %q", entry, matcher)
+				}
+				rule.Selector[name] = value
+			}
+			if rule.Tier == RetentionTierAlerts {
+				return nil, fmt.Errorf("retention rule %q: alerts do not carry container labels", entry)
+			}
+		}
+		if rule.Tier == "" {
+			return nil, fmt.Errorf("retention rule %q: missing tier", entry)
+		}
+		if len(rule.Selector) == 0 {
+			if _, ok := base[rule.Tier]; ok {
+				return nil, fmt.Errorf("retention rule %q: duplicate rule for tier %s", entry, rule.Tier)
+			}
+			base[rule.Tier] = rule.MaxAge
+		}
+		rules = append(rules, rule)
+	}
+	for _, rule := range rules {
+		if limit, ok := base[rule.Tier]; ok && len(rule.Selector) > 0 && rule.MaxAge > limit {
+			return nil, fmt.Errorf("retention rule %s: %s exceeds the %s tier retention; selectors can only shorten retention", rule, rule.MaxAge, rule.Tier)
+		}
+	}
+	return rules, nil
+}
+func ParseRetentionAge(value string) (time.Duration, error) {
+	multiplier := time.Duration(0)
+	switch {
+	case strings.HasSuffix(value, "d"):
+		multiplier = 24 * time.Hour
+	case strings.HasSuffix(value, "w"):
+		multiplier = 7 * 24 * time.Hour
+	case strings.HasSuffix(value, "y"):
+		multiplier = 365 * 24 * time.Hour
+	}
+	var age time.Duration
+	if multiplier > 0 {
+		n, err := strconv.Atoi(value[:len(value)-1])
+		if err != nil {
+			return 0, fmt.Errorf("invalid age %q", value)
+		}
+		age = time.Duration(n) * multiplier
+	} else {
+		parsed, err := time.ParseDuration(value)
+		if err != nil {
+			return 0, fmt.Errorf("invalid age %q", value)
+		}
+		age = parsed
+	}
+	if age <= 0 {
+		return 0, fmt.Errorf("invalid age %q", value)
+	}
+	return age, nil
+}
+func RetentionFor(rules []RetentionRule, tier string) time.Duration {
+	for _, rule := range rules {
+		if rule.Tier == tier && len(rule.Selector) == 0 {
+			return rule.MaxAge
+		}
+	}
+	return 0
+}
+func RetentionRulesForTiers(rules []RetentionRule, tiers ...string) []RetentionRule {
+	var selected []RetentionRule
+	for _, rule := range rules {
+		for _, tier := range tiers {
+			if rule.Tier == tier {
+				selected = append(selected, rule)
+				break
+			}
+		}
+	}
+	return selected
+}
\ No newline at end of file
@@ -0,0 +1,59 @@
+package entities
+import (
+	"strings"
+	"testing"
+	"time"
+)
+func TestParseRetentionRules(t *testing.T) {
+	rules, err := ParseRetentionRules(`raw=7d; 1m=30d
+raw{container_name="noisy", env=dev}=12h; alerts=1w; 1h=1y`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []struct {
+		rule   string
+		maxAge time.Duration
+	}{
+		{"raw", 7 * 24 * time.Hour},
+		{"1m", 30 * 24 * time.Hour},
+		{"raw{container_name=noisy,env=dev}", 12 * time.Hour},
+		{"alerts", 7 * 24 * time.Hour},
+		{"1h", 365 * 24 * time.Hour},
+	}
+	if len(rules) != len(expected) {
+		t.Fatalf("expected %d rules, got %v", len(expected), rules)
+	}
+	for i, want := range expected {
+		if rules[i].String() != want.rule || rules[i].MaxAge != want.maxAge {
+			t.Fatalf("rule %d: expected %s=%s, got %s=%s", i, want.rule, want.maxAge, rules[i], rules[i].MaxAge)
+		}
+	}
+	if RetentionFor(rules, RetentionTierRaw) != 7*24*time.Hour || RetentionFor(rules, "5m") != 0 {
+		t.Fatal("expected RetentionFor to ignore selector rules and unknown tiers")
+	}
+	if owned := RetentionRulesForTiers(rules, "1m", RetentionTierAlerts); len(owned) != 2 || owned[0].Tier != "1m" || owned[1].Tier != RetentionTierAlerts {
+		t.Fatalf("expected only the 1m and alerts rules, got %v", owned)
+	}
+	if !rules[2].Matches(map[string]string{"container_name": "noisy", "env": "dev", "extra": "x"}) || rules[2].Matches(map[string]string{"container_name": "noisy"}) {
+		t.Fatal("expected selector rules to match on every matcher")
+	}
+}
+func TestParseRetentionRulesRejectsInvalidSpecs(t *testing.T) {
+	cases := map[string]string{
+		"raw":                     "expected <tier>=<age>",
+		"raw=soon":                "invalid age",
+		"raw=0s":                  "invalid age",
+		"raw=-1d":                 "invalid age",
+		"=7d":                     "missing tier",
+		"raw{env=dev=1d":          "unterminated selector",
+		"raw{env}=1d":             "invalid matcher",
+		"alerts{env=dev}=1d":      "alerts do not carry container labels",
+		"raw=7d; raw=1d":          "duplicate rule",
+		"raw=1d; raw{env=dev}=7d": "selectors can only shorten retention",
+	}
+	for spec, message := range cases {
+		if _, err := ParseRetentionRules(spec); err == nil || !strings.Contains(err.Error(), message) {
+			t.Errorf("%q: expected error containing %q, got %v", spec, message, err)
+		}
+	}
+}
\ No newline at end of file
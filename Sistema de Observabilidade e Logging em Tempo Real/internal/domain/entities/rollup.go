@@ -0,0 +1,100 @@
+package entities
+import (
+	"math"
+	"sort"
+	"time"
+)
+type RollupTier struct {
+	Name       string
+	Resolution time.Duration
+	Retention  time.Duration
+}
+var DefaultRollupTiers = []RollupTier{
+	{Name: "1m", Resolution: time.Minute},
+	{Name: "5m", Resolution: 5 * time.Minute},
+	{Name: "1h", Resolution: time.Hour},
+}
+type MetricsRollup struct {
+	Name      string
+	Labels    map[string]string
+	Type      MetricType
+	Unit      string
+	Tier      string
+	Timestamp time.Time
+	Min       float64
+	Max       float64
+	Mean      float64
+	P95       float64
+	Last      float64
+	Count     uint64
+}
+func NewMetricsRollup(tier RollupTier, series *Series, windowStart time.Time, values []float64) *MetricsRollup {
+	rollup := &MetricsRollup{
+		Name:      series.Name,
+		Labels:    series.Labels,
+		Type:      series.Type,
+		Unit:      series.Unit,
+		Tier:      tier.Name,
+		Timestamp: windowStart,
+		Count:     uint64(len(values)),
+	}
+	if len(values) == 0 {
+		return rollup
+	}
+	rollup.Last = values[len(values)-1]
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	rollup.Min = sorted[0]
+	rollup.Max = sorted[len(sorted)-1]
+	var sum float64
+	for _, v := range sorted {
+		sum += v
+	}
+	rollup.Mean = sum / float64(len(sorted))
+	rank := int(math.Ceil(0.95*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	rollup.P95 = sorted[rank]
+	return rollup
+}
+func RollupTiersWithRetention(tiers []RollupTier, rules []RetentionRule) []RollupTier {
+	result := make([]RollupTier, len(tiers))
+	for i, tier := range tiers {
+		tier.Retention = RetentionFor(rules, tier.Name)
+		result[i] = tier
+	}
+	return result
+}
+func SelectRollupTier(tiers []RollupTier, duration, step time.Duration) (RollupTier, bool) {
+	var selected RollupTier
+	found := false
+	for _, tier := range tiers {
+		if tier.Resolution > step || tier.Resolution > duration {
+			continue
+		}
+		if tier.Retention > 0 && tier.Retention < duration {
+			continue
+		}
+		if !found || tier.Resolution > selected.Resolution {
+			selected, found = tier, true
+		}
+	}
+	return selected, found
+}
+func RollupsToSeries(rollups []*MetricsRollup) []*Series {
+	index := make(map[string]*Series)
+	var series []*Series
+	for _, rollup := range rollups {
+		key := SeriesKey(rollup.Name, rollup.Labels)
+		s, ok := index[key]
+		if !ok {
+			s = &Series{Name: rollup.Name, Labels: rollup.Labels, Type: rollup.Type, Unit: rollup.Unit}
+			index[key] = s
+			series = append(series, s)
+		}
+		s.Points = append(s.Points, SeriesPoint{Timestamp: rollup.Timestamp, Value: rollup.Mean})
+	}
+	SortSeries(series)
+	return series
+}
\ No newline at end of file
@@ -0,0 +1,97 @@
+package entities
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+type MetricType string
+const (
+	MetricTypeGauge     MetricType = "gauge"
+	MetricTypeCounter   MetricType = "counter"
+	MetricTypeHistogram MetricType = "histogram"
+)
+const MetricNameLabel = "__name__"
+type HistogramBucket struct {
+	UpperBound float64
+	Count      uint64
+}
+type Sample struct {
+	Name      string
+	Labels    map[string]string
+	Value     float64
+	Type      MetricType
+	Unit      string
+	Count     uint64
+	Buckets   []HistogramBucket
+	Timestamp time.Time
+}
+type SeriesPoint struct {
+	Timestamp time.Time
+	Value     float64
+}
+type Series struct {
+	Name   string
+	Labels map[string]string
+	Type   MetricType
+	Unit   string
+	Points []SeriesPoint
+}
+func (s Sample) Expand() []Sample {
+	if s.Type != MetricTypeHistogram {
+		return []Sample{s}
+	}
+	expanded := make([]Sample, 0, len(s.Buckets)+2)
+	for _, bucket := range s.Buckets {
+		labels := make(map[string]string, len(s.Labels)+1)
+		for name, value := range s.Labels {
+			labels[name] = value
+		}
+		labels["le"] = strconv.FormatFloat(bucket.UpperBound, 'g', -1, 64)
+		expanded = append(expanded, Sample{
+			Name:      s.Name + "_bucket",
+			Labels:    labels,
+			Value:     float64(bucket.Count),
+			Type:      MetricTypeCounter,
+			Timestamp: s.Timestamp,
+		})
+	}
+	expanded = append(expanded,
+		Sample{Name: s.Name + "_sum", Labels: s.Labels, Value: s.Value, Type: MetricTypeCounter, Unit: s.Unit, Timestamp: s.Timestamp},
+		Sample{Name: s.Name + "_count", Labels: s.Labels, Value: float64(s.Count), Type: MetricTypeCounter, Timestamp: s.Timestamp},
+	)
+	return expanded
+}
+func (s *Series) Key() string {
+	return SeriesKey(s.Name, s.Labels)
+}
+func SeriesKey(name string, labels map[string]string) string {
+	names := make([]string, 0, len(labels))
+	for label := range labels {
+		names = append(names, label)
+	}
+	sort.Strings(names)
+	var b strings.Builder
+	b.WriteString(name)
+	b.WriteByte('{')
+	for i, label := range names {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(label)
+		b.WriteByte('=')
+		b.WriteString(strconv.Quote(labels[label]))
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+func SortSeries(series []*Series) {
+	sort.Slice(series, func(i, j int) bool {
+		return series[i].Key() < series[j].Key()
+	})
+	for _, s := range series {
+		sort.SliceStable(s.Points, func(i, j int) bool {
+			return s.Points[i].Timestamp.Before(s.Points[j].Timestamp)
+		})
+	}
+}
\ No newline at end of file
@@ -0,0 +1,77 @@
+package entities
+import (
+	"fmt"
+	"time"
+)
+const MaxMaintenanceWindow = 7 * 24 * time.Hour
+type Silence struct {
+	ID        string
+	Matchers  map[string]string
+	StartsAt  time.Time
+	EndsAt    time.Time
+	CreatedBy string
+	Comment   string
+	CreatedAt time.Time
+}
+type MaintenanceWindow struct {
+	Name     string
+	Matchers map[string]string
+	Schedule *CronSchedule
+	Duration time.Duration
+	Location *time.Location
+	Comment  string
+}
+func (s *Silence) Validate() error {
+	if len(s.Matchers) == 0 {
+		return fmt.Errorf("silence needs at least one matcher")
+	}
+	if err := ValidateLabelSelector(s.Matchers); err != nil {
+		return fmt.Errorf("silence %w", err)
+	}
+	if s.CreatedBy == "" {
+		return fmt.Errorf("silence needs a creator")
+	}
+	if !s.EndsAt.After(s.StartsAt) {
+		return fmt.Errorf("silence must end after it starts")
+	}
+	return nil
+}
+func (s *Silence) Active(at time.Time) bool {
+	return !at.Before(s.StartsAt) && at.Before(s.EndsAt)
+}
+func (s *Silence) Expired(at time.Time) bool {
+	return !at.Before(s.EndsAt)
+}
+func (s *Silence) Matches(labels map[string]string) bool {
+	return MatchLabelSelector(s.Matchers, labels)
+}
+func (w MaintenanceWindow) Active(at time.Time) bool {
+	if w.Location != nil {
+		at = at.In(w.Location)
+	}
+	start := at.Truncate(time.Minute)
+	for candidate := start; at.Sub(candidate) < w.Duration; candidate = candidate.Add(-time.Minute) {
+		if w.Schedule.Matches(candidate) {
+			return true
+		}
+	}
+	return false
+}
+func (w MaintenanceWindow) Matches(labels map[string]string) bool {
+	return MatchLabelSelector(w.Matchers, labels)
+}
+func AlertSilenceLabels(alert *Alert) map[string]string {
+	labels := make(map[string]string, len(alert.Labels)+4)
+	for name, value := range alert.Labels {
+		labels[name] = value
+	}
+	labels["alertname"] = string(alert.Type)
+	labels["container_id"] = alert.ContainerID
+	if alert.ContainerName != "" {
+		labels["container_name"] = alert.ContainerName
+	}
+	if alert.Severity != "" {
+		labels["severity"] = alert.Severity
+	}
+	return labels
+}
\ No newline at end of file
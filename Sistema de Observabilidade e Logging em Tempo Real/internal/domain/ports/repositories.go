@@ -5,9 +5,14 @@ import (
 	"observability-system/internal/domain/entities"
 )
 type MetricsRepository interface {
-	Save(ctx context.Context, metrics *entities.ContainerMetrics) error
-	FindByContainerID(ctx context.Context, containerID string, duration time.Duration) ([]*entities.ContainerMetrics, error)
-	FindAll(ctx context.Context, duration time.Duration) ([]*entities.ContainerMetrics, error)
+	Save(ctx context.Context, samples []entities.Sample) error
+	FindSeries(ctx context.Context, selector map[string]string, duration time.Duration) ([]*entities.Series, error)
+	Close() error
+}
+type RollupRepository interface {
+	SaveRollups(ctx context.Context, rollups []*entities.MetricsRollup) error
+	FindRollups(ctx context.Context, tier string, selector map[string]string, duration time.Duration) ([]*entities.MetricsRollup, error)
+	LastRollupTime(ctx context.Context, tier string, lookback time.Duration) (time.Time, error)
 	Close() error
 }
 type AlertRepository interface {
@@ -15,4 +20,28 @@ type AlertRepository interface {
 	IsInCooldown(ctx context.Context, containerID string, alertType entities.AlertType) (bool, error)
 	SetCooldown(ctx context.Context, containerID string, alertType entities.AlertType, duration time.Duration) error
 	Close() error
+}
+type MetricsRetentionStore interface {
+	DeleteMetrics(ctx context.Context, tier string, selector map[string]string, before time.Time) (int64, error)
+}
+type AlertStateRepository interface {
+	ListAlertStates(ctx context.Context) ([]*entities.AlertInstance, error)
+	SaveAlertStates(ctx context.Context, saved []*entities.AlertInstance, deleted []string) error
+}
+type SilenceRepository interface {
+	SaveSilence(ctx context.Context, silence *entities.Silence) error
+	ListSilences(ctx context.Context) ([]*entities.Silence, error)
+	Close() error
+}
+type AlertRetentionStore interface {
+	DeleteAlertsBefore(ctx context.Context, before time.Time) (int64, error)
+}
+type LogRepository interface {
+	WriteLogs(ctx context.Context, entries []*entities.LogEntry) error
+	QueryLogs(ctx context.Context, query entities.LogQuery) ([]*entities.LogEntry, error)
+	Close() error
+}
+type LogFeed interface {
+	LatestLogCursor(ctx context.Context) (string, error)
+	ReadLogsSince(ctx context.Context, cursor string, limit int) ([]*entities.LogEntry, string, error)
 }
\ No newline at end of file
@@ -1,18 +1,57 @@
 package ports
 import (
 	"context"
+	"errors"
+	"time"
 	"observability-system/internal/domain/entities"
 )
 type ContainerCollector interface {
 	ListContainers(ctx context.Context) ([]string, error)
-	CollectMetrics(ctx context.Context, containerID string) (*entities.ContainerMetrics, error)
+	CollectSamples(ctx context.Context, containerID string) ([]entities.Sample, error)
 	Close() error
 }
+type LogSource interface {
+	Run(ctx context.Context, entries chan<- *entities.LogEntry) error
+	Close() error
+}
+type LogAcknowledger interface {
+	Acknowledge(entries []*entities.LogEntry)
+}
+type LogAggregator interface {
+	Add(entry *entities.LogEntry) []*entities.LogEntry
+	Expire(now time.Time) []*entities.LogEntry
+	Drain() []*entities.LogEntry
+}
+type LogParser interface {
+	Parse(entry *entities.LogEntry)
+}
+type LogSink interface {
+	WriteLogs(ctx context.Context, entries []*entities.LogEntry) error
+}
+type LogMetricExtractor interface {
+	Observe(entry *entities.LogEntry)
+	Collect(now time.Time) []entities.Sample
+}
+type SampleRecorder interface {
+	RecordSamples(samples []entities.Sample)
+}
+type Redactor interface {
+	RedactLog(entry *entities.LogEntry)
+	RedactAlert(alert *entities.Alert)
+}
+var ErrSilenced = errors.New("alert silenced")
 type Notifier interface {
 	Notify(ctx context.Context, alert *entities.Alert) error
 }
+type AlertSilencer interface {
+	IsSilenced(ctx context.Context, alert *entities.Alert) (bool, error)
+}
+type LogBroadcaster interface {
+	LogSubscribers() int
+	BroadcastLogs(entries []*entities.LogEntry)
+}
 type MetricsBroadcaster interface {
-	Broadcast(metrics []*entities.ContainerMetrics) error
+	Broadcast(samples []entities.Sample) error
 	RegisterClient(client interface{}) error
 	UnregisterClient(client interface{}) error
 }
\ No newline at end of file
@@ -0,0 +1,223 @@
+package adapters
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"observability-system/internal/domain/entities"
+)
+var cgroupContainerPattern = regexp.MustCompile(`^(?:(docker|cri-containerd|crio|libpod)-)?([0-9a-f]{64})(?:\.scope)?$`)
+type CgroupCollectorAdapter struct {
+	root       string
+	meminfo    string
+	containers map[string]cgroupContainer
+	samples    map[string]cgroupCPUSample
+	now        func() time.Time
+	mu         sync.Mutex
+}
+type cgroupContainer struct {
+	path    string
+	runtime string
+}
+type cgroupCPUSample struct {
+	usageUsec uint64
+	takenAt   time.Time
+}
+func NewCgroupCollectorAdapter(root string) (*CgroupCollectorAdapter, error) {
+	if root == "" {
+		root = "/sys/fs/cgroup"
+	}
+	if _, err := os.Stat(filepath.Join(root, "cgroup.controllers")); err != nil {
+		return nil, fmt.Errorf("cgroup v2 hierarchy not available at %s: %w", root, err)
+	}
+	return &CgroupCollectorAdapter{
+		root:       root,
+		meminfo:    "/proc/meminfo",
+		containers: make(map[string]cgroupContainer),
+		samples:    make(map[string]cgroupCPUSample),
+		now:        time.Now,
+	}, nil
+}
+func (c *CgroupCollectorAdapter) ListContainers(ctx context.Context) ([]string, error) {
+	found := make(map[string]cgroupContainer)
+	var ids []string
+	err := filepath.WalkDir(c.root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == c.root {
+				return err
+			}
+			return nil
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		match := cgroupContainerPattern.FindStringSubmatch(d.Name())
+		if match == nil {
+			return nil
+		}
+		id := match[2]
+		if _, ok := found[id]; !ok {
+			runtime := match[1]
+			if runtime == "" {
+				runtime = filepath.Base(filepath.Dir(path))
+			}
+			found[id] = cgroupContainer{path: path, runtime: runtime}
+			ids = append(ids, id)
+		}
+		return filepath.SkipDir
+	})
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	c.containers = found
+	for id := range c.samples {
+		if _, ok := found[id]; !ok {
+			delete(c.samples, id)
+		}
+	}
+	c.mu.Unlock()
+	return ids, nil
+}
+func (c *CgroupCollectorAdapter) CollectSamples(ctx context.Context, containerID string) ([]entities.Sample, error) {
+	metrics, err := c.CollectMetrics(ctx, containerID)
+	if err != nil || metrics == nil {
+		return nil, err
+	}
+	return metrics.Samples(), nil
+}
+func (c *CgroupCollectorAdapter) CollectMetrics(ctx context.Context, containerID string) (*entities.ContainerMetrics, error) {
+	c.mu.Lock()
+	container, ok := c.containers[containerID]
+	c.mu.Unlock()
+	if !ok {
+		return nil, nil
+	}
+	cpuStat, err := readKeyValueFields(filepath.Join(container.path, "cpu.stat"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	memoryUsage, err := readCgroupUint(filepath.Join(container.path, "memory.current"))
+	if err != nil {
+		return nil, err
+	}
+	memoryLimit, err := readCgroupUint(filepath.Join(container.path, "memory.max"))
+	if err != nil {
+		return nil, err
+	}
+	if memoryLimit == 0 {
+		memoryLimit = c.hostMemTotal()
+	}
+	pids, err := readCgroupUint(filepath.Join(container.path, "pids.current"))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	readBytes, writeBytes, err := readCgroupIOStat(filepath.Join(container.path, "io.stat"))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	usageUsec, _ := strconv.ParseUint(cpuStat["usage_usec"], 10, 64)
+	var memPercent float64
+	if memoryLimit > 0 {
+		memPercent = float64(memoryUsage) / float64(memoryLimit) * 100.0
+	}
+	now := c.now()
+	return &entities.ContainerMetrics{
+		ContainerID:   containerID,
+		ContainerName: fmt.Sprintf("%s-%s", container.runtime, containerID[:12]),
+		CPUPercent:    c.cpuPercent(containerID, usageUsec, now),
+		MemoryUsage:   memoryUsage,
+		MemoryLimit:   memoryLimit,
+		MemoryPercent: memPercent,
+		DiskRead:      readBytes,
+		DiskWrite:     writeBytes,
+		PIDs:          pids,
+		Timestamp:     now,
+	}, nil
+}
+func (c *CgroupCollectorAdapter) Close() error {
+	return nil
+}
+func (c *CgroupCollectorAdapter) hostMemTotal() uint64 {
+	values, err := readKeyValueFile(c.meminfo)
+	if err != nil {
+		return 0
+	}
+	return parseKilobytes(values["MemTotal"])
+}
+func (c *CgroupCollectorAdapter) cpuPercent(containerID string, usageUsec uint64, now time.Time) float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	previous, ok := c.samples[containerID]
+	c.samples[containerID] = cgroupCPUSample{usageUsec: usageUsec, takenAt: now}
+	elapsed := now.Sub(previous.takenAt).Microseconds()
+	if !ok || elapsed <= 0 || usageUsec < previous.usageUsec {
+		return 0.0
+	}
+	return float64(usageUsec-previous.usageUsec) / float64(elapsed) * 100.0
+}
+func readCgroupUint(path string) (uint64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	value := strings.TrimSpace(string(data))
+	if value == "max" {
+		return 0, nil
+	}
+	return strconv.ParseUint(value, 10, 64)
+}
+func readKeyValueFields(path string) (map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	values := make(map[string]string)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 {
+			values[fields[0]] = fields[1]
+		}
+	}
+	return values, scanner.Err()
+}
+func readCgroupIOStat(path string) (uint64, uint64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer file.Close()
+	var readBytes, writeBytes uint64
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		for _, field := range strings.Fields(scanner.Text()) {
+			key, value, ok := strings.Cut(field, "=")
+			if !ok {
+				continue
+			}
+			n, err := strconv.ParseUint(value, 10, 64)
+			if err != nil {
+				continue
+			}
+			switch key {
+			case "rbytes":
+				readBytes += n
+			case "wbytes":
+				writeBytes += n
+			}
+		}
+	}
+	return readBytes, writeBytes, scanner.Err()
+}
\ No newline at end of file
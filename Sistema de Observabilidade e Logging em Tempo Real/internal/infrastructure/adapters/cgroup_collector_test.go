@@ -0,0 +1,112 @@
+package adapters
+import (
+	"context"
+	"math"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+const (
+	dockerContainerID = "1111111111111111111111111111111111111111111111111111111111111111"
+	podmanContainerID = "2222222222222222222222222222222222222222222222222222222222222222"
+	cgroupfsContainer = "3333333333333333333333333333333333333333333333333333333333333333"
+)
+func newCgroupFixture(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	writeProcFixture(t, root, "cgroup.controllers", "cpu io memory pids\n")
+	writeProcFixture(t, root, "system.slice/sshd.service/cpu.stat", "usage_usec 1\n")
+	docker := filepath.Join("system.slice", "docker-"+dockerContainerID+".scope")
+	writeProcFixture(t, root, filepath.Join(docker, "cpu.stat"), "usage_usec 1000000\nuser_usec 600000\nsystem_usec 400000\n")
+	writeProcFixture(t, root, filepath.Join(docker, "memory.current"), "268435456\n")
+	writeProcFixture(t, root, filepath.Join(docker, "memory.max"), "536870912\n")
+	writeProcFixture(t, root, filepath.Join(docker, "io.stat"), "8:0 rbytes=1000 wbytes=2000 rios=1 wios=2 dbytes=0 dios=0\n8:16 rbytes=24 wbytes=48 rios=1 wios=1 dbytes=0 dios=0\n")
+	writeProcFixture(t, root, filepath.Join(docker, "pids.current"), "7\n")
+	podman := filepath.Join("machine.slice", "libpod-"+podmanContainerID+".scope")
+	writeProcFixture(t, root, filepath.Join(podman, "cpu.stat"), "usage_usec 5\n")
+	writeProcFixture(t, root, filepath.Join(podman, "memory.current"), "1024\n")
+	writeProcFixture(t, root, filepath.Join(podman, "memory.max"), "max\n")
+	writeProcFixture(t, root, filepath.Join(podman, "container", "cpu.stat"), "usage_usec 5\n")
+	writeProcFixture(t, root, filepath.Join("docker", cgroupfsContainer, "cpu.stat"), "usage_usec 5\n")
+	writeProcFixture(t, root, filepath.Join("docker", cgroupfsContainer, "memory.current"), "1\n")
+	writeProcFixture(t, root, filepath.Join("docker", cgroupfsContainer, "memory.max"), "max\n")
+	return root
+}
+func TestCgroupCollectorMapsPathsToContainerIDs(t *testing.T) {
+	collector, err := NewCgroupCollectorAdapter(newCgroupFixture(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids, err := collector.ListContainers(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(ids)
+	expected := []string{dockerContainerID, podmanContainerID, cgroupfsContainer}
+	if strings.Join(ids, ",") != strings.Join(expected, ",") {
+		t.Fatalf("expected %v, got %v", expected, ids)
+	}
+	metrics, err := collector.CollectMetrics(context.Background(), cgroupfsContainer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if metrics.ContainerName != "docker-333333333333" {
+		t.Fatalf("unexpected name %s", metrics.ContainerName)
+	}
+}
+func TestCgroupCollectorReadsControllerFiles(t *testing.T) {
+	root := newCgroupFixture(t)
+	collector, err := NewCgroupCollectorAdapter(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Unix(1700000000, 0)
+	collector.now = func() time.Time { return now }
+	collector.meminfo = filepath.Join(root, "meminfo")
+	ctx := context.Background()
+	if _, err := collector.ListContainers(ctx); err != nil {
+		t.Fatal(err)
+	}
+	first, err := collector.CollectMetrics(ctx, dockerContainerID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.CPUPercent != 0 {
+		t.Fatalf("expected 0%% CPU on first sample, got %.2f", first.CPUPercent)
+	}
+	if first.MemoryUsage != 268435456 || first.MemoryLimit != 536870912 || first.MemoryPercent != 50 {
+		t.Fatalf("unexpected memory %d/%d %.2f", first.MemoryUsage, first.MemoryLimit, first.MemoryPercent)
+	}
+	if first.DiskRead != 1024 || first.DiskWrite != 2048 || first.PIDs != 7 {
+		t.Fatalf("unexpected io/pids %d/%d/%d", first.DiskRead, first.DiskWrite, first.PIDs)
+	}
+	if first.ContainerName != "docker-111111111111" {
+		t.Fatalf("unexpected name %s", first.ContainerName)
+	}
+	now = now.Add(2 * time.Second)
+	writeProcFixture(t, root, filepath.Join("system.slice", "docker-"+dockerContainerID+".scope", "cpu.stat"), "usage_usec 2500000\n")
+	second, err := collector.CollectMetrics(ctx, dockerContainerID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(second.CPUPercent-75.0) > 0.001 {
+		t.Fatalf("expected 75%% CPU, got %.4f", second.CPUPercent)
+	}
+	unlimited, err := collector.CollectMetrics(ctx, podmanContainerID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if unlimited.MemoryLimit != 0 || unlimited.MemoryPercent != 0 || unlimited.ContainerName != "libpod-222222222222" {
+		t.Fatalf("expected no limit without /proc/meminfo, got %+v", unlimited)
+	}
+	writeProcFixture(t, root, "meminfo", "MemTotal:        4 kB\nMemFree:         2 kB\n")
+	unlimited, err = collector.CollectMetrics(ctx, podmanContainerID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if unlimited.MemoryLimit != 4096 || unlimited.MemoryPercent != 25 {
+		t.Fatalf("expected unlimited containers to use host memory, got %d %.2f", unlimited.MemoryLimit, unlimited.MemoryPercent)
+	}
+}
\ No newline at end of file
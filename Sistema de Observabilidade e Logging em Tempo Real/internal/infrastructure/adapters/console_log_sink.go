@@ -0,0 +1,28 @@
+package adapters
+import (
+	"context"
+	"log"
+	"time"
+	"observability-system/internal/domain/entities"
+)
+type ConsoleLogSink struct{}
+func NewConsoleLogSink() *ConsoleLogSink {
+	return &ConsoleLogSink{}
+}
+func (s *ConsoleLogSink) WriteLogs(ctx context.Context, entries []*entities.LogEntry) error {
+	for _, entry := range entries {
+		origin := entry.ContainerName
+		if origin == "" {
+			origin = entry.Labels["filename"]
+		}
+		log.Printf("📝 [%s] %s %s/%s %s: %s",
+			entry.Timestamp.Format(time.RFC3339),
+			origin,
+			entry.Source,
+			entry.Stream,
+			entry.Level,
+			entry.Message,
+		)
+	}
+	return nil
+}
\ No newline at end of file
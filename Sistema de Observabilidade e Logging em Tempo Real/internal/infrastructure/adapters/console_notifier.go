@@ -10,7 +10,11 @@ func NewConsoleNotifier() *ConsoleNotifier {
 	return &ConsoleNotifier{}
 }
 func (n *ConsoleNotifier) Notify(ctx context.Context, alert *entities.Alert) error {
-	log.Printf("🚨 ALERT [%s] %s - %s: %s",
+	format := "🚨 ALERT [%s] %s - %s: %s"
+	if alert.Resolved() {
+		format = "✅ RESOLVED [%s] %s - %s: %s"
+	}
+	log.Printf(format,
 		alert.Timestamp.Format(time.RFC3339),
 		alert.ContainerName,
 		alert.Type,
@@ -45,11 +45,20 @@ func (n *DiscordNotifier) Notify(ctx context.Context, alert *entities.Alert) err
 	if alert.Value > alert.Threshold*1.2 {
 		color = 16711680
 	}
+	content, title := "🚨 **Container Alert**", fmt.Sprintf("%s - %s Alert", alert.ContainerName, alert.Type)
+	if alert.Resolved() {
+		color = 3066993
+		content, title = "✅ **Container Alert Resolved**", fmt.Sprintf("%s - %s Resolved", alert.ContainerName, alert.Type)
+	}
+	containerID := alert.ContainerID
+	if len(containerID) > 12 {
+		containerID = containerID[:12]
+	}
 	msg := discordMessage{
-		Content: "🚨 **Container Alert**",
+		Content: content,
 		Embeds: []discordEmbed{
 			{
-				Title:       fmt.Sprintf("%s - %s Alert", alert.ContainerName, alert.Type),
+				Title:       title,
 				Description: alert.Message,
 				Color:       color,
 				Fields: []discordEmbedField{
@@ -65,17 +74,17 @@ func (n *DiscordNotifier) Notify(ctx context.Context, alert *entities.Alert) err
 					},
 					{
 						Name:   "Value",
-						Value:  fmt.Sprintf("%.2f%%", alert.Value),
+						Value:  alert.FormatValue(alert.Value),
 						Inline: true,
 					},
 					{
 						Name:   "Threshold",
-						Value:  fmt.Sprintf("%.2f%%", alert.Threshold),
+						Value:  alert.FormatValue(alert.Threshold),
 						Inline: true,
 					},
 					{
 						Name:   "Container ID",
-						Value:  alert.ContainerID[:12],
+						Value:  containerID,
 						Inline: false,
 					},
 				},
@@ -0,0 +1,28 @@
+package adapters
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"observability-system/internal/domain/entities"
+)
+func TestDiscordNotifierStylesResolvedAlerts(t *testing.T) {
+	var got discordMessage
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Error(err)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+	alert := entities.NewAlert("short", "web", entities.AlertTypeMemory, 70, 85)
+	alert.Status = entities.AlertStatusResolved
+	if err := NewDiscordNotifier(server.URL).Notify(context.Background(), alert); err != nil {
+		t.Fatal(err)
+	}
+	embed := got.Embeds[0]
+	if embed.Color != 3066993 || embed.Title != "web - MEMORY Resolved" || embed.Fields[4].Value != "short" || embed.Fields[2].Value != "70.00%" {
+		t.Fatalf("unexpected resolved message %+v", got)
+	}
+}
\ No newline at end of file
@@ -29,6 +29,13 @@ func (d *DockerCollectorAdapter) ListContainers(ctx context.Context) ([]string,
 	}
 	return ids, nil
 }
+func (d *DockerCollectorAdapter) CollectSamples(ctx context.Context, containerID string) ([]entities.Sample, error) {
+	metrics, err := d.CollectMetrics(ctx, containerID)
+	if err != nil || metrics == nil {
+		return nil, err
+	}
+	return metrics.Samples(), nil
+}
 func (d *DockerCollectorAdapter) CollectMetrics(ctx context.Context, containerID string) (*entities.ContainerMetrics, error) {
 	stats, err := d.client.ContainerStats(ctx, containerID, false)
 	if err != nil {
@@ -48,6 +55,10 @@ func (d *DockerCollectorAdapter) CollectMetrics(ctx context.Context, containerID
 	}
 	cpuPercent := calculateCPUPercent(&v)
 	memPercent := float64(v.MemoryStats.Usage) / float64(v.MemoryStats.Limit) * 100.0
+	var labels map[string]string
+	if containerInfo.Config != nil {
+		labels = containerInfo.Config.Labels
+	}
 	var networkRx, networkTx uint64
 	for _, net := range v.Networks {
 		networkRx += net.RxBytes
@@ -56,6 +67,7 @@ func (d *DockerCollectorAdapter) CollectMetrics(ctx context.Context, containerID
 	return &entities.ContainerMetrics{
 		ContainerID:   containerID,
 		ContainerName: containerInfo.Name,
+		Labels:        labels,
 		CPUPercent:    cpuPercent,
 		MemoryUsage:   v.MemoryStats.Usage,
 		MemoryLimit:   v.MemoryStats.Limit,
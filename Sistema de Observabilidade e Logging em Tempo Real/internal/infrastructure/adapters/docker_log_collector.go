@@ -0,0 +1,311 @@
+package adapters
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"sync"
+	"time"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+	"observability-system/internal/domain/entities"
+)
+const dockerLogSource = "docker"
+type DockerLogCollectorAdapter struct {
+	client       *client.Client
+	checkpoint   *logCheckpoint
+	pollInterval time.Duration
+	followers    map[string]struct{}
+	emitted      map[string]time.Time
+	unacked      map[string][]unackedLogLine
+	mu           sync.Mutex
+	wg           sync.WaitGroup
+}
+type unackedLogLine struct {
+	entry     *entities.LogEntry
+	timestamp time.Time
+}
+func NewDockerLogCollectorAdapter(checkpointPath string, pollInterval time.Duration) (*DockerLogCollectorAdapter, error) {
+	cli, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	if err != nil {
+		return nil, err
+	}
+	checkpoint, err := openLogCheckpoint(checkpointPath)
+	if err != nil {
+		cli.Close()
+		return nil, err
+	}
+	if pollInterval <= 0 {
+		pollInterval = 10 * time.Second
+	}
+	return &DockerLogCollectorAdapter{
+		client:       cli,
+		checkpoint:   checkpoint,
+		pollInterval: pollInterval,
+		followers:    make(map[string]struct{}),
+		emitted:      make(map[string]time.Time),
+		unacked:      make(map[string][]unackedLogLine),
+	}, nil
+}
+func (d *DockerLogCollectorAdapter) Run(ctx context.Context, entries chan<- *entities.LogEntry) error {
+	d.pruneCheckpoint(ctx)
+	ticker := time.NewTicker(d.pollInterval)
+	defer ticker.Stop()
+	for {
+		if err := d.discover(ctx, entries); err != nil && ctx.Err() == nil {
+			log.Printf("Failed to list containers for log collection: %v", err)
+		}
+		if err := d.checkpoint.save(); err != nil {
+			log.Printf("Failed to save docker log offsets: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			d.wg.Wait()
+			return d.checkpoint.save()
+		case <-ticker.C:
+		}
+	}
+}
+func (d *DockerLogCollectorAdapter) Acknowledge(entries []*entities.LogEntry) {
+	acked := make(map[*entities.LogEntry]bool, len(entries))
+	for _, entry := range entries {
+		if entry.Source == dockerLogSource {
+			acked[entry] = true
+		}
+	}
+	if len(acked) == 0 {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for id, lines := range d.unacked {
+		last := -1
+		for i, line := range lines {
+			if acked[line.entry] {
+				last = i
+			}
+		}
+		if last < 0 {
+			continue
+		}
+		d.checkpoint.set(id, logPosition{Timestamp: lines[last].timestamp})
+		if remaining := lines[last+1:]; len(remaining) > 0 {
+			d.unacked[id] = remaining
+		} else {
+			delete(d.unacked, id)
+		}
+	}
+}
+func (d *DockerLogCollectorAdapter) Close() error {
+	err := d.checkpoint.save()
+	if closeErr := d.client.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+func (d *DockerLogCollectorAdapter) discover(ctx context.Context, entries chan<- *entities.LogEntry) error {
+	containers, err := d.client.ContainerList(ctx, types.ContainerListOptions{})
+	if err != nil {
+		return err
+	}
+	for _, c := range containers {
+		d.mu.Lock()
+		_, following := d.followers[c.ID]
+		if !following {
+			d.followers[c.ID] = struct{}{}
+		}
+		d.mu.Unlock()
+		if following {
+			continue
+		}
+		d.wg.Add(1)
+		go func(id string) {
+			defer d.wg.Done()
+			defer func() {
+				d.mu.Lock()
+				delete(d.followers, id)
+				d.mu.Unlock()
+			}()
+			if err := d.follow(ctx, id, entries); err != nil && ctx.Err() == nil {
+				log.Printf("Log stream for container %s ended: %v", id, err)
+			}
+		}(c.ID)
+	}
+	return nil
+}
+func (d *DockerLogCollectorAdapter) pruneCheckpoint(ctx context.Context) {
+	for _, id := range d.checkpoint.keys() {
+		if _, err := d.client.ContainerInspect(ctx, id); client.IsErrNotFound(err) {
+			d.checkpoint.delete(id)
+		}
+	}
+}
+func (d *DockerLogCollectorAdapter) follow(ctx context.Context, id string, entries chan<- *entities.LogEntry) error {
+	info, err := d.client.ContainerInspect(ctx, id)
+	if err != nil {
+		return err
+	}
+	var labels map[string]string
+	tty := false
+	if info.Config != nil {
+		labels = info.Config.Labels
+		tty = info.Config.Tty
+	}
+	name := strings.TrimPrefix(info.Name, "/")
+	position, _ := d.checkpoint.get(id)
+	d.mu.Lock()
+	if emitted := d.emitted[id]; emitted.After(position.Timestamp) {
+		position.Timestamp = emitted
+	}
+	d.mu.Unlock()
+	options := types.ContainerLogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+		Follow:     true,
+		Timestamps: true,
+	}
+	if !position.Timestamp.IsZero() {
+		options.Since = fmt.Sprintf("%d.%09d", position.Timestamp.Unix(), position.Timestamp.Nanosecond())
+	}
+	stream, err := d.client.ContainerLogs(ctx, id, options)
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+	reader := newDockerLogReader(stream, tty)
+	for {
+		streamType, line, err := reader.next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		timestamp, message := splitDockerTimestamp(line)
+		if !position.Timestamp.IsZero() && !timestamp.After(position.Timestamp) {
+			continue
+		}
+		entry := &entities.LogEntry{
+			Timestamp:     timestamp,
+			Source:        dockerLogSource,
+			Stream:        streamType,
+			ContainerID:   id,
+			ContainerName: name,
+			Labels:        labels,
+			Message:       message,
+		}
+		d.mu.Lock()
+		d.unacked[id] = append(d.unacked[id], unackedLogLine{entry: entry, timestamp: timestamp})
+		d.mu.Unlock()
+		select {
+		case entries <- entry:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		position.Timestamp = timestamp
+		d.mu.Lock()
+		d.emitted[id] = timestamp
+		d.mu.Unlock()
+	}
+}
+type dockerLogLine struct {
+	stream entities.LogStream
+	text   string
+}
+type dockerLogReader struct {
+	r       *bufio.Reader
+	tty     bool
+	partial map[entities.LogStream][]byte
+	lines   []dockerLogLine
+}
+func newDockerLogReader(r io.Reader, tty bool) *dockerLogReader {
+	return &dockerLogReader{
+		r:       bufio.NewReader(r),
+		tty:     tty,
+		partial: make(map[entities.LogStream][]byte),
+	}
+}
+func (r *dockerLogReader) next() (entities.LogStream, string, error) {
+	for len(r.lines) == 0 {
+		if err := r.fill(); err != nil {
+			if err == io.EOF && r.flushPartial() {
+				continue
+			}
+			return "", "", err
+		}
+	}
+	line := r.lines[0]
+	r.lines = r.lines[1:]
+	return line.stream, line.text, nil
+}
+func (r *dockerLogReader) fill() error {
+	if r.tty {
+		data, err := r.r.ReadBytes('\n')
+		if len(data) > 0 {
+			r.push(entities.LogStreamStdout, data)
+		}
+		return err
+	}
+	var header [8]byte
+	if _, err := io.ReadFull(r.r, header[:]); err != nil {
+		return err
+	}
+	payload := make([]byte, binary.BigEndian.Uint32(header[4:]))
+	if _, err := io.ReadFull(r.r, payload); err != nil {
+		if err == io.EOF {
+			return io.ErrUnexpectedEOF
+		}
+		return err
+	}
+	switch header[0] {
+	case 0:
+	case 1:
+		r.push(entities.LogStreamStdout, payload)
+	case 2:
+		r.push(entities.LogStreamStderr, payload)
+	case 3:
+		return fmt.Errorf("docker log stream error: %s", bytes.TrimSpace(payload))
+	default:
+		return fmt.Errorf("unexpected docker log stream type %d", header[0])
+	}
+	return nil
+}
+func (r *dockerLogReader) push(stream entities.LogStream, data []byte) {
+	buf := append(r.partial[stream], data...)
+	for {
+		i := bytes.IndexByte(buf, '\n')
+		if i < 0 {
+			break
+		}
+		r.lines = append(r.lines, dockerLogLine{stream: stream, text: strings.TrimSuffix(string(buf[:i]), "\r")})
+		buf = buf[i+1:]
+	}
+	if len(buf) >= maxLogLineBytes {
+		r.lines = append(r.lines, dockerLogLine{stream: stream, text: string(buf)})
+		buf = nil
+	}
+	r.partial[stream] = buf
+}
+func (r *dockerLogReader) flushPartial() bool {
+	flushed := false
+	for _, stream := range []entities.LogStream{entities.LogStreamStdout, entities.LogStreamStderr} {
+		if buf := r.partial[stream]; len(buf) > 0 {
+			r.lines = append(r.lines, dockerLogLine{stream: stream, text: strings.TrimSuffix(string(buf), "\r")})
+			r.partial[stream] = nil
+			flushed = true
+		}
+	}
+	return flushed
+}
+func splitDockerTimestamp(line string) (time.Time, string) {
+	if i := strings.IndexByte(line, ' '); i > 0 {
+		if timestamp, err := time.Parse(time.RFC3339Nano, line[:i]); err == nil {
+			return timestamp, line[i+1:]
+		}
+	}
+	return time.Now(), line
+}
\ No newline at end of file
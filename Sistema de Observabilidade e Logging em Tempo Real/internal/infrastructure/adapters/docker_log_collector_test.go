@@ -0,0 +1,221 @@
+package adapters
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"observability-system/internal/domain/entities"
+)
+func dockerFrame(stream byte, payload string) []byte {
+	header := make([]byte, 8)
+	header[0] = stream
+	binary.BigEndian.PutUint32(header[4:], uint32(len(payload)))
+	return append(header, payload...)
+}
+type fakeDockerAPI struct {
+	*httptest.Server
+	logs   []byte
+	sinces []string
+	mu     sync.Mutex
+}
+func newFakeDockerAPI(t *testing.T, logs []byte) *fakeDockerAPI {
+	t.Helper()
+	api := &fakeDockerAPI{logs: logs}
+	api.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/containers/json"):
+			json.NewEncoder(w).Encode([]map[string]interface{}{{"Id": "abc", "Names": []string{"/web"}, "State": "running"}})
+		case strings.HasSuffix(r.URL.Path, "/containers/abc/json"):
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"Id":     "abc",
+				"Name":   "/web",
+				"Config": map[string]interface{}{"Tty": false, "Labels": map[string]string{"app": "web"}},
+			})
+		case strings.HasSuffix(r.URL.Path, "/containers/abc/logs"):
+			api.mu.Lock()
+			api.sinces = append(api.sinces, r.URL.Query().Get("since"))
+			api.mu.Unlock()
+			w.Header().Set("Content-Type", "application/vnd.docker.multiplexed-stream")
+			w.Write(api.logs)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message":"no such container"}`))
+		}
+	}))
+	t.Setenv("DOCKER_HOST", "tcp://"+api.Listener.Addr().String())
+	t.Setenv("DOCKER_API_VERSION", "1.43")
+	return api
+}
+func (api *fakeDockerAPI) requestedSinces() []string {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	return append([]string(nil), api.sinces...)
+}
+func collectDockerLogs(t *testing.T, checkpointPath string, want int, ack bool) []*entities.LogEntry {
+	t.Helper()
+	collector, err := NewDockerLogCollectorAdapter(checkpointPath, 20*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer collector.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	entries := make(chan *entities.LogEntry, 16)
+	done := make(chan error, 1)
+	go func() {
+		done <- collector.Run(ctx, entries)
+	}()
+	var got []*entities.LogEntry
+	deadline := time.After(2 * time.Second)
+	for len(got) < want {
+		select {
+		case entry := <-entries:
+			got = append(got, entry)
+			if ack {
+				collector.Acknowledge([]*entities.LogEntry{entry})
+			}
+		case <-deadline:
+			t.Fatalf("expected %d entries, got %d", want, len(got))
+		}
+	}
+	time.Sleep(100 * time.Millisecond)
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	for {
+		select {
+		case entry := <-entries:
+			got = append(got, entry)
+		default:
+			return got
+		}
+	}
+}
+func TestDockerLogCollectorDemultiplexesAndResumesFromOffsets(t *testing.T) {
+	var logs bytes.Buffer
+	logs.Write(dockerFrame(1, "2024-01-01T00:00:01.000000001Z hello from stdout\n"))
+	logs.Write(dockerFrame(2, "2024-01-01T00:00:02.5Z something failed\r\n"))
+	logs.Write(dockerFrame(1, "2024-01-01T00:00:03Z split "))
+	logs.Write(dockerFrame(1, "across frames\n"))
+	api := newFakeDockerAPI(t, logs.Bytes())
+	defer api.Close()
+	checkpointPath := filepath.Join(t.TempDir(), "docker-offsets.json")
+	entries := collectDockerLogs(t, checkpointPath, 3, true)
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 entries without duplicates across re-follows, got %d", len(entries))
+	}
+	expected := []struct {
+		stream  entities.LogStream
+		message string
+	}{
+		{entities.LogStreamStdout, "hello from stdout"},
+		{entities.LogStreamStderr, "something failed"},
+		{entities.LogStreamStdout, "split across frames"},
+	}
+	for i, want := range expected {
+		got := entries[i]
+		if got.Stream != want.stream || got.Message != want.message {
+			t.Fatalf("entry %d: expected %s %q, got %s %q", i, want.stream, want.message, got.Stream, got.Message)
+		}
+		if got.ContainerID != "abc" || got.ContainerName != "web" || got.Source != "docker" || got.Labels["app"] != "web" {
+			t.Fatalf("entry %d missing container metadata: %+v", i, got)
+		}
+	}
+	if !entries[0].Timestamp.Equal(time.Date(2024, 1, 1, 0, 0, 1, 1, time.UTC)) {
+		t.Fatalf("unexpected timestamp %s", entries[0].Timestamp)
+	}
+	if sinces := api.requestedSinces(); sinces[0] != "" {
+		t.Fatalf("first follow should start from the beginning, got since=%q", sinces[0])
+	}
+	restarted := collectDockerLogs(t, checkpointPath, 0, true)
+	if len(restarted) != 0 {
+		t.Fatalf("expected no replayed entries after restart, got %d", len(restarted))
+	}
+	sinces := api.requestedSinces()
+	if last := sinces[len(sinces)-1]; last != "1704067203.000000000" {
+		t.Fatalf("expected restart to resume from the persisted offset, got since=%q", last)
+	}
+}
+func TestDockerLogCollectorReplaysUnacknowledgedEntries(t *testing.T) {
+	var logs bytes.Buffer
+	logs.Write(dockerFrame(1, "2024-01-01T00:00:01Z first\n"))
+	logs.Write(dockerFrame(1, "2024-01-01T00:00:02Z second\n"))
+	api := newFakeDockerAPI(t, logs.Bytes())
+	defer api.Close()
+	checkpointPath := filepath.Join(t.TempDir(), "docker-offsets.json")
+	if entries := collectDockerLogs(t, checkpointPath, 2, false); len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+	restartAt := len(api.requestedSinces())
+	if replayed := collectDockerLogs(t, checkpointPath, 2, false); len(replayed) != 2 || replayed[0].Message != "first" {
+		t.Fatalf("expected unacknowledged entries to be replayed after restart, got %d", len(replayed))
+	}
+	if since := api.requestedSinces()[restartAt]; since != "" {
+		t.Fatalf("expected restart without acknowledgements to start from the beginning, got since=%q", since)
+	}
+}
+func TestDockerLogCollectorAcknowledgeAdvancesInOrder(t *testing.T) {
+	checkpoint, err := openLogCheckpoint(filepath.Join(t.TempDir(), "docker-offsets.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	collector := &DockerLogCollectorAdapter{checkpoint: checkpoint, unacked: make(map[string][]unackedLogLine)}
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	var lines []*entities.LogEntry
+	for i := 0; i < 3; i++ {
+		entry := &entities.LogEntry{Source: dockerLogSource, ContainerID: "abc"}
+		lines = append(lines, entry)
+		collector.unacked["abc"] = append(collector.unacked["abc"], unackedLogLine{entry: entry, timestamp: base.Add(time.Duration(i) * time.Second)})
+	}
+	collector.Acknowledge([]*entities.LogEntry{{Source: "file"}})
+	if _, ok := checkpoint.get("abc"); ok {
+		t.Fatal("acknowledging foreign entries should not move the checkpoint")
+	}
+	collector.Acknowledge(lines[1:2])
+	if position, _ := checkpoint.get("abc"); !position.Timestamp.Equal(base.Add(time.Second)) {
+		t.Fatalf("expected checkpoint at the acknowledged entry, got %s", position.Timestamp)
+	}
+	if len(collector.unacked["abc"]) != 1 {
+		t.Fatalf("expected entries up to the acknowledged one to be released, got %d pending", len(collector.unacked["abc"]))
+	}
+	collector.Acknowledge(lines[2:])
+	if position, _ := checkpoint.get("abc"); !position.Timestamp.Equal(base.Add(2 * time.Second)) {
+		t.Fatalf("expected checkpoint at the last entry, got %s", position.Timestamp)
+	}
+	if _, ok := collector.unacked["abc"]; ok {
+		t.Fatal("expected pending queue to be cleared")
+	}
+}
+func TestDockerLogReaderHandlesTTYAndStreamErrors(t *testing.T) {
+	reader := newDockerLogReader(strings.NewReader("first\r\nsecond\nunterminated"), true)
+	var lines []string
+	for {
+		stream, line, err := reader.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if stream != entities.LogStreamStdout {
+			t.Fatalf("tty output should be reported as stdout, got %s", stream)
+		}
+		lines = append(lines, line)
+	}
+	if strings.Join(lines, "|") != "first|second|unterminated" {
+		t.Fatalf("unexpected tty lines %q", lines)
+	}
+	reader = newDockerLogReader(bytes.NewReader(dockerFrame(3, "container not running\n")), false)
+	if _, _, err := reader.next(); err == nil || !strings.Contains(err.Error(), "container not running") {
+		t.Fatalf("expected stream error, got %v", err)
+	}
+}
\ No newline at end of file
@@ -2,7 +2,9 @@ package adapters
 import (
 	"context"
 	"fmt"
+	"html"
 	"net/smtp"
+	"strings"
 	"time"
 	"observability-system/internal/domain/entities"
 )
@@ -22,25 +24,36 @@ func NewEmailNotifier(smtpHost, smtpPort, from, password string, to []string) *E
 		to:       to,
 	}
 }
+var headerSanitizer = strings.NewReplacer("\r", " ", "\n", " ")
 func (n *EmailNotifier) Notify(ctx context.Context, alert *entities.Alert) error {
+	auth := smtp.PlainAuth("", n.from, n.password, n.smtpHost)
+	addr := fmt.Sprintf("%s:%s", n.smtpHost, n.smtpPort)
+	return smtp.SendMail(addr, auth, n.from, n.to, n.message(alert))
+}
+func (n *EmailNotifier) message(alert *entities.Alert) []byte {
 	subject := fmt.Sprintf("🚨 Alert: %s - %s", alert.ContainerName, alert.Type)
+	heading, background, border := "🚨 Container Alert", "#fee", "#f44"
+	if alert.Resolved() {
+		subject = fmt.Sprintf("✅ Resolved: %s - %s", alert.ContainerName, alert.Type)
+		heading, background, border = "✅ Container Alert Resolved", "#efe", "#4a4"
+	}
 	body := fmt.Sprintf(`
 <!DOCTYPE html>
 <html>
 <head>
     <style>
         body { font-family: Arial, sans-serif; }
-        .alert { background: #fee; border-left: 4px solid #f44; padding: 20px; }
+        .alert { background: %s; border-left: 4px solid %s; padding: 20px; }
         .info { color: #666; margin-top: 10px; }
     </style>
 </head>
 <body>
     <div class="alert">
-        <h2>🚨 Container Alert</h2>
+        <h2>%s</h2>
         <p><strong>Container:</strong> %s</p>
         <p><strong>Type:</strong> %s</p>
         <p><strong>Message:</strong> %s</p>
-        <p><strong>Value:</strong> %.2f%% (Threshold: %.2f%%)</p>
+        <p><strong>Value:</strong> %s (Threshold: %s)</p>
         <div class="info">
             <p>Time: %s</p>
             <p>Container ID: %s</p>
@@ -48,16 +61,14 @@ func (n *EmailNotifier) Notify(ctx context.Context, alert *entities.Alert) error
     </div>
 </body>
 </html>
-	`, alert.ContainerName, alert.Type, alert.Message, alert.Value, alert.Threshold,
-		alert.Timestamp.Format(time.RFC3339), alert.ContainerID)
-	msg := []byte(fmt.Sprintf("From: %s\r\n"+
+	`, background, border, heading, html.EscapeString(alert.ContainerName), html.EscapeString(string(alert.Type)), html.EscapeString(alert.Message),
+		html.EscapeString(alert.FormatValue(alert.Value)), html.EscapeString(alert.FormatValue(alert.Threshold)),
+		alert.Timestamp.Format(time.RFC3339), html.EscapeString(alert.ContainerID))
+	return []byte(fmt.Sprintf("From: %s\r\n"+
 		"To: %s\r\n"+
 		"Subject: %s\r\n"+
 		"MIME-Version: 1.0\r\n"+
 		"Content-Type: text/html; charset=UTF-8\r\n"+
 		"\r\n"+
-		"%s\r\n", n.from, n.to[0], subject, body))
-	auth := smtp.PlainAuth("", n.from, n.password, n.smtpHost)
-	addr := fmt.Sprintf("%s:%s", n.smtpHost, n.smtpPort)
-	return smtp.SendMail(addr, auth, n.from, n.to, msg)
+		"%s\r\n", n.from, n.to[0], headerSanitizer.Replace(subject), body))
 }
\ No newline at end of file
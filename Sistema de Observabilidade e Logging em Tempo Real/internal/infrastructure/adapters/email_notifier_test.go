@@ -0,0 +1,26 @@
+package adapters
+import (
+	"strings"
+	"testing"
+	"observability-system/internal/domain/entities"
+)
+func TestEmailNotifierEscapesAlertContent(t *testing.T) {
+	notifier := NewEmailNotifier("smtp.example.com", "587", "alerts@example.com", "secret", []string{"ops@example.com"})
+	alert := entities.NewAlert("abc<b>", "web\r\nBcc: victim@example.com", entities.LogAlertType("panic"), 3, 0)
+	alert.Message = `log rule panic matched: <img src=x onerror="alert(1)">`
+	message := string(notifier.message(alert))
+	headers, body, _ := strings.Cut(message, "\r\n\r\n")
+	if strings.Contains(headers, "\r\nBcc:") || !strings.Contains(headers, "Subject: 🚨 Alert: web  Bcc: victim@example.com - LOG_PANIC\r\n") {
+		t.Fatalf("expected the subject to stay on one header line, got %q", headers)
+	}
+	for _, unsafe := range []string{"<img", "abc<b>"} {
+		if strings.Contains(body, unsafe) {
+			t.Fatalf("body contains unescaped %q:\n%s", unsafe, body)
+		}
+	}
+	for _, escaped := range []string{"&lt;img src=x onerror=&#34;alert(1)&#34;&gt;", "abc&lt;b&gt;", "3 lines (Threshold: 0 lines)"} {
+		if !strings.Contains(body, escaped) {
+			t.Fatalf("body missing %q:\n%s", escaped, body)
+		}
+	}
+}
\ No newline at end of file
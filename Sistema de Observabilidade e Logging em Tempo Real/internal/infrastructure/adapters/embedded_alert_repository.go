@@ -0,0 +1,198 @@
+package adapters
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+	"observability-system/internal/domain/entities"
+)
+const (
+	alertFileLayout = "2006-01-02"
+	alertFileSuffix = ".jsonl"
+	cooldownsFile   = "cooldowns.json"
+	alertStatesFile = "alert_states.json"
+)
+type EmbeddedAlertRepository struct {
+	*EmbeddedSilenceRepository
+	dir       string
+	cooldowns map[string]time.Time
+	states    map[string]*entities.AlertInstance
+	mu        sync.Mutex
+}
+func NewEmbeddedAlertRepository(dir string) (*EmbeddedAlertRepository, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	silences, err := NewEmbeddedSilenceRepository(dir)
+	if err != nil {
+		return nil, err
+	}
+	r := &EmbeddedAlertRepository{
+		EmbeddedSilenceRepository: silences,
+		dir:                       dir,
+		cooldowns:                 make(map[string]time.Time),
+		states:                    make(map[string]*entities.AlertInstance),
+	}
+	if err := readStateFile(filepath.Join(dir, cooldownsFile), &r.cooldowns); err != nil {
+		return nil, err
+	}
+	if err := readStateFile(filepath.Join(dir, alertStatesFile), &r.states); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+func readStateFile(path string, out interface{}) error {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		log.Printf("Ignoring unreadable state in %s: %v", filepath.Base(path), err)
+	}
+	return nil
+}
+func (r *EmbeddedAlertRepository) Save(ctx context.Context, alert *entities.Alert) error {
+	record, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	day := alert.Timestamp.UTC().Format(alertFileLayout)
+	file, err := os.OpenFile(filepath.Join(r.dir, day+alertFileSuffix), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(append(record, '\n')); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+func (r *EmbeddedAlertRepository) IsInCooldown(ctx context.Context, containerID string, alertType entities.AlertType) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	until, ok := r.cooldowns[cooldownKey(containerID, alertType)]
+	return ok && time.Now().Before(until), nil
+}
+func (r *EmbeddedAlertRepository) SetCooldown(ctx context.Context, containerID string, alertType entities.AlertType, duration time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	for key, until := range r.cooldowns {
+		if now.After(until) {
+			delete(r.cooldowns, key)
+		}
+	}
+	r.cooldowns[cooldownKey(containerID, alertType)] = now.Add(duration)
+	return r.saveCooldowns()
+}
+func (r *EmbeddedAlertRepository) ListAlertStates(ctx context.Context) ([]*entities.AlertInstance, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	instances := make([]*entities.AlertInstance, 0, len(r.states))
+	for _, instance := range r.states {
+		copied := *instance
+		instances = append(instances, &copied)
+	}
+	return instances, nil
+}
+func (r *EmbeddedAlertRepository) SaveAlertStates(ctx context.Context, saved []*entities.AlertInstance, deleted []string) error {
+	if len(saved) == 0 && len(deleted) == 0 {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, instance := range saved {
+		copied := *instance
+		r.states[instance.Fingerprint] = &copied
+	}
+	for _, fingerprint := range deleted {
+		delete(r.states, fingerprint)
+	}
+	return writeStateFile(filepath.Join(r.dir, alertStatesFile), r.states)
+}
+func (r *EmbeddedAlertRepository) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.saveCooldowns()
+}
+func (r *EmbeddedAlertRepository) saveCooldowns() error {
+	return writeStateFile(filepath.Join(r.dir, cooldownsFile), r.cooldowns)
+}
+func writeStateFile(path string, state interface{}) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+func (r *EmbeddedAlertRepository) DeleteAlertsBefore(ctx context.Context, before time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	cutoff := before.UTC().Format(alertFileLayout)
+	entries, err := os.ReadDir(r.dir)
+	if err != nil {
+		return 0, err
+	}
+	var deleted int64
+	for _, entry := range entries {
+		day, ok := strings.CutSuffix(entry.Name(), alertFileSuffix)
+		if !ok || day > cutoff {
+			continue
+		}
+		path := filepath.Join(r.dir, entry.Name())
+		removed, err := r.expireAlertFile(path, before, day < cutoff)
+		deleted += removed
+		if err != nil {
+			return deleted, err
+		}
+	}
+	return deleted, nil
+}
+func (r *EmbeddedAlertRepository) expireAlertFile(path string, before time.Time, wholeFile bool) (int64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	var kept []byte
+	var removed int64
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+		var alert entities.Alert
+		if !wholeFile && json.Unmarshal(line, &alert) == nil && !alert.Timestamp.Before(before) {
+			kept = append(append(kept, line...), '\n')
+			continue
+		}
+		removed++
+	}
+	if len(kept) == 0 {
+		return removed, os.Remove(path)
+	}
+	if removed == 0 {
+		return 0, nil
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, kept, 0o644); err != nil {
+		return 0, err
+	}
+	return removed, os.Rename(tmp, path)
+}
+func cooldownKey(containerID string, alertType entities.AlertType) string {
+	return fmt.Sprintf("cooldown:%s:%s", containerID, alertType)
+}
\ No newline at end of file
@@ -0,0 +1,149 @@
+package adapters
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"observability-system/internal/domain/entities"
+	"observability-system/internal/domain/ports"
+)
+func TestEmbeddedAlertStateSurvivesReopen(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	repo, err := NewEmbeddedAlertRepository(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	labels := map[string]string{"container_id": "c1"}
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	pending := &entities.AlertInstance{Fingerprint: entities.AlertFingerprint("CPU", labels), Rule: "CPU", ContainerID: "c1", Labels: labels}
+	if pending.Observe(start, 95, 2*time.Minute, 15*time.Second) {
+		t.Fatal("expected first breach to stay pending")
+	}
+	other := &entities.AlertInstance{Fingerprint: entities.AlertFingerprint("MEMORY", labels), Rule: "MEMORY"}
+	if err := repo.SaveAlertStates(ctx, []*entities.AlertInstance{pending, other}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.SaveAlertStates(ctx, nil, []string{other.Fingerprint}); err != nil {
+		t.Fatal(err)
+	}
+	repo.Close()
+	reopened, err := NewEmbeddedAlertRepository(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	instances, err := reopened.ListAlertStates(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(instances) != 1 || instances[0].State != entities.AlertStatePending || !instances[0].ActiveAt.Equal(start) {
+		t.Fatalf("unexpected states %+v", instances)
+	}
+	for at := start.Add(10 * time.Second); at.Before(start.Add(2 * time.Minute)); at = at.Add(10 * time.Second) {
+		if instances[0].Observe(at, 96, 2*time.Minute, 15*time.Second) {
+			t.Fatal("expected restored pending state to wait for the hold")
+		}
+	}
+	if !instances[0].Observe(start.Add(2*time.Minute), 96, 2*time.Minute, 15*time.Second) {
+		t.Fatal("expected restored pending state to fire once the hold elapsed")
+	}
+}
+type recordingNotifier struct {
+	alerts []*entities.Alert
+}
+func (n *recordingNotifier) Notify(ctx context.Context, alert *entities.Alert) error {
+	n.alerts = append(n.alerts, alert)
+	return nil
+}
+type silencerFunc func(alert *entities.Alert) bool
+func (f silencerFunc) IsSilenced(ctx context.Context, alert *entities.Alert) (bool, error) {
+	return f(alert), nil
+}
+func TestEmbeddedSilencesAreSharedAndGateNotifications(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	repo, err := NewEmbeddedAlertRepository(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Close()
+	writer, err := NewEmbeddedSilenceRepository(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	silence := &entities.Silence{ID: "s1", Matchers: map[string]string{"container_name": "api-*"}, StartsAt: now.Add(-time.Minute), EndsAt: now.Add(time.Hour), CreatedBy: "ops"}
+	stale := &entities.Silence{ID: "s0", Matchers: map[string]string{"alertname": "CPU"}, StartsAt: now.Add(-72 * time.Hour), EndsAt: now.Add(-48 * time.Hour), CreatedBy: "ops"}
+	for _, s := range []*entities.Silence{stale, silence} {
+		if err := writer.SaveSilence(ctx, s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	silences, err := repo.ListSilences(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(silences) != 1 || silences[0].ID != "s1" {
+		t.Fatalf("expected the silence saved by another process without stale entries, got %+v", silences)
+	}
+	recorder := &recordingNotifier{}
+	notifier := NewSilencingNotifier(recorder, silencerFunc(func(alert *entities.Alert) bool {
+		labels := entities.AlertSilenceLabels(alert)
+		return silences[0].Active(time.Now()) && silences[0].Matches(labels)
+	}))
+	if err := notifier.Notify(ctx, entities.NewAlert("c-api-1", "api-1", entities.AlertTypeCPU, 95, 90)); !errors.Is(err, ports.ErrSilenced) {
+		t.Fatalf("expected ErrSilenced for the silenced alert, got %v", err)
+	}
+	if err := notifier.Notify(ctx, entities.NewAlert("c-db", "db", entities.AlertTypeCPU, 95, 90)); err != nil {
+		t.Fatal(err)
+	}
+	if len(recorder.alerts) != 1 || recorder.alerts[0].ContainerName != "db" {
+		t.Fatalf("expected only the unsilenced alert to be sent, got %+v", recorder.alerts)
+	}
+}
+func TestEmbeddedAlertRepositoryDeleteAlertsBefore(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	repo, err := NewEmbeddedAlertRepository(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Close()
+	day := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	for _, at := range []time.Time{day.Add(-20 * time.Hour), day.Add(-2 * time.Hour), day.Add(3 * time.Hour), day.Add(9 * time.Hour), day.Add(30 * time.Hour)} {
+		if err := repo.Save(ctx, &entities.Alert{ContainerID: "c1", Type: entities.AlertTypeCPU, Timestamp: at}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	deleted, err := repo.DeleteAlertsBefore(ctx, day.Add(6*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deleted != 3 {
+		t.Fatalf("expected 3 deleted alerts, got %d", deleted)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var files []string
+	for _, entry := range entries {
+		if strings.HasSuffix(entry.Name(), alertFileSuffix) {
+			files = append(files, entry.Name())
+		}
+	}
+	if fmt.Sprint(files) != "[2024-01-02.jsonl 2024-01-03.jsonl]" {
+		t.Fatalf("expected the expired day file to be removed, got %v", files)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "2024-01-02.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Count(string(data), "\n"); lines != 1 {
+		t.Fatalf("expected one alert kept from the partially expired day, got %d", lines)
+	}
+}
\ No newline at end of file
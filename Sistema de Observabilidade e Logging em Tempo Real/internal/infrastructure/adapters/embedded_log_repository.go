@@ -0,0 +1,88 @@
+package adapters
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"observability-system/internal/domain/entities"
+	"observability-system/internal/infrastructure/logstore"
+)
+type EmbeddedLogRepository struct {
+	db *logstore.DB
+}
+func NewEmbeddedLogRepository(db *logstore.DB) *EmbeddedLogRepository {
+	return &EmbeddedLogRepository{db: db}
+}
+func (r *EmbeddedLogRepository) WriteLogs(ctx context.Context, entries []*entities.LogEntry) error {
+	records := make([]logstore.Record, len(entries))
+	for i, entry := range entries {
+		records[i] = logstore.Record{
+			Timestamp: entry.Timestamp,
+			Labels:    entry.StreamLabels(),
+			Fields:    entry.Fields,
+			Message:   entry.Message,
+		}
+	}
+	return r.db.Append(records...)
+}
+func (r *EmbeddedLogRepository) QueryLogs(ctx context.Context, query entities.LogQuery) ([]*entities.LogEntry, error) {
+	matchers := map[string][]string{
+		"container_id":   query.ContainerIDs,
+		"container_name": query.ContainerNames,
+		"level":          query.Levels,
+	}
+	for name, value := range query.Labels {
+		matchers[name] = []string{value}
+	}
+	search := logstore.Query{
+		Matchers: matchers,
+		Start:    query.Start,
+		End:      query.End,
+		Contains: query.Contains,
+		Limit:    query.Limit,
+	}
+	if query.Regex != "" {
+		re, err := regexp.Compile(query.Regex)
+		if err != nil {
+			return nil, err
+		}
+		search.Regexp = re
+	}
+	records, err := r.db.Search(search)
+	if err != nil {
+		return nil, err
+	}
+	return embeddedLogEntries(records), nil
+}
+func (r *EmbeddedLogRepository) LatestLogCursor(ctx context.Context) (string, error) {
+	head, err := r.db.Head()
+	if err != nil {
+		return "", err
+	}
+	return encodeLogPosition(head), nil
+}
+func (r *EmbeddedLogRepository) ReadLogsSince(ctx context.Context, cursor string, limit int) ([]*entities.LogEntry, string, error) {
+	var after logstore.Position
+	if cursor != "" {
+		if _, err := fmt.Sscanf(cursor, "%d:%d", &after.Segment, &after.Record); err != nil {
+			return nil, cursor, fmt.Errorf("invalid log cursor %q", cursor)
+		}
+	}
+	records, position, err := r.db.Tail(after, limit)
+	if err != nil {
+		return nil, cursor, err
+	}
+	return embeddedLogEntries(records), encodeLogPosition(position), nil
+}
+func embeddedLogEntries(records []logstore.Record) []*entities.LogEntry {
+	entries := make([]*entities.LogEntry, len(records))
+	for i, record := range records {
+		entries[i] = entities.LogEntryFromLabels(record.Timestamp, record.Labels, record.Message, record.Fields)
+	}
+	return entries
+}
+func encodeLogPosition(position logstore.Position) string {
+	return fmt.Sprintf("%d:%d", position.Segment, position.Record)
+}
+func (r *EmbeddedLogRepository) Close() error {
+	return r.db.Close()
+}
\ No newline at end of file
@@ -0,0 +1,94 @@
+package adapters
+import (
+	"context"
+	"strings"
+	"time"
+	"observability-system/internal/domain/entities"
+	"observability-system/internal/infrastructure/tsdb"
+)
+const (
+	embeddedTypeLabel = "__type__"
+	embeddedUnitLabel = "__unit__"
+)
+type EmbeddedMetricsRepository struct {
+	db *tsdb.DB
+}
+func NewEmbeddedMetricsRepository(db *tsdb.DB) *EmbeddedMetricsRepository {
+	return &EmbeddedMetricsRepository{db: db}
+}
+func (r *EmbeddedMetricsRepository) Save(ctx context.Context, samples []entities.Sample) error {
+	for _, sample := range samples {
+		for _, scalar := range sample.Expand() {
+			labels := tsdb.Labels(withLabels(map[string]string{
+				entities.MetricNameLabel: scalar.Name,
+				embeddedTypeLabel:        string(scalar.Type),
+				embeddedUnitLabel:        scalar.Unit,
+			}, scalar.Labels))
+			if err := r.db.Append(labels, scalar.Timestamp, scalar.Value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+func (r *EmbeddedMetricsRepository) FindSeries(ctx context.Context, selector map[string]string, duration time.Duration) ([]*entities.Series, error) {
+	return r.FindSeriesAggregated(ctx, selector, duration, 0)
+}
+func (r *EmbeddedMetricsRepository) FindSeriesAggregated(ctx context.Context, selector map[string]string, duration, window time.Duration) ([]*entities.Series, error) {
+	end := time.Now()
+	series, err := r.db.Select(tsdb.Labels(selector), end.Add(-duration), end)
+	if err != nil {
+		return nil, err
+	}
+	pivot := newSeriesPivot()
+	for _, s := range series {
+		points := s.Points
+		if window > 0 {
+			points = windowMean(points, window.Milliseconds())
+		}
+		labels := embeddedUserLabels(s.Labels)
+		for _, p := range points {
+			pivot.add(s.Labels[entities.MetricNameLabel], labels, s.Labels[embeddedTypeLabel], s.Labels[embeddedUnitLabel], time.UnixMilli(p.Timestamp).UTC(), p.Value)
+		}
+	}
+	return pivot.result(), nil
+}
+func (r *EmbeddedMetricsRepository) DeleteMetrics(ctx context.Context, tier string, selector map[string]string, before time.Time) (int64, error) {
+	if tier != entities.RetentionTierRaw {
+		return 0, nil
+	}
+	return r.db.Delete(tsdb.Labels(selector), before)
+}
+func (r *EmbeddedMetricsRepository) Close() error {
+	return r.db.Close()
+}
+func embeddedUserLabels(labels tsdb.Labels) map[string]string {
+	user := make(map[string]string, len(labels))
+	for name, value := range labels {
+		if strings.HasPrefix(name, "__") {
+			continue
+		}
+		user[name] = value
+	}
+	return user
+}
+func windowMean(points []tsdb.Point, window int64) []tsdb.Point {
+	var result []tsdb.Point
+	var sum float64
+	var count int
+	var stop int64
+	for _, p := range points {
+		bucketStop := p.Timestamp - p.Timestamp%window + window
+		if count > 0 && bucketStop != stop {
+			result = append(result, tsdb.Point{Timestamp: stop, Value: sum / float64(count)})
+			sum, count = 0, 0
+		}
+		stop = bucketStop
+		sum += p.Value
+		count++
+	}
+	if count > 0 {
+		result = append(result, tsdb.Point{Timestamp: stop, Value: sum / float64(count)})
+	}
+	return result
+}
\ No newline at end of file
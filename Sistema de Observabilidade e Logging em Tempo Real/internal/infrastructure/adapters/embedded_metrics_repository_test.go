@@ -0,0 +1,91 @@
+package adapters
+import (
+	"context"
+	"testing"
+	"time"
+	"observability-system/internal/domain/entities"
+	"observability-system/internal/infrastructure/tsdb"
+)
+func openEmbeddedDB(t *testing.T) *tsdb.DB {
+	t.Helper()
+	db, err := tsdb.Open(tsdb.Options{Dir: t.TempDir(), BlockDuration: time.Hour, FlushInterval: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+func TestEmbeddedMetricsRepositoryDeleteMetricsAppliesSelector(t *testing.T) {
+	ctx := context.Background()
+	db := openEmbeddedDB(t)
+	repo := NewEmbeddedMetricsRepository(db)
+	defer repo.Close()
+	now := time.Now()
+	var samples []entities.Sample
+	for _, container := range []string{"noisy", "quiet"} {
+		for _, age := range []time.Duration{3 * time.Hour, 2 * time.Hour, time.Minute} {
+			samples = append(samples, entities.Sample{Name: "cpu", Labels: map[string]string{"container_name": container}, Value: 1, Timestamp: now.Add(-age)})
+		}
+	}
+	if err := repo.Save(ctx, samples); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if deleted, err := repo.DeleteMetrics(ctx, "1m", nil, now); err != nil || deleted != 0 {
+		t.Fatalf("expected rollup tiers to be ignored by the raw store, got %d %v", deleted, err)
+	}
+	deleted, err := repo.DeleteMetrics(ctx, entities.RetentionTierRaw, map[string]string{"container_name": "noisy"}, now.Add(-time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deleted != 2 {
+		t.Fatalf("expected 2 deleted points, got %d", deleted)
+	}
+	series, err := repo.FindSeries(ctx, nil, 4*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	counts := make(map[string]int)
+	for _, s := range series {
+		counts[s.Labels["container_name"]] += len(s.Points)
+	}
+	if counts["noisy"] != 1 || counts["quiet"] != 3 {
+		t.Fatalf("unexpected remaining points %v", counts)
+	}
+}
+func TestEmbeddedRollupRepositoryDeleteMetricsOnlyTouchesTier(t *testing.T) {
+	ctx := context.Background()
+	db := openEmbeddedDB(t)
+	repo := NewEmbeddedRollupRepository(db)
+	defer repo.Close()
+	now := time.Now().Truncate(time.Minute)
+	labels := map[string]string{"container_name": "web"}
+	var rollups []*entities.MetricsRollup
+	for _, tier := range []string{"1m", "1h"} {
+		for _, age := range []time.Duration{3 * time.Hour, 10 * time.Minute} {
+			rollups = append(rollups, &entities.MetricsRollup{Name: "cpu", Labels: labels, Tier: tier, Timestamp: now.Add(-age), Mean: 1, Count: 1})
+		}
+	}
+	if err := repo.SaveRollups(ctx, rollups); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if deleted, err := repo.DeleteMetrics(ctx, entities.RetentionTierRaw, nil, now); err != nil || deleted != 0 {
+		t.Fatalf("expected the raw tier to be ignored by the rollup store, got %d %v", deleted, err)
+	}
+	if _, err := repo.DeleteMetrics(ctx, "1m", labels, now.Add(-time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	for tier, want := range map[string]int{"1m": 1, "1h": 2} {
+		found, err := repo.FindRollups(ctx, tier, nil, 4*time.Hour)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(found) != want {
+			t.Fatalf("expected %d %s rollups, got %d", want, tier, len(found))
+		}
+	}
+}
\ No newline at end of file
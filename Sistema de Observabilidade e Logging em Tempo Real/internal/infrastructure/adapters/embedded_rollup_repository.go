@@ -0,0 +1,93 @@
+package adapters
+import (
+	"context"
+	"time"
+	"observability-system/internal/domain/entities"
+	"observability-system/internal/infrastructure/tsdb"
+)
+const (
+	embeddedTierLabel = "__tier__"
+	embeddedStatLabel = "__stat__"
+)
+type EmbeddedRollupRepository struct {
+	db *tsdb.DB
+}
+func NewEmbeddedRollupRepository(db *tsdb.DB) *EmbeddedRollupRepository {
+	return &EmbeddedRollupRepository{db: db}
+}
+func (r *EmbeddedRollupRepository) SaveRollups(ctx context.Context, rollups []*entities.MetricsRollup) error {
+	for _, rollup := range rollups {
+		stats := map[string]float64{
+			"min":   rollup.Min,
+			"max":   rollup.Max,
+			"mean":  rollup.Mean,
+			"p95":   rollup.P95,
+			"last":  rollup.Last,
+			"count": float64(rollup.Count),
+		}
+		for stat, value := range stats {
+			labels := tsdb.Labels(withLabels(map[string]string{
+				entities.MetricNameLabel: rollup.Name,
+				embeddedTypeLabel:        string(rollup.Type),
+				embeddedUnitLabel:        rollup.Unit,
+				embeddedTierLabel:        rollup.Tier,
+				embeddedStatLabel:        stat,
+			}, rollup.Labels))
+			if err := r.db.Append(labels, rollup.Timestamp, value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+func (r *EmbeddedRollupRepository) FindRollups(ctx context.Context, tier string, selector map[string]string, duration time.Duration) ([]*entities.MetricsRollup, error) {
+	matchers := tsdb.Labels{embeddedTierLabel: tier}
+	for name, value := range selector {
+		matchers[name] = value
+	}
+	end := time.Now()
+	series, err := r.db.Select(matchers, end.Add(-duration), end)
+	if err != nil {
+		return nil, err
+	}
+	pivot := newRollupPivot()
+	for _, s := range series {
+		labels := embeddedUserLabels(s.Labels)
+		for _, p := range s.Points {
+			pivot.add(s.Labels[entities.MetricNameLabel], labels, s.Labels[embeddedTypeLabel], s.Labels[embeddedUnitLabel], tier, s.Labels[embeddedStatLabel], time.UnixMilli(p.Timestamp).UTC(), p.Value)
+		}
+	}
+	return pivot.rollups(), nil
+}
+func (r *EmbeddedRollupRepository) LastRollupTime(ctx context.Context, tier string, lookback time.Duration) (time.Time, error) {
+	end := time.Now()
+	series, err := r.db.Select(tsdb.Labels{
+		embeddedTierLabel: tier,
+		embeddedStatLabel: "count",
+	}, end.Add(-lookback), end)
+	if err != nil {
+		return time.Time{}, err
+	}
+	var last time.Time
+	for _, s := range series {
+		if n := len(s.Points); n > 0 {
+			if t := time.UnixMilli(s.Points[n-1].Timestamp).UTC(); t.After(last) {
+				last = t
+			}
+		}
+	}
+	return last, nil
+}
+func (r *EmbeddedRollupRepository) DeleteMetrics(ctx context.Context, tier string, selector map[string]string, before time.Time) (int64, error) {
+	if tier == entities.RetentionTierRaw {
+		return 0, nil
+	}
+	matchers := tsdb.Labels{embeddedTierLabel: tier}
+	for name, value := range selector {
+		matchers[name] = value
+	}
+	return r.db.Delete(matchers, before)
+}
+func (r *EmbeddedRollupRepository) Close() error {
+	return r.db.Close()
+}
\ No newline at end of file
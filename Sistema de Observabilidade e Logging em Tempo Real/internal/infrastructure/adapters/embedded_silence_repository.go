@@ -0,0 +1,55 @@
+package adapters
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+	"observability-system/internal/domain/entities"
+)
+const (
+	silencesFile     = "silences.json"
+	silenceRetention = 24 * time.Hour
+)
+type EmbeddedSilenceRepository struct {
+	path string
+	mu   sync.Mutex
+}
+func NewEmbeddedSilenceRepository(dir string) (*EmbeddedSilenceRepository, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &EmbeddedSilenceRepository{path: filepath.Join(dir, silencesFile)}, nil
+}
+func (r *EmbeddedSilenceRepository) SaveSilence(ctx context.Context, silence *entities.Silence) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	silences, err := r.load()
+	if err != nil {
+		return err
+	}
+	cutoff := time.Now().Add(-silenceRetention)
+	kept := silences[:0]
+	for _, existing := range silences {
+		if existing.ID != silence.ID && existing.EndsAt.After(cutoff) {
+			kept = append(kept, existing)
+		}
+	}
+	copied := *silence
+	return writeStateFile(r.path, append(kept, &copied))
+}
+func (r *EmbeddedSilenceRepository) ListSilences(ctx context.Context) ([]*entities.Silence, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.load()
+}
+func (r *EmbeddedSilenceRepository) Close() error {
+	return nil
+}
+func (r *EmbeddedSilenceRepository) load() ([]*entities.Silence, error) {
+	var silences []*entities.Silence
+	if err := readStateFile(r.path, &silences); err != nil {
+		return nil, err
+	}
+	return silences, nil
+}
\ No newline at end of file
@@ -0,0 +1,13 @@
+//go:build !windows
+
+package adapters
+import (
+	"os"
+	"syscall"
+)
+func fileInode(path string, info os.FileInfo) uint64 {
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+		return uint64(stat.Ino)
+	}
+	return pathInode(path)
+}
\ No newline at end of file
@@ -0,0 +1,5 @@
+package adapters
+import "os"
+func fileInode(path string, info os.FileInfo) uint64 {
+	return pathInode(path)
+}
\ No newline at end of file
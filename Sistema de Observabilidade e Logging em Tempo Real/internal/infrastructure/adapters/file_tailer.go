@@ -0,0 +1,351 @@
+package adapters
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"observability-system/internal/domain/entities"
+)
+const fileLogSource = "file"
+const logFingerprintBytes = 1024
+type tailedFile struct {
+	path            string
+	inode           uint64
+	file            *os.File
+	reader          *bufio.Reader
+	offset          int64
+	acked           int64
+	fingerprint     uint64
+	fingerprintSize int64
+	partial         []byte
+}
+type unackedFileLine struct {
+	entry  *entities.LogEntry
+	offset int64
+}
+type FileTailerAdapter struct {
+	patterns     []string
+	checkpoint   *logCheckpoint
+	pollInterval time.Duration
+	files        map[uint64]*tailedFile
+	unacked      map[*tailedFile][]unackedFileLine
+	initial      bool
+	mu           sync.Mutex
+}
+func NewFileTailerAdapter(patterns []string, checkpointPath string, pollInterval time.Duration) (*FileTailerAdapter, error) {
+	for _, pattern := range patterns {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid log file pattern %q: %w", pattern, err)
+		}
+	}
+	checkpoint, err := openLogCheckpoint(checkpointPath)
+	if err != nil {
+		return nil, err
+	}
+	if pollInterval <= 0 {
+		pollInterval = time.Second
+	}
+	return &FileTailerAdapter{
+		patterns:     patterns,
+		checkpoint:   checkpoint,
+		pollInterval: pollInterval,
+		files:        make(map[uint64]*tailedFile),
+		unacked:      make(map[*tailedFile][]unackedFileLine),
+		initial:      len(checkpoint.keys()) == 0,
+	}, nil
+}
+func (t *FileTailerAdapter) Run(ctx context.Context, entries chan<- *entities.LogEntry) error {
+	ticker := time.NewTicker(t.pollInterval)
+	defer ticker.Stop()
+	for {
+		if err := t.poll(ctx, entries); err != nil && ctx.Err() == nil {
+			log.Printf("Failed to tail log files: %v", err)
+		}
+		if err := t.checkpoint.save(); err != nil {
+			log.Printf("Failed to save log file offsets: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return t.checkpoint.save()
+		case <-ticker.C:
+		}
+	}
+}
+func (t *FileTailerAdapter) Acknowledge(entries []*entities.LogEntry) {
+	acked := make(map[*entities.LogEntry]bool, len(entries))
+	for _, entry := range entries {
+		if entry.Source == fileLogSource {
+			acked[entry] = true
+		}
+	}
+	if len(acked) == 0 {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for f, lines := range t.unacked {
+		last := -1
+		for i, line := range lines {
+			if acked[line.entry] {
+				last = i
+			}
+		}
+		if last < 0 {
+			continue
+		}
+		f.acked = lines[last].offset
+		t.checkpoint.set(f.path, f.position())
+		if remaining := lines[last+1:]; len(remaining) > 0 {
+			t.unacked[f] = remaining
+		} else {
+			delete(t.unacked, f)
+		}
+	}
+}
+func (t *FileTailerAdapter) Close() error {
+	for inode, f := range t.files {
+		f.file.Close()
+		delete(t.files, inode)
+	}
+	return t.checkpoint.save()
+}
+func (t *FileTailerAdapter) poll(ctx context.Context, entries chan<- *entities.LogEntry) error {
+	matched := make(map[uint64]string)
+	infos := make(map[uint64]os.FileInfo)
+	for _, pattern := range t.patterns {
+		paths, _ := filepath.Glob(pattern)
+		for _, path := range paths {
+			info, err := os.Stat(path)
+			if err != nil || !info.Mode().IsRegular() {
+				continue
+			}
+			inode := fileInode(path, info)
+			matched[inode] = path
+			infos[inode] = info
+		}
+	}
+	for inode, f := range t.files {
+		if _, ok := matched[inode]; ok {
+			continue
+		}
+		err := t.read(ctx, f, entries, true)
+		f.file.Close()
+		delete(t.files, inode)
+		if err != nil {
+			return err
+		}
+	}
+	known := t.checkpoint.byInode()
+	inodes := make([]uint64, 0, len(matched))
+	for inode := range matched {
+		inodes = append(inodes, inode)
+	}
+	sort.Slice(inodes, func(i, j int) bool { return matched[inodes[i]] < matched[inodes[j]] })
+	paths := make(map[string]bool, len(matched))
+	for _, inode := range inodes {
+		path := matched[inode]
+		paths[path] = true
+		f, ok := t.files[inode]
+		if !ok {
+			var err error
+			f, err = t.open(ctx, path, inode, infos[inode], known, entries)
+			if err != nil {
+				log.Printf("Failed to open log file %s: %v", path, err)
+				continue
+			}
+			if f == nil {
+				continue
+			}
+			t.files[inode] = f
+		}
+		t.mu.Lock()
+		f.path = path
+		t.mu.Unlock()
+		if err := t.read(ctx, f, entries, false); err != nil {
+			return err
+		}
+	}
+	for _, path := range t.checkpoint.keys() {
+		if !paths[path] {
+			t.checkpoint.delete(path)
+		}
+	}
+	t.initial = false
+	return nil
+}
+func (t *FileTailerAdapter) open(ctx context.Context, path string, inode uint64, info os.FileInfo, known map[uint64]logPosition, entries chan<- *entities.LogEntry) (*tailedFile, error) {
+	position, seen := known[inode]
+	if strings.HasSuffix(path, ".gz") {
+		if !seen && t.initial {
+			if err := t.readGzip(ctx, path, entries); err != nil {
+				return nil, err
+			}
+		}
+		t.checkpoint.set(path, logPosition{Inode: inode, Offset: info.Size()})
+		return nil, nil
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	f := &tailedFile{path: path, inode: inode, file: file}
+	if seen && position.Offset <= info.Size() && position.FingerprintSize <= info.Size() {
+		if fingerprint, err := fileFingerprint(file, position.FingerprintSize); err == nil && fingerprint == position.Fingerprint {
+			f.offset, f.acked = position.Offset, position.Offset
+			f.fingerprint, f.fingerprintSize = position.Fingerprint, position.FingerprintSize
+		}
+	}
+	if _, err := file.Seek(f.offset, io.SeekStart); err != nil {
+		file.Close()
+		return nil, err
+	}
+	f.reader = bufio.NewReader(file)
+	return f, nil
+}
+func (t *FileTailerAdapter) read(ctx context.Context, f *tailedFile, entries chan<- *entities.LogEntry, final bool) error {
+	defer func() {
+		t.mu.Lock()
+		t.checkpoint.set(f.path, f.position())
+		t.mu.Unlock()
+	}()
+	if t.truncated(f) {
+		if _, err := f.file.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		f.reader.Reset(f.file)
+		f.offset = 0
+		f.partial = nil
+		t.mu.Lock()
+		f.acked, f.fingerprint, f.fingerprintSize = 0, 0, 0
+		delete(t.unacked, f)
+		t.mu.Unlock()
+	}
+	defer t.updateFingerprint(f)
+	for {
+		line, err := f.reader.ReadBytes('\n')
+		if len(line) > 0 {
+			f.partial = append(f.partial, line...)
+			if line[len(line)-1] == '\n' || len(f.partial) >= maxLogLineBytes {
+				if emitErr := t.emit(ctx, entries, f); emitErr != nil {
+					return emitErr
+				}
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if final && len(f.partial) > 0 {
+		return t.emit(ctx, entries, f)
+	}
+	return nil
+}
+func (t *FileTailerAdapter) emit(ctx context.Context, entries chan<- *entities.LogEntry, f *tailedFile) error {
+	entry := newFileLogEntry(f.path, f.partial)
+	t.mu.Lock()
+	t.unacked[f] = append(t.unacked[f], unackedFileLine{entry: entry, offset: f.offset + int64(len(f.partial))})
+	t.mu.Unlock()
+	if err := sendLogEntry(ctx, entries, entry); err != nil {
+		return err
+	}
+	f.offset += int64(len(f.partial))
+	f.partial = nil
+	return nil
+}
+func (t *FileTailerAdapter) truncated(f *tailedFile) bool {
+	info, err := f.file.Stat()
+	if err != nil {
+		return false
+	}
+	if info.Size() < f.offset+int64(len(f.partial)) || info.Size() < f.fingerprintSize {
+		return true
+	}
+	if f.fingerprintSize == 0 {
+		return false
+	}
+	fingerprint, err := fileFingerprint(f.file, f.fingerprintSize)
+	return err == nil && fingerprint != f.fingerprint
+}
+func (t *FileTailerAdapter) updateFingerprint(f *tailedFile) {
+	size := min(f.offset, logFingerprintBytes)
+	if size <= f.fingerprintSize {
+		return
+	}
+	fingerprint, err := fileFingerprint(f.file, size)
+	if err != nil {
+		return
+	}
+	t.mu.Lock()
+	f.fingerprint, f.fingerprintSize = fingerprint, size
+	t.mu.Unlock()
+}
+func (f *tailedFile) position() logPosition {
+	return logPosition{Inode: f.inode, Offset: f.acked, Fingerprint: f.fingerprint, FingerprintSize: f.fingerprintSize}
+}
+func fileFingerprint(file *os.File, size int64) (uint64, error) {
+	h := fnv.New64a()
+	if _, err := io.Copy(h, io.NewSectionReader(file, 0, size)); err != nil {
+		return 0, err
+	}
+	return h.Sum64(), nil
+}
+func (t *FileTailerAdapter) readGzip(ctx context.Context, path string, entries chan<- *entities.LogEntry) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+	reader := bufio.NewReader(gz)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			if emitErr := sendLogEntry(ctx, entries, newFileLogEntry(path, line)); emitErr != nil {
+				return emitErr
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+func newFileLogEntry(path string, line []byte) *entities.LogEntry {
+	return &entities.LogEntry{
+		Timestamp: time.Now(),
+		Source:    fileLogSource,
+		Labels:    map[string]string{"filename": path},
+		Message:   strings.TrimSuffix(strings.TrimSuffix(string(line), "\n"), "\r"),
+	}
+}
+func sendLogEntry(ctx context.Context, entries chan<- *entities.LogEntry, entry *entities.LogEntry) error {
+	select {
+	case entries <- entry:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+func pathInode(path string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(path))
+	return h.Sum64()
+}
\ No newline at end of file
@@ -0,0 +1,178 @@
+package adapters
+import (
+	"compress/gzip"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"observability-system/internal/domain/entities"
+)
+func appendLogLines(t *testing.T, path string, lines ...string) {
+	t.Helper()
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	if _, err := file.WriteString(strings.Join(lines, "")); err != nil {
+		t.Fatal(err)
+	}
+}
+func pollTailer(t *testing.T, tailer *FileTailerAdapter) []string {
+	t.Helper()
+	entries := pollTailerEntries(t, tailer)
+	tailer.Acknowledge(entries)
+	return logMessages(entries)
+}
+func pollTailerEntries(t *testing.T, tailer *FileTailerAdapter) []*entities.LogEntry {
+	t.Helper()
+	entries := make(chan *entities.LogEntry, 64)
+	if err := tailer.poll(context.Background(), entries); err != nil {
+		t.Fatal(err)
+	}
+	close(entries)
+	var result []*entities.LogEntry
+	for entry := range entries {
+		if entry.Source != "file" || entry.Labels["filename"] == "" {
+			t.Fatalf("entry missing file metadata: %+v", entry)
+		}
+		result = append(result, entry)
+	}
+	return result
+}
+func logMessages(entries []*entities.LogEntry) []string {
+	var messages []string
+	for _, entry := range entries {
+		messages = append(messages, entry.Message)
+	}
+	return messages
+}
+func newTestTailer(t *testing.T, dir, pattern string) *FileTailerAdapter {
+	t.Helper()
+	tailer, err := NewFileTailerAdapter([]string{filepath.Join(dir, pattern)}, filepath.Join(dir, "state", "offsets.json"), time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tailer
+}
+func TestFileTailerFollowsRenameRotation(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	appendLogLines(t, path, "one\n", "two\n", "partial")
+	tailer := newTestTailer(t, dir, "app.log")
+	defer tailer.Close()
+	if got := strings.Join(pollTailer(t, tailer), "|"); got != "one|two" {
+		t.Fatalf("unexpected first read %q", got)
+	}
+	appendLogLines(t, path, " line\n", "three\n")
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	appendLogLines(t, path+".1", "late write to rotated file\n")
+	appendLogLines(t, path, "fresh\n")
+	if got := strings.Join(pollTailer(t, tailer), "|"); got != "partial line|three|late write to rotated file|fresh" {
+		t.Fatalf("unexpected read across rotation %q", got)
+	}
+	if got := pollTailer(t, tailer); len(got) != 0 {
+		t.Fatalf("expected nothing new, got %q", got)
+	}
+}
+func TestFileTailerHandlesCopyTruncateAndResumesFromCheckpoint(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	appendLogLines(t, path, "before truncate 1\n", "before truncate 2\n")
+	tailer := newTestTailer(t, dir, "*.log")
+	if got := pollTailer(t, tailer); len(got) != 2 {
+		t.Fatalf("expected 2 lines, got %q", got)
+	}
+	if err := os.Truncate(path, 0); err != nil {
+		t.Fatal(err)
+	}
+	appendLogLines(t, path, "after\n")
+	if got := strings.Join(pollTailer(t, tailer), "|"); got != "after" {
+		t.Fatalf("expected read from start after truncation, got %q", got)
+	}
+	if err := tailer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	appendLogLines(t, path, "while stopped\n")
+	restarted := newTestTailer(t, dir, "*.log")
+	defer restarted.Close()
+	if got := strings.Join(pollTailer(t, restarted), "|"); got != "while stopped" {
+		t.Fatalf("expected only lines written while stopped, got %q", got)
+	}
+}
+func TestFileTailerReadsGzipRotationsOnFirstScanOnly(t *testing.T) {
+	dir := t.TempDir()
+	writeGzip := func(name string, content string) {
+		file, err := os.Create(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		gz := gzip.NewWriter(file)
+		gz.Write([]byte(content))
+		gz.Close()
+		file.Close()
+	}
+	writeGzip("app.log.2.gz", "archived 1\narchived 2\n")
+	appendLogLines(t, filepath.Join(dir, "app.log"), "live\n")
+	tailer := newTestTailer(t, dir, "app.log*")
+	defer tailer.Close()
+	if got := strings.Join(pollTailer(t, tailer), "|"); got != "live|archived 1|archived 2" {
+		t.Fatalf("unexpected initial read %q", got)
+	}
+	writeGzip("app.log.3.gz", "compressed copy of an already tailed file\n")
+	if got := pollTailer(t, tailer); len(got) != 0 {
+		t.Fatalf("gzip rotations after the first scan should not be re-read, got %q", got)
+	}
+}
+func TestFileTailerOnlyCheckpointsAcknowledgedLines(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	appendLogLines(t, path, "one\n", "two\n", "three\n")
+	tailer := newTestTailer(t, dir, "app.log")
+	entries := pollTailerEntries(t, tailer)
+	if got := strings.Join(logMessages(entries), "|"); got != "one|two|three" {
+		t.Fatalf("unexpected first read %q", got)
+	}
+	tailer.Acknowledge(entries[:1])
+	if err := tailer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	restarted := newTestTailer(t, dir, "app.log")
+	defer restarted.Close()
+	if got := strings.Join(pollTailer(t, restarted), "|"); got != "two|three" {
+		t.Fatalf("expected unacknowledged lines to be read again, got %q", got)
+	}
+}
+func TestFileTailerDetectsCopyTruncateAfterTheFileRegrows(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	appendLogLines(t, path, "before 1\n", "before 2\n")
+	tailer := newTestTailer(t, dir, "app.log")
+	defer tailer.Close()
+	if got := pollTailer(t, tailer); len(got) != 2 {
+		t.Fatalf("expected 2 lines, got %q", got)
+	}
+	if err := os.Truncate(path, 0); err != nil {
+		t.Fatal(err)
+	}
+	appendLogLines(t, path, "after truncation 1\n", "after truncation 2\n")
+	if got := strings.Join(pollTailer(t, tailer), "|"); got != "after truncation 1|after truncation 2" {
+		t.Fatalf("expected the regrown file to be read from the start, got %q", got)
+	}
+	if err := tailer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Truncate(path, 0); err != nil {
+		t.Fatal(err)
+	}
+	appendLogLines(t, path, "replaced while stopped, long enough to pass the offset\n")
+	restarted := newTestTailer(t, dir, "app.log")
+	defer restarted.Close()
+	if got := strings.Join(pollTailer(t, restarted), "|"); got != "replaced while stopped, long enough to pass the offset" {
+		t.Fatalf("expected a truncation while stopped to restart from the beginning, got %q", got)
+	}
+}
\ No newline at end of file
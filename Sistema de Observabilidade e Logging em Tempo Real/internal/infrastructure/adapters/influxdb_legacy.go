@@ -0,0 +1,78 @@
+package adapters
+import (
+	"time"
+	"github.com/influxdata/influxdb-client-go/v2/api/query"
+	"observability-system/internal/domain/entities"
+	"observability-system/internal/infrastructure/flux"
+)
+const (
+	legacySampleMeasurement = "container_metrics"
+	legacyRollupMeasurement = "container_metrics_rollup"
+)
+var legacyMetricFields = map[string]string{
+	"cpu_percent":    "container_cpu_usage_percent",
+	"memory_usage":   "container_memory_usage_bytes",
+	"memory_limit":   "container_memory_limit_bytes",
+	"memory_percent": "container_memory_usage_percent",
+	"network_rx":     "container_network_rx_bytes_total",
+	"network_tx":     "container_network_tx_bytes_total",
+	"disk_read":      "container_disk_read_bytes_total",
+	"disk_write":     "container_disk_write_bytes_total",
+	"pids":           "container_pids",
+}
+func legacySeriesQuery(bucket string, selector map[string]string, duration, window time.Duration) string {
+	filters := []flux.Predicate{flux.Equal("_measurement", legacySampleMeasurement)}
+	for _, name := range sortedKeys(selector) {
+		if name != entities.MetricNameLabel {
+			filters = append(filters, flux.Equal(name, selector[name]))
+			continue
+		}
+		field, ok := legacyField(selector[name])
+		if !ok {
+			return ""
+		}
+		filters = append(filters, flux.Equal("_field", field))
+	}
+	q := flux.From(bucket).Range(duration).Filter(filters...)
+	if window > 0 {
+		q = q.AggregateWindow(window, flux.Mean)
+	}
+	return q.String()
+}
+func legacyDeleteTags(tier string, selector map[string]string) (string, map[string]string, bool) {
+	tags := make(map[string]string, len(selector)+1)
+	for name, value := range selector {
+		if name != entities.MetricNameLabel {
+			tags[name] = value
+		}
+	}
+	metric, filtered := selector[entities.MetricNameLabel]
+	if tier == entities.RetentionTierRaw {
+		return legacySampleMeasurement, tags, !filtered
+	}
+	tags["tier"] = tier
+	if filtered {
+		field, ok := legacyField(metric)
+		if !ok {
+			return "", nil, false
+		}
+		tags["field"] = field
+	}
+	return legacyRollupMeasurement, tags, true
+}
+func legacyField(metric string) (string, bool) {
+	for field, name := range legacyMetricFields {
+		if name == metric {
+			return field, true
+		}
+	}
+	return "", false
+}
+func (p *seriesPivot) addLegacy(record *query.FluxRecord) {
+	name, ok := legacyMetricFields[record.Field()]
+	if !ok {
+		return
+	}
+	metricType, unit, _ := entities.ContainerMetricInfo(name)
+	p.add(name, recordLabels(record.Values()), string(metricType), unit, record.Time(), record.Value())
+}
\ No newline at end of file
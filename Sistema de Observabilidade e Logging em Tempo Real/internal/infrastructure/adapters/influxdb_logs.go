@@ -0,0 +1,293 @@
+package adapters
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+	influxdb2 "github.com/influxdata/influxdb-client-go/v2"
+	"github.com/influxdata/influxdb-client-go/v2/api/write"
+	"observability-system/internal/domain/entities"
+	"observability-system/internal/infrastructure/flux"
+)
+const (
+	logMeasurement    = "logs"
+	logTimestampSlack = time.Millisecond
+	logSeriesIdle     = time.Minute
+	logFeedSettle     = 5 * time.Second
+	logFeedWindow     = time.Hour
+)
+var logTagLabels = map[string]bool{"source": true, "stream": true, "container_id": true, "container_name": true, "level": true}
+type logSeriesClock struct {
+	last     map[string]time.Time
+	ingested int64
+	mu       sync.Mutex
+}
+func (c *logSeriesClock) next(series string, timestamp time.Time) time.Time {
+	if c.last == nil {
+		c.last = make(map[string]time.Time)
+	}
+	if last, ok := c.last[series]; ok && !timestamp.After(last) && last.Sub(timestamp) < logTimestampSlack {
+		timestamp = last.Add(time.Nanosecond)
+	}
+	if timestamp.After(c.last[series]) {
+		c.last[series] = timestamp
+	}
+	return timestamp
+}
+func (c *logSeriesClock) ingest(now time.Time) int64 {
+	stamp := now.UnixNano()
+	if stamp <= c.ingested {
+		stamp = c.ingested + 1
+	}
+	c.ingested = stamp
+	return stamp
+}
+func (c *logSeriesClock) prune(newest time.Time) {
+	for series, last := range c.last {
+		if newest.Sub(last) > logSeriesIdle {
+			delete(c.last, series)
+		}
+	}
+}
+func logSeriesKey(tags map[string]string) string {
+	var b strings.Builder
+	for _, name := range sortedKeys(tags) {
+		b.WriteString(name)
+		b.WriteByte('=')
+		b.WriteString(tags[name])
+		b.WriteByte(0)
+	}
+	return b.String()
+}
+func (r *InfluxDBRepository) WriteLogs(ctx context.Context, entries []*entities.LogEntry) error {
+	points, err := r.logPoints(entries)
+	if err != nil {
+		return err
+	}
+	return r.writePoints(ctx, points)
+}
+func (r *InfluxDBRepository) logPoints(entries []*entities.LogEntry) ([]*write.Point, error) {
+	points := make([]*write.Point, 0, len(entries))
+	var newest time.Time
+	now := time.Now()
+	untailed := 0
+	r.logClock.mu.Lock()
+	defer r.logClock.mu.Unlock()
+	for _, entry := range entries {
+		fields := map[string]interface{}{"message": entry.Message, "ingested": r.logClock.ingest(now)}
+		if now.Sub(entry.Timestamp) > logFeedWindow {
+			untailed++
+		}
+		if len(entry.Fields) > 0 {
+			encoded, err := json.Marshal(entry.Fields)
+			if err != nil {
+				return nil, err
+			}
+			fields["fields"] = string(encoded)
+		}
+		tags := withLabels(map[string]string{}, entry.StreamLabels())
+		for name, value := range entry.Fields {
+			if r.logFieldTags[name] {
+				withLabels(tags, map[string]string{name: value})
+			}
+		}
+		timestamp := r.logClock.next(logSeriesKey(tags), entry.Timestamp)
+		if timestamp.After(newest) {
+			newest = timestamp
+		}
+		points = append(points, influxdb2.NewPoint(logMeasurement, tags, fields, timestamp))
+	}
+	r.logClock.prune(newest)
+	if untailed > 0 {
+		log.Printf("⚠️ %d log entries are more than %s old and will not reach the live tail", untailed, logFeedWindow)
+	}
+	return points, nil
+}
+func (r *InfluxDBRepository) QueryLogs(ctx context.Context, query entities.LogQuery) ([]*entities.LogEntry, error) {
+	start, end := query.Start, query.End
+	if start.IsZero() {
+		start = time.Unix(0, 0)
+	}
+	if end.IsZero() {
+		end = time.Now().Add(time.Nanosecond)
+	}
+	q := flux.From(r.bucket).
+		RangeBetween(start, end).
+		Filter(flux.Equal("_measurement", logMeasurement)).
+		Filter(anyOf("container_id", query.ContainerIDs)...).
+		Filter(anyOf("container_name", query.ContainerNames)...).
+		Filter(anyOf("level", query.Levels)...).
+		Filter(selectorPredicates(streamLabelSelector(query.Labels))...).
+		Pivot([]string{"_time"}, []string{"_field"}, "_value").
+		Filter(logFieldPredicates(query.Labels)...)
+	if query.Contains != "" {
+		q = q.Filter(flux.ContainsFold("message", query.Contains))
+	}
+	if query.Regex != "" {
+		q = q.Filter(flux.Matches("message", query.Regex))
+	}
+	q = q.Group().Sort(true, "_time")
+	if query.Limit > 0 {
+		q = q.Limit(query.Limit)
+	}
+	fluxQuery := q.String()
+	var result []*entities.LogEntry
+	err := r.circuitBreaker.Execute(ctx, func() error {
+		queryResult, err := r.queryAPI.Query(ctx, fluxQuery)
+		if err != nil {
+			return err
+		}
+		defer queryResult.Close()
+		result = nil
+		for queryResult.Next() {
+			entry, err := logEntryFromRecord(queryResult.Record().Time(), queryResult.Record().Values())
+			if err != nil {
+				return err
+			}
+			if matchesLogLabels(entry, query.Labels) {
+				result = append(result, entry)
+			}
+		}
+		return queryResult.Err()
+	})
+	return result, err
+}
+func (r *InfluxDBRepository) LatestLogCursor(ctx context.Context) (string, error) {
+	return entities.EncodeLogCursor(time.Now().Add(-logFeedSettle), 0), nil
+}
+func (r *InfluxDBRepository) ReadLogsSince(ctx context.Context, cursor string, limit int) ([]*entities.LogEntry, string, error) {
+	since, skip := time.Now().Add(-logFeedSettle), 0
+	if cursor != "" {
+		var err error
+		if since, skip, err = entities.DecodeLogCursor(cursor); err != nil {
+			return nil, cursor, fmt.Errorf("invalid log cursor %q", cursor)
+		}
+	}
+	upper := time.Now().Add(-logFeedSettle)
+	if upper.Before(since) {
+		return nil, cursor, nil
+	}
+	q := flux.From(r.bucket).
+		RangeBetween(since.Add(-logFeedWindow), upper.Add(logFeedWindow)).
+		Filter(flux.Equal("_measurement", logMeasurement)).
+		Pivot([]string{"_time"}, []string{"_field"}, "_value").
+		Filter(flux.Exists("ingested"), flux.AtLeast("ingested", since.UnixNano()), flux.AtMost("ingested", upper.UnixNano())).
+		Group().
+		Sort(false, "ingested")
+	if limit > 0 {
+		q = q.Limit(limit + skip)
+	}
+	fluxQuery := q.String()
+	var entries []*entities.LogEntry
+	var stamps []int64
+	err := r.circuitBreaker.Execute(ctx, func() error {
+		queryResult, err := r.queryAPI.Query(ctx, fluxQuery)
+		if err != nil {
+			return err
+		}
+		defer queryResult.Close()
+		entries, stamps = nil, nil
+		for queryResult.Next() {
+			values := queryResult.Record().Values()
+			entry, err := logEntryFromRecord(queryResult.Record().Time(), values)
+			if err != nil {
+				return err
+			}
+			stamp, _ := values["ingested"].(int64)
+			entries = append(entries, entry)
+			stamps = append(stamps, stamp)
+		}
+		return queryResult.Err()
+	})
+	if err != nil {
+		return nil, cursor, err
+	}
+	full := limit > 0 && len(entries) == limit+skip
+	dropped := 0
+	for dropped < skip && dropped < len(stamps) && stamps[dropped] == since.UnixNano() {
+		dropped++
+	}
+	entries, stamps = entries[dropped:], stamps[dropped:]
+	if !full {
+		return entries, entities.EncodeLogCursor(upper.Add(time.Nanosecond), 0), nil
+	}
+	last := stamps[len(stamps)-1]
+	ties := 0
+	if last == since.UnixNano() {
+		ties = dropped
+	}
+	for _, stamp := range stamps {
+		if stamp == last {
+			ties++
+		}
+	}
+	return entries, entities.EncodeLogCursor(time.Unix(0, last), ties), nil
+}
+func logEntryFromRecord(timestamp time.Time, values map[string]interface{}) (*entities.LogEntry, error) {
+	message, _ := values["message"].(string)
+	var fields map[string]string
+	if encoded, ok := values["fields"].(string); ok && encoded != "" {
+		if err := json.Unmarshal([]byte(encoded), &fields); err != nil {
+			return nil, err
+		}
+	}
+	labels := recordLabels(values, "message", "fields")
+	for name, value := range fields {
+		if labels[name] == value {
+			delete(labels, name)
+		}
+	}
+	return entities.LogEntryFromLabels(timestamp, labels, message, fields), nil
+}
+func streamLabelSelector(labels map[string]string) map[string]string {
+	selector := make(map[string]string)
+	for name, value := range labels {
+		if logTagLabels[name] {
+			selector[name] = value
+		}
+	}
+	return selector
+}
+func logFieldPredicates(labels map[string]string) []flux.Predicate {
+	var predicates []flux.Predicate
+	for _, name := range sortedKeys(labels) {
+		if logTagLabels[name] {
+			continue
+		}
+		encodedName, _ := json.Marshal(name)
+		encodedValue, _ := json.Marshal(labels[name])
+		pattern := `[{,]` + regexp.QuoteMeta(string(encodedName)+":"+string(encodedValue)) + `[,}]`
+		predicates = append(predicates, flux.If(
+			flux.Exists(name),
+			flux.Equal(name, labels[name]),
+			flux.And(flux.Exists("fields"), flux.Matches("fields", pattern)),
+		))
+	}
+	return predicates
+}
+func matchesLogLabels(entry *entities.LogEntry, labels map[string]string) bool {
+	if len(labels) == 0 {
+		return true
+	}
+	set := entry.LabelSet()
+	for name, value := range labels {
+		if set[name] != value {
+			return false
+		}
+	}
+	return true
+}
+func anyOf(column string, values []string) []flux.Predicate {
+	if len(values) == 0 {
+		return nil
+	}
+	predicates := make([]flux.Predicate, len(values))
+	for i, value := range values {
+		predicates[i] = flux.Equal(column, value)
+	}
+	return []flux.Predicate{flux.Or(predicates...)}
+}
\ No newline at end of file
@@ -0,0 +1,123 @@
+package adapters
+import (
+	"math"
+	"sort"
+	"time"
+	"observability-system/internal/domain/entities"
+)
+type seriesPivot struct {
+	series map[string]*entities.Series
+}
+func newSeriesPivot() *seriesPivot {
+	return &seriesPivot{series: make(map[string]*entities.Series)}
+}
+func (p *seriesPivot) add(name string, labels map[string]string, metricType, unit string, timestamp time.Time, value interface{}) {
+	key := entities.SeriesKey(name, labels)
+	s, ok := p.series[key]
+	if !ok {
+		s = &entities.Series{
+			Name:   name,
+			Labels: labels,
+			Type:   entities.MetricType(metricType),
+			Unit:   unit,
+		}
+		p.series[key] = s
+	}
+	s.Points = append(s.Points, entities.SeriesPoint{Timestamp: timestamp, Value: toFloat(value)})
+}
+func (p *seriesPivot) result() []*entities.Series {
+	result := make([]*entities.Series, 0, len(p.series))
+	for _, s := range p.series {
+		result = append(result, s)
+	}
+	entities.SortSeries(result)
+	return result
+}
+type rollupPivot struct {
+	rows map[string]*entities.MetricsRollup
+}
+func newRollupPivot() *rollupPivot {
+	return &rollupPivot{rows: make(map[string]*entities.MetricsRollup)}
+}
+func (p *rollupPivot) add(name string, labels map[string]string, metricType, unit, tier, stat string, timestamp time.Time, value interface{}) {
+	key := entities.SeriesKey(name, labels) + "|" + timestamp.Format(time.RFC3339Nano)
+	row, ok := p.rows[key]
+	if !ok {
+		row = &entities.MetricsRollup{
+			Name:      name,
+			Labels:    labels,
+			Type:      entities.MetricType(metricType),
+			Unit:      unit,
+			Tier:      tier,
+			Timestamp: timestamp,
+		}
+		p.rows[key] = row
+	}
+	switch stat {
+	case "min":
+		row.Min = toFloat(value)
+	case "max":
+		row.Max = toFloat(value)
+	case "mean":
+		row.Mean = toFloat(value)
+	case "p95":
+		row.P95 = toFloat(value)
+	case "last":
+		row.Last = toFloat(value)
+	case "count":
+		row.Count = toUint(value)
+	}
+}
+func (p *rollupPivot) rollups() []*entities.MetricsRollup {
+	result := make([]*entities.MetricsRollup, 0, len(p.rows))
+	for _, row := range p.rows {
+		result = append(result, row)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Timestamp.Equal(result[j].Timestamp) {
+			return entities.SeriesKey(result[i].Name, result[i].Labels) < entities.SeriesKey(result[j].Name, result[j].Labels)
+		}
+		return result[i].Timestamp.Before(result[j].Timestamp)
+	})
+	return result
+}
+func recordLabels(values map[string]interface{}, exclude ...string) map[string]string {
+	labels := make(map[string]string)
+	for column, value := range values {
+		text, ok := value.(string)
+		if !ok || column == "" || column[0] == '_' || column == "result" || column == "table" {
+			continue
+		}
+		labels[column] = text
+	}
+	for _, column := range exclude {
+		delete(labels, column)
+	}
+	return labels
+}
+func toFloat(value interface{}) float64 {
+	switch v := value.(type) {
+	case float64:
+		return v
+	case int64:
+		return float64(v)
+	case uint64:
+		return float64(v)
+	}
+	return 0
+}
+func toUint(value interface{}) uint64 {
+	switch v := value.(type) {
+	case uint64:
+		return v
+	case int64:
+		if v > 0 {
+			return uint64(v)
+		}
+	case float64:
+		if v > 0 {
+			return uint64(math.Round(v))
+		}
+	}
+	return 0
+}
\ No newline at end of file
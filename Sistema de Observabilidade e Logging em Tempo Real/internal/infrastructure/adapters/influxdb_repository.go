@@ -1,90 +1,352 @@
 package adapters
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 	influxdb2 "github.com/influxdata/influxdb-client-go/v2"
 	"github.com/influxdata/influxdb-client-go/v2/api"
+	"github.com/influxdata/influxdb-client-go/v2/api/query"
+	"github.com/influxdata/influxdb-client-go/v2/api/write"
 	"observability-system/internal/domain/entities"
+	"observability-system/internal/infrastructure/flux"
 	"observability-system/internal/infrastructure/resilience"
 )
+const sampleMeasurement = "metrics"
+var (
+	ErrWriteBufferFull  = errors.New("influxdb write buffer is full")
+	ErrRepositoryClosed = errors.New("influxdb repository is closed")
+)
+type InfluxDBWriteOptions struct {
+	MaxBatchSize  int
+	FlushInterval time.Duration
+	BufferSize    int
+	RetryAttempts int
+	RetryDelay    time.Duration
+	LogFieldTags  []string
+}
 type InfluxDBRepository struct {
 	client         influxdb2.Client
-	writeAPI       api.WriteAPI
+	writeAPI       api.WriteAPIBlocking
 	queryAPI       api.QueryAPI
+	deleteAPI      api.DeleteAPI
 	org            string
 	bucket         string
 	circuitBreaker *resilience.CircuitBreaker
 	retryPolicy    *resilience.RetryPolicy
+	options        InfluxDBWriteOptions
+	logFieldTags   map[string]bool
+	logClock       logSeriesClock
+	points         chan *write.Point
+	queued         atomic.Int64
+	writeErrors    chan error
+	done           chan struct{}
+	closed         bool
+	batcherWG      sync.WaitGroup
+	errorsWG       sync.WaitGroup
+	closeOnce      sync.Once
+	mu             sync.RWMutex
+}
+func DefaultInfluxDBWriteOptions() InfluxDBWriteOptions {
+	return InfluxDBWriteOptions{
+		MaxBatchSize:  500,
+		FlushInterval: 1 * time.Second,
+		BufferSize:    10000,
+		RetryAttempts: 3,
+		RetryDelay:    1 * time.Second,
+	}
 }
 func NewInfluxDBRepository(url, token, org, bucket string) *InfluxDBRepository {
+	return NewInfluxDBRepositoryWithOptions(url, token, org, bucket, DefaultInfluxDBWriteOptions())
+}
+func NewInfluxDBRepositoryWithOptions(url, token, org, bucket string, options InfluxDBWriteOptions) *InfluxDBRepository {
+	defaults := DefaultInfluxDBWriteOptions()
+	if options.MaxBatchSize <= 0 {
+		options.MaxBatchSize = defaults.MaxBatchSize
+	}
+	if options.FlushInterval <= 0 {
+		options.FlushInterval = defaults.FlushInterval
+	}
+	if options.BufferSize < options.MaxBatchSize {
+		options.BufferSize = options.MaxBatchSize
+	}
+	if options.RetryAttempts <= 0 {
+		options.RetryAttempts = defaults.RetryAttempts
+	}
+	if options.RetryDelay <= 0 {
+		options.RetryDelay = defaults.RetryDelay
+	}
+	logFieldTags := make(map[string]bool, len(options.LogFieldTags))
+	for _, name := range options.LogFieldTags {
+		logFieldTags[name] = true
+	}
 	client := influxdb2.NewClient(url, token)
-	return &InfluxDBRepository{
+	r := &InfluxDBRepository{
 		client:         client,
-		writeAPI:       client.WriteAPI(org, bucket),
+		writeAPI:       client.WriteAPIBlocking(org, bucket),
 		queryAPI:       client.QueryAPI(org),
+		deleteAPI:      client.DeleteAPI(),
 		org:            org,
 		bucket:         bucket,
 		circuitBreaker: resilience.NewCircuitBreaker(5, 30*time.Second),
-		retryPolicy:    resilience.NewRetryPolicy(3, 1*time.Second, 2.0),
+		retryPolicy:    resilience.NewRetryPolicy(options.RetryAttempts, options.RetryDelay, 2.0),
+		options:        options,
+		logFieldTags:   logFieldTags,
+		points:         make(chan *write.Point, options.BufferSize),
+		writeErrors:    make(chan error, 16),
+		done:           make(chan struct{}),
+	}
+	r.errorsWG.Add(1)
+	go r.drainErrors()
+	r.batcherWG.Add(1)
+	go r.runBatcher()
+	return r
+}
+func (r *InfluxDBRepository) Save(ctx context.Context, samples []entities.Sample) error {
+	var points []*write.Point
+	for _, sample := range samples {
+		for _, scalar := range sample.Expand() {
+			points = append(points, influxdb2.NewPoint(
+				sampleMeasurement,
+				withLabels(map[string]string{
+					"metric":      scalar.Name,
+					"metric_type": string(scalar.Type),
+					"unit":        scalar.Unit,
+				}, scalar.Labels),
+				map[string]interface{}{"value": scalar.Value},
+				scalar.Timestamp,
+			))
+		}
+	}
+	return r.enqueue(points)
+}
+func (r *InfluxDBRepository) enqueue(points []*write.Point) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return ErrRepositoryClosed
+	}
+	if r.circuitBreaker.IsOpen() {
+		return resilience.ErrCircuitOpen
+	}
+	if r.queued.Load()+int64(len(points)) > int64(r.options.BufferSize) {
+		return ErrWriteBufferFull
+	}
+	r.queued.Add(int64(len(points)))
+	for _, p := range points {
+		r.points <- p
+	}
+	return nil
+}
+func (r *InfluxDBRepository) runBatcher() {
+	defer r.batcherWG.Done()
+	ticker := time.NewTicker(r.options.FlushInterval)
+	defer ticker.Stop()
+	var pending []*write.Point
+	for {
+		select {
+		case p := <-r.points:
+			pending = append(pending, p)
+			if len(pending)%r.options.MaxBatchSize == 0 {
+				pending = r.flushPending(pending)
+			}
+		case <-ticker.C:
+			pending = r.flushPending(pending)
+		case <-r.done:
+			for {
+				select {
+				case p := <-r.points:
+					pending = append(pending, p)
+				default:
+					if pending = r.flushPending(pending); len(pending) > 0 {
+						r.reportWriteError(fmt.Errorf("dropping %d unwritten points on close", len(pending)))
+					}
+					return
+				}
+			}
+		}
+	}
+}
+func (r *InfluxDBRepository) flushPending(pending []*write.Point) []*write.Point {
+	for len(pending) > 0 {
+		batch := pending[:min(r.options.MaxBatchSize, len(pending))]
+		if err := r.writeBatch(context.Background(), batch); err != nil {
+			if !errors.Is(err, resilience.ErrCircuitOpen) {
+				r.reportWriteError(fmt.Errorf("%d points kept for retry: %w", len(pending), err))
+			}
+			return pending
+		}
+		pending = pending[len(batch):]
+		r.queued.Add(-int64(len(batch)))
+	}
+	return pending[:0]
+}
+func (r *InfluxDBRepository) reportWriteError(err error) {
+	select {
+	case r.writeErrors <- err:
+	default:
+	}
+}
+func (r *InfluxDBRepository) drainErrors() {
+	defer r.errorsWG.Done()
+	for err := range r.writeErrors {
+		log.Printf("InfluxDB write failed: %v", err)
+	}
+}
+func (r *InfluxDBRepository) writePoints(ctx context.Context, points []*write.Point) error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.closed {
+		return ErrRepositoryClosed
 	}
+	for start := 0; start < len(points); start += r.options.MaxBatchSize {
+		if err := r.writeBatch(ctx, points[start:min(start+r.options.MaxBatchSize, len(points))]); err != nil {
+			return err
+		}
+	}
+	return nil
 }
-func (r *InfluxDBRepository) Save(ctx context.Context, metrics *entities.ContainerMetrics) error {
+func (r *InfluxDBRepository) writeBatch(ctx context.Context, batch []*write.Point) error {
 	return r.circuitBreaker.Execute(ctx, func() error {
 		return r.retryPolicy.Execute(ctx, func() error {
-			p := influxdb2.NewPoint(
-				"container_metrics",
-				map[string]string{
-					"container_id":   metrics.ContainerID,
-					"container_name": metrics.ContainerName,
-				},
-				map[string]interface{}{
-					"cpu_percent":    metrics.CPUPercent,
-					"memory_usage":   metrics.MemoryUsage,
-					"memory_limit":   metrics.MemoryLimit,
-					"memory_percent": metrics.MemoryPercent,
-					"network_rx":     metrics.NetworkRx,
-					"network_tx":     metrics.NetworkTx,
-				},
-				metrics.Timestamp,
-			)
-			r.writeAPI.WritePoint(p)
-			r.writeAPI.Flush()
-			select {
-			case err := <-r.writeAPI.Errors():
-				return err
-			default:
-				return nil
-			}
+			return r.writeAPI.WritePoint(ctx, batch...)
 		})
 	})
 }
-func (r *InfluxDBRepository) FindByContainerID(ctx context.Context, containerID string, duration time.Duration) ([]*entities.ContainerMetrics, error) {
-	var result []*entities.ContainerMetrics
+func (r *InfluxDBRepository) FindSeries(ctx context.Context, selector map[string]string, duration time.Duration) ([]*entities.Series, error) {
+	return r.FindSeriesAggregated(ctx, selector, duration, 0)
+}
+func (r *InfluxDBRepository) FindSeriesAggregated(ctx context.Context, selector map[string]string, duration, window time.Duration) ([]*entities.Series, error) {
+	q := flux.From(r.bucket).
+		Range(duration).
+		Filter(flux.Equal("_measurement", sampleMeasurement), flux.Equal("_field", "value")).
+		Filter(selectorPredicates(selector)...)
+	if window > 0 {
+		q = q.AggregateWindow(window, flux.Mean)
+	}
+	seriesQuery := q.String()
+	legacyQuery := legacySeriesQuery(r.bucket, selector, duration, window)
+	var result []*entities.Series
 	err := r.circuitBreaker.Execute(ctx, func() error {
-		query := fmt.Sprintf(`
-			from(bucket: "%s")
-			|> range(start: -%s)
-			|> filter(fn: (r) => r["_measurement"] == "container_metrics")
-			|> filter(fn: (r) => r["container_id"] == "%s")
-		`, r.bucket, duration.String(), containerID)
-		queryResult, err := r.queryAPI.Query(ctx, query)
+		pivot := newSeriesPivot()
+		err := r.queryRecords(ctx, seriesQuery, func(record *query.FluxRecord) {
+			values := record.Values()
+			name, _ := values["metric"].(string)
+			metricType, _ := values["metric_type"].(string)
+			unit, _ := values["unit"].(string)
+			pivot.add(name, recordLabels(values, "metric", "metric_type", "unit"), metricType, unit, record.Time(), record.Value())
+		})
 		if err != nil {
 			return err
 		}
-		for queryResult.Next() {
-			log.Printf("Query result: %v", queryResult.Record().Values())
+		if legacyQuery != "" {
+			if err := r.queryRecords(ctx, legacyQuery, pivot.addLegacy); err != nil {
+				return err
+			}
 		}
-		return queryResult.Err()
+		result = pivot.result()
+		return nil
 	})
 	return result, err
 }
-func (r *InfluxDBRepository) FindAll(ctx context.Context, duration time.Duration) ([]*entities.ContainerMetrics, error) {
-	return nil, nil
+func (r *InfluxDBRepository) queryRecords(ctx context.Context, q string, fn func(record *query.FluxRecord)) error {
+	queryResult, err := r.queryAPI.Query(ctx, q)
+	if err != nil {
+		return err
+	}
+	defer queryResult.Close()
+	for queryResult.Next() {
+		fn(queryResult.Record())
+	}
+	return queryResult.Err()
+}
+func (r *InfluxDBRepository) DeleteMetrics(ctx context.Context, tier string, selector map[string]string, before time.Time) (int64, error) {
+	tags := make(map[string]string, len(selector)+1)
+	for name, value := range selector {
+		tags[selectorColumn(name)] = value
+	}
+	measurement := sampleMeasurement
+	if tier != entities.RetentionTierRaw {
+		measurement = rollupMeasurement
+		tags["tier"] = tier
+	}
+	filters := []flux.Predicate{flux.Equal("_measurement", measurement)}
+	for _, name := range sortedKeys(tags) {
+		filters = append(filters, flux.Equal(name, tags[name]))
+	}
+	legacyMeasurement, legacyTags, deleteLegacy := legacyDeleteTags(tier, selector)
+	err := r.circuitBreaker.Execute(ctx, func() error {
+		if err := r.deleteAPI.DeleteWithName(ctx, r.org, r.bucket, time.Unix(0, 0), before, deletePredicate(measurement, tags)); err != nil {
+			return err
+		}
+		if !deleteLegacy {
+			return nil
+		}
+		return r.deleteAPI.DeleteWithName(ctx, r.org, r.bucket, time.Unix(0, 0), before, deletePredicate(legacyMeasurement, legacyTags))
+	})
+	if err != nil {
+		return 0, err
+	}
+	return entities.RetentionDeletedUnknown, nil
 }
 func (r *InfluxDBRepository) Close() error {
-	r.writeAPI.Flush()
-	r.client.Close()
+	r.closeOnce.Do(func() {
+		r.mu.Lock()
+		r.closed = true
+		close(r.done)
+		r.mu.Unlock()
+		r.batcherWG.Wait()
+		close(r.writeErrors)
+		r.errorsWG.Wait()
+		r.client.Close()
+	})
 	return nil
+}
+func withLabels(tags map[string]string, labels map[string]string) map[string]string {
+	for name, value := range labels {
+		if _, reserved := tags[name]; reserved || name == "" || strings.HasPrefix(name, "_") {
+			continue
+		}
+		tags[name] = value
+	}
+	return tags
+}
+func deletePredicate(measurement string, tags map[string]string) string {
+	var b strings.Builder
+	b.WriteString("_measurement=")
+	b.WriteString(strconv.Quote(measurement))
+	for _, name := range sortedKeys(tags) {
+		b.WriteString(" AND ")
+		b.WriteString(name)
+		b.WriteString("=")
+		b.WriteString(strconv.Quote(tags[name]))
+	}
+	return b.String()
+}
+func selectorPredicates(selector map[string]string) []flux.Predicate {
+	var predicates []flux.Predicate
+	for _, name := range sortedKeys(selector) {
+		predicates = append(predicates, flux.Equal(selectorColumn(name), selector[name]))
+	}
+	return predicates
+}
+func selectorColumn(name string) string {
+	if name == entities.MetricNameLabel {
+		return "metric"
+	}
+	return name
+}
+func sortedKeys(values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
 }
\ No newline at end of file
@@ -0,0 +1,605 @@
+package adapters
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"observability-system/internal/domain/entities"
+	"observability-system/internal/infrastructure/resilience"
+)
+const stubFluxResponse = `#datatype,string,long,dateTime:RFC3339,dateTime:RFC3339,dateTime:RFC3339,double,string,string,string,string,string,string,string
+#group,false,false,true,true,false,false,true,true,true,true,true,true,true
+#default,_result,,,,,,,,,,,,
+,result,table,_start,_stop,_time,_value,_field,_measurement,container_id,container_name,metric,metric_type,unit
+,,0,2024-01-01T00:00:00Z,2024-01-01T01:00:00Z,2024-01-01T00:00:05Z,42.5,value,metrics,abc,web,container_cpu_usage_percent,gauge,percent
+,,0,2024-01-01T00:00:00Z,2024-01-01T01:00:00Z,2024-01-01T00:00:10Z,50.25,value,metrics,abc,web,container_cpu_usage_percent,gauge,percent
+
+#datatype,string,long,dateTime:RFC3339,dateTime:RFC3339,dateTime:RFC3339,double,string,string,string,string,string,string,string
+#group,false,false,true,true,false,false,true,true,true,true,true,true,true
+#default,_result,,,,,,,,,,,,
+,result,table,_start,_stop,_time,_value,_field,_measurement,container_id,container_name,metric,metric_type,unit
+,,1,2024-01-01T00:00:00Z,2024-01-01T01:00:00Z,2024-01-01T00:00:05Z,1048576,value,metrics,abc,web,container_memory_usage_bytes,gauge,bytes
+,,1,2024-01-01T00:00:00Z,2024-01-01T01:00:00Z,2024-01-01T00:00:10Z,2097152,value,metrics,abc,web,container_memory_usage_bytes,gauge,bytes
+
+`
+const stubLegacyResponse = `#datatype,string,long,dateTime:RFC3339,dateTime:RFC3339,dateTime:RFC3339,double,string,string,string,string
+#group,false,false,true,true,false,false,true,true,true,true
+#default,_result,,,,,,,,,
+,result,table,_start,_stop,_time,_value,_field,_measurement,container_id,container_name
+,,0,2024-01-01T00:00:00Z,2024-01-01T01:00:00Z,2024-01-01T00:00:01Z,12.5,cpu_percent,container_metrics,abc,web
+,,1,2024-01-01T00:00:00Z,2024-01-01T01:00:00Z,2024-01-01T00:00:01Z,512,memory_usage,container_metrics,abc,web
+
+`
+const stubLogsResponse = `#datatype,string,long,dateTime:RFC3339,dateTime:RFC3339,dateTime:RFC3339,string,string,string,string,string,string,string,string
+#group,false,false,false,false,false,false,false,false,false,false,false,false,false
+#default,_result,,,,,,,,,,,,
+,result,table,_start,_stop,_time,_measurement,container_id,container_name,level,source,stream,message,fields
+,,0,2024-01-01T00:00:00Z,2024-01-01T01:00:00Z,2024-01-01T00:00:10Z,logs,abc,web,error,docker,stderr,db timeout,"{""request_id"":""r1""}"
+,,0,2024-01-01T00:00:00Z,2024-01-01T01:00:00Z,2024-01-01T00:00:05Z,logs,abc,web,info,docker,stdout,started,
+
+`
+const stubTaggedLogsResponse = `#datatype,string,long,dateTime:RFC3339,dateTime:RFC3339,dateTime:RFC3339,string,string,string,string,string
+#group,false,false,false,false,false,false,false,false,false,false
+#default,_result,,,,,,,,,
+,result,table,_start,_stop,_time,_measurement,container_id,user_id,message,fields
+,,0,2024-01-01T00:00:00Z,2024-01-01T01:00:00Z,2024-01-01T00:00:10Z,logs,abc,42,login,"{""user_id"":""42""}"
+
+`
+const stubParsedFieldLogsResponse = `#datatype,string,long,dateTime:RFC3339,dateTime:RFC3339,dateTime:RFC3339,string,string,string,string
+#group,false,false,false,false,false,false,false,false,false
+#default,_result,,,,,,,,
+,result,table,_start,_stop,_time,_measurement,container_id,message,fields
+,,0,2024-01-01T00:00:00Z,2024-01-01T01:00:00Z,2024-01-01T00:00:10Z,logs,abc,checkout,"{""order_id"":""o-1"",""user_id"":""42""}"
+,,0,2024-01-01T00:00:00Z,2024-01-01T01:00:00Z,2024-01-01T00:00:05Z,logs,abc,checkout,"{""order_id"":""o-2"",""user_id"":""420""}"
+
+`
+func stubIngestedLogsResponse(rows ...string) string {
+	response := "#datatype,string,long,dateTime:RFC3339,dateTime:RFC3339,dateTime:RFC3339,string,string,string,long\n" +
+		"#group,false,false,false,false,false,false,false,false,false\n" +
+		"#default,_result,,,,,,,,\n" +
+		",result,table,_start,_stop,_time,_measurement,container_id,message,ingested\n"
+	for _, row := range rows {
+		response += ",,0,2024-01-01T00:00:00Z,2024-01-01T01:00:00Z,2024-01-01T00:00:10Z,logs,abc," + row + "\n"
+	}
+	return response + "\n"
+}
+func newStubInfluxServer(t *testing.T, queries *[]string) *httptest.Server {
+	return newStubFluxServer(t, queries, stubFluxResponse)
+}
+func newStubFluxServer(t *testing.T, queries *[]string, responses ...string) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v2/query" {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		var body struct {
+			Query string `json:"query"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("failed to decode query: %v", err)
+		}
+		*queries = append(*queries, body.Query)
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Write([]byte(responses[min(len(*queries), len(responses))-1]))
+	}))
+}
+func TestInfluxDBRepositoryFindSeriesPivotsToLegacyMetrics(t *testing.T) {
+	var queries []string
+	server := newStubInfluxServer(t, &queries)
+	defer server.Close()
+	repo := NewInfluxDBRepository(server.URL, "token", "org", "metrics")
+	defer repo.Close()
+	series, err := repo.FindSeries(context.Background(), map[string]string{"container_id": "abc"}, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(series) != 2 || series[0].Name != "container_cpu_usage_percent" || series[0].Unit != "percent" || series[0].Labels["container_name"] != "web" {
+		t.Fatalf("unexpected series %+v", series)
+	}
+	metrics := entities.ContainerMetricsFromSeries(series)
+	if len(metrics) != 2 {
+		t.Fatalf("expected 2 pivoted rows, got %d", len(metrics))
+	}
+	first := metrics[0]
+	if first.ContainerID != "abc" || first.ContainerName != "web" || first.CPUPercent != 42.5 || first.MemoryUsage != 1048576 {
+		t.Fatalf("unexpected first row %+v", first)
+	}
+	if !first.Timestamp.Equal(time.Date(2024, 1, 1, 0, 0, 5, 0, time.UTC)) {
+		t.Fatalf("unexpected timestamp %s", first.Timestamp)
+	}
+	if metrics[1].CPUPercent != 50.25 || metrics[1].MemoryUsage != 2097152 {
+		t.Fatalf("unexpected second row %+v", metrics[1])
+	}
+	if !strings.Contains(queries[0], `r["container_id"] == "abc"`) || strings.Contains(queries[0], "aggregateWindow") {
+		t.Fatalf("unexpected query %s", queries[0])
+	}
+}
+func TestInfluxDBRepositoryQueryLogsFiltersAndRebuildsEntries(t *testing.T) {
+	var queries []string
+	server := newStubFluxServer(t, &queries, stubLogsResponse)
+	defer server.Close()
+	repo := NewInfluxDBRepository(server.URL, "token", "org", "metrics")
+	defer repo.Close()
+	entries, err := repo.QueryLogs(context.Background(), entities.LogQuery{
+		ContainerIDs: []string{"abc", "def"},
+		Levels:       []string{"error"},
+		Start:        time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		End:          time.Date(2024, 1, 1, 1, 0, 0, 0, time.UTC),
+		Contains:     "Timeout",
+		Regex:        `^db\s`,
+		Limit:        11,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+	first := entries[0]
+	if first.ContainerID != "abc" || first.ContainerName != "web" || first.Level != "error" || first.Stream != entities.LogStreamStderr || first.Source != "docker" {
+		t.Fatalf("unexpected first entry %+v", first)
+	}
+	if first.Message != "db timeout" || first.Fields["request_id"] != "r1" || len(first.Labels) != 0 {
+		t.Fatalf("unexpected first entry payload %+v", first)
+	}
+	if entries[1].Message != "started" || entries[1].Fields != nil {
+		t.Fatalf("unexpected second entry %+v", entries[1])
+	}
+	for _, fragment := range []string{
+		`import "strings"`,
+		`range(start: 2024-01-01T00:00:00Z, stop: 2024-01-01T01:00:00Z)`,
+		`(r["container_id"] == "abc") or (r["container_id"] == "def")`,
+		`r["level"] == "error"`,
+		`substr: "timeout"`,
+		`regexp.compile(v: "^db\\s")`,
+		`sort(columns: ["_time"], desc: true)`,
+		`limit(n: 11)`,
+	} {
+		if !strings.Contains(queries[0], fragment) {
+			t.Fatalf("query missing %q:\n%s", fragment, queries[0])
+		}
+	}
+}
+func TestInfluxDBRepositoryEscapesContainerIDAndAggregates(t *testing.T) {
+	var queries []string
+	server := newStubInfluxServer(t, &queries)
+	defer server.Close()
+	repo := NewInfluxDBRepository(server.URL, "token", "org", "metrics")
+	defer repo.Close()
+	hostile := `x") or r["container_id"] != ("${secret}\`
+	if _, err := repo.FindSeriesAggregated(context.Background(), map[string]string{"container_id": hostile}, time.Hour, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	expected := `r["container_id"] == "x\") or r[\"container_id\"] != (\"\${secret}\\")`
+	if !strings.Contains(queries[0], expected) || !strings.Contains(queries[1], expected) {
+		t.Fatalf("container id was not escaped: %s", queries)
+	}
+	if !strings.Contains(queries[0], "aggregateWindow(every: 60s, fn: mean, createEmpty: false)") {
+		t.Fatalf("missing aggregation window: %s", queries[0])
+	}
+	all, err := repo.FindSeries(context.Background(), nil, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rows := entities.ContainerMetricsFromSeries(all); len(rows) != 2 || strings.Contains(queries[2], "container_id") {
+		t.Fatalf("unexpected FindSeries result %d rows, query %s", len(rows), queries[2])
+	}
+}
+type stubWriteServer struct {
+	*httptest.Server
+	status    int
+	failFirst int
+	batches   []int
+	bodies    []string
+	mu        sync.Mutex
+}
+func newStubWriteServer(status int) *stubWriteServer {
+	s := &stubWriteServer{status: status}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		s.mu.Lock()
+		s.batches = append(s.batches, strings.Count(strings.TrimSpace(string(body)), "\n")+1)
+		s.bodies = append(s.bodies, string(body))
+		status := s.status
+		if s.failFirst > 0 {
+			s.failFirst--
+			status = http.StatusServiceUnavailable
+		}
+		s.mu.Unlock()
+		if status != http.StatusNoContent {
+			w.WriteHeader(status)
+			w.Write([]byte(`{"code":"invalid","message":"bad line protocol"}`))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	return s
+}
+func (s *stubWriteServer) written() []int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]int(nil), s.batches...)
+}
+func (s *stubWriteServer) lines() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var lines []string
+	for _, body := range s.bodies {
+		lines = append(lines, strings.Split(strings.TrimSpace(body), "\n")...)
+	}
+	return lines
+}
+func sampleMetrics(i int) []entities.Sample {
+	return []entities.Sample{{
+		Name:      "container_cpu_usage_percent",
+		Labels:    map[string]string{"container_id": "abc", "container_name": "web"},
+		Value:     float64(i),
+		Type:      entities.MetricTypeGauge,
+		Unit:      "percent",
+		Timestamp: time.Unix(1700000000+int64(i), 0),
+	}}
+}
+func waitForBatches(t *testing.T, server *stubWriteServer, n int) []int {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for len(server.written()) < n && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	return server.written()
+}
+func TestInfluxDBRepositoryBatchesWritesAndFlushesOnClose(t *testing.T) {
+	server := newStubWriteServer(http.StatusNoContent)
+	defer server.Close()
+	repo := NewInfluxDBRepositoryWithOptions(server.URL, "token", "org", "metrics", InfluxDBWriteOptions{
+		MaxBatchSize:  3,
+		FlushInterval: time.Hour,
+		BufferSize:    10,
+	})
+	ctx := context.Background()
+	for i := 0; i < 7; i++ {
+		if err := repo.Save(ctx, sampleMetrics(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if batches := waitForBatches(t, server, 2); len(batches) != 2 || batches[0] != 3 || batches[1] != 3 {
+		t.Fatalf("expected two full batches before close, got %v", batches)
+	}
+	repo.Close()
+	if batches := server.written(); len(batches) != 3 || batches[2] != 1 {
+		t.Fatalf("expected remaining point flushed on close, got %v", batches)
+	}
+	if err := repo.Save(ctx, sampleMetrics(8)); !errors.Is(err, ErrRepositoryClosed) {
+		t.Fatalf("expected ErrRepositoryClosed after close, got %v", err)
+	}
+}
+func TestInfluxDBRepositoryFlushesPartialBatchesOnInterval(t *testing.T) {
+	server := newStubWriteServer(http.StatusNoContent)
+	defer server.Close()
+	repo := NewInfluxDBRepositoryWithOptions(server.URL, "token", "org", "metrics", InfluxDBWriteOptions{MaxBatchSize: 100, FlushInterval: 10 * time.Millisecond})
+	defer repo.Close()
+	if err := repo.Save(context.Background(), sampleMetrics(0)); err != nil {
+		t.Fatal(err)
+	}
+	if batches := waitForBatches(t, server, 1); len(batches) != 1 || batches[0] != 1 {
+		t.Fatalf("expected the partial batch to be flushed by the interval, got %v", batches)
+	}
+}
+func TestInfluxDBRepositoryKeepsFailedBatchesForRetry(t *testing.T) {
+	server := newStubWriteServer(http.StatusNoContent)
+	server.failFirst = 1
+	defer server.Close()
+	repo := NewInfluxDBRepositoryWithOptions(server.URL, "token", "org", "metrics", InfluxDBWriteOptions{MaxBatchSize: 1, FlushInterval: 10 * time.Millisecond, RetryAttempts: 1, RetryDelay: time.Millisecond})
+	defer repo.Close()
+	if err := repo.Save(context.Background(), sampleMetrics(0)); err != nil {
+		t.Fatal(err)
+	}
+	waitForBatches(t, server, 2)
+	if lines := server.lines(); len(lines) != 2 || lines[0] != lines[1] {
+		t.Fatalf("expected the failed batch to be written again, got %v", lines)
+	}
+}
+func TestInfluxDBRepositoryRejectsSavesWhenBufferIsFull(t *testing.T) {
+	server := newStubWriteServer(http.StatusBadRequest)
+	defer server.Close()
+	repo := NewInfluxDBRepositoryWithOptions(server.URL, "token", "org", "metrics", InfluxDBWriteOptions{MaxBatchSize: 2, FlushInterval: time.Hour, BufferSize: 3, RetryAttempts: 1, RetryDelay: time.Millisecond})
+	defer repo.Close()
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		if err := repo.Save(ctx, sampleMetrics(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := repo.Save(ctx, sampleMetrics(3)); !errors.Is(err, ErrWriteBufferFull) {
+		t.Fatalf("expected ErrWriteBufferFull while unwritten points fill the buffer, got %v", err)
+	}
+}
+func TestInfluxDBRepositoryWriteErrorsOpenCircuit(t *testing.T) {
+	server := newStubWriteServer(http.StatusBadRequest)
+	defer server.Close()
+	repo := NewInfluxDBRepositoryWithOptions(server.URL, "token", "org", "metrics", InfluxDBWriteOptions{
+		MaxBatchSize:  1,
+		FlushInterval: time.Millisecond,
+		RetryAttempts: 1,
+		RetryDelay:    time.Millisecond,
+	})
+	defer repo.Close()
+	ctx := context.Background()
+	if err := repo.Save(ctx, sampleMetrics(0)); err != nil {
+		t.Fatalf("expected the first save to be queued, got %v", err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if err := repo.Save(ctx, sampleMetrics(1)); errors.Is(err, resilience.ErrCircuitOpen) {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("circuit breaker never opened, state %v", repo.circuitBreaker.GetState())
+}
+func TestInfluxDBRepositoryRetriesTransientLogWrites(t *testing.T) {
+	server := newStubWriteServer(http.StatusNoContent)
+	server.failFirst = 2
+	defer server.Close()
+	repo := NewInfluxDBRepositoryWithOptions(server.URL, "token", "org", "metrics", InfluxDBWriteOptions{RetryAttempts: 3, RetryDelay: time.Millisecond})
+	defer repo.Close()
+	entry := &entities.LogEntry{Timestamp: time.Unix(1700000000, 0), Source: "docker", ContainerID: "abc", Message: "hello"}
+	if err := repo.WriteLogs(context.Background(), []*entities.LogEntry{entry}); err != nil {
+		t.Fatalf("expected WriteLogs to succeed after retries, got %v", err)
+	}
+	if batches := server.written(); len(batches) != 3 {
+		t.Fatalf("expected 3 write attempts before WriteLogs returned, got %v", batches)
+	}
+}
+func TestInfluxDBRepositoryDeleteMetricsCoversBothSchemas(t *testing.T) {
+	var paths []string
+	var predicates []string
+	var stops []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		var body struct {
+			Predicate string `json:"predicate"`
+			Stop      string `json:"stop"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		predicates = append(predicates, body.Predicate)
+		stops = append(stops, body.Stop)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+	repo := NewInfluxDBRepository(server.URL, "token", "org", "metrics")
+	defer repo.Close()
+	before := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	deleted, err := repo.DeleteMetrics(context.Background(), entities.RetentionTierRaw, map[string]string{"container_name": "noisy", entities.MetricNameLabel: "cpu"}, before)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deleted != entities.RetentionDeletedUnknown {
+		t.Fatalf("expected an unknown delete count, got %d", deleted)
+	}
+	if _, err := repo.DeleteMetrics(context.Background(), "1h", nil, before); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range paths {
+		if path != "/api/v2/delete" {
+			t.Fatalf("expected only delete requests, got %v", paths)
+		}
+	}
+	expected := []string{
+		`_measurement="metrics" AND container_name="noisy" AND metric="cpu"`,
+		`_measurement="metrics_rollup" AND tier="1h"`,
+		`_measurement="container_metrics_rollup" AND tier="1h"`,
+	}
+	if strings.Join(predicates, "\n") != strings.Join(expected, "\n") {
+		t.Fatalf("unexpected predicates %q", predicates)
+	}
+	if stop, err := time.Parse(time.RFC3339, stops[0]); err != nil || !stop.Equal(before) {
+		t.Fatalf("expected delete to stop at the cutoff, got %q", stops[0])
+	}
+}
+func TestInfluxDBRepositoryFindSeriesReadsLegacyMeasurement(t *testing.T) {
+	var queries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Query string `json:"query"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		queries = append(queries, body.Query)
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		if strings.Contains(body.Query, `"container_metrics"`) {
+			w.Write([]byte(stubLegacyResponse))
+			return
+		}
+		w.Write([]byte(stubFluxResponse))
+	}))
+	defer server.Close()
+	repo := NewInfluxDBRepository(server.URL, "token", "org", "metrics")
+	defer repo.Close()
+	series, err := repo.FindSeries(context.Background(), map[string]string{"container_id": "abc"}, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows := entities.ContainerMetricsFromSeries(series)
+	if len(rows) != 3 || rows[0].CPUPercent != 12.5 || rows[0].MemoryUsage != 512 || rows[0].ContainerName != "web" || rows[1].CPUPercent != 42.5 {
+		t.Fatalf("expected legacy rows merged before new rows, got %+v", rows)
+	}
+	for _, s := range series {
+		if s.Name == "container_memory_usage_bytes" && s.Unit != "bytes" {
+			t.Fatalf("expected legacy series to carry the descriptor unit, got %+v", s)
+		}
+	}
+	if len(queries) != 2 || !strings.Contains(queries[1], `r["container_id"] == "abc"`) {
+		t.Fatalf("expected a filtered legacy query, got %q", queries)
+	}
+	queries = nil
+	if _, err := repo.FindSeries(context.Background(), map[string]string{entities.MetricNameLabel: "container_memory_usage_percent"}, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if len(queries) != 2 || !strings.Contains(queries[1], `r["_field"] == "memory_percent"`) {
+		t.Fatalf("expected the metric selector to map to the legacy field, got %q", queries)
+	}
+	queries = nil
+	if _, err := repo.FindSeries(context.Background(), map[string]string{entities.MetricNameLabel: "http_requests_total"}, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if len(queries) != 1 {
+		t.Fatalf("expected no legacy query for metrics the old schema never had, got %q", queries)
+	}
+}
+func TestInfluxDBRepositoryWritesAllowedLogFieldsAsTags(t *testing.T) {
+	server := newStubWriteServer(http.StatusNoContent)
+	defer server.Close()
+	repo := NewInfluxDBRepositoryWithOptions(server.URL, "token", "org", "metrics", InfluxDBWriteOptions{LogFieldTags: []string{"user_id", "container_id"}})
+	defer repo.Close()
+	err := repo.WriteLogs(context.Background(), []*entities.LogEntry{{
+		Timestamp:   time.Unix(1700000000, 0),
+		ContainerID: "abc",
+		Message:     "login",
+		Fields:      map[string]string{"user_id": "42", "request_id": "r1", "container_id": "spoofed"},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := server.lines()
+	if len(lines) != 1 {
+		t.Fatalf("expected one point, got %q", lines)
+	}
+	tags, _, _ := strings.Cut(lines[0], " ")
+	if tags != "logs,container_id=abc,user_id=42" {
+		t.Fatalf("expected only allowlisted fields as tags without overriding stream labels, got %q", tags)
+	}
+	if !strings.Contains(lines[0], "request_id") {
+		t.Fatalf("expected all fields to be kept in the fields payload, got %q", lines[0])
+	}
+}
+func TestInfluxDBRepositoryQueryLogsByTaggedField(t *testing.T) {
+	var queries []string
+	server := newStubFluxServer(t, &queries, stubTaggedLogsResponse)
+	defer server.Close()
+	repo := NewInfluxDBRepository(server.URL, "token", "org", "metrics")
+	defer repo.Close()
+	entries, err := repo.QueryLogs(context.Background(), entities.LogQuery{Labels: map[string]string{"user_id": "42"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(queries[0], `r["user_id"] == "42"`) {
+		t.Fatalf("expected field selector in query:\n%s", queries[0])
+	}
+	if len(entries) != 1 || entries[0].Fields["user_id"] != "42" || len(entries[0].Labels) != 0 {
+		t.Fatalf("expected tagged field to come back as a field only, got %+v", entries)
+	}
+}
+func TestInfluxDBRepositoryQueryLogsByParsedField(t *testing.T) {
+	var queries []string
+	server := newStubFluxServer(t, &queries, stubParsedFieldLogsResponse)
+	defer server.Close()
+	repo := NewInfluxDBRepository(server.URL, "token", "org", "metrics")
+	defer repo.Close()
+	entries, err := repo.QueryLogs(context.Background(), entities.LogQuery{Labels: map[string]string{"user_id": "42", "container_id": "abc"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Fields["order_id"] != "o-1" {
+		t.Fatalf("expected only the entry whose parsed field matches, got %+v", entries)
+	}
+	pivot := strings.Index(queries[0], "pivot(")
+	tag := strings.Index(queries[0], `r["container_id"] == "abc"`)
+	field := strings.Index(queries[0], `if exists r["user_id"] then (r["user_id"] == "42") else ((exists r["fields"]) and (r["fields"] =~ regexp.compile(`)
+	if tag < 0 || tag > pivot || field < pivot {
+		t.Fatalf("expected stream labels to be filtered before the pivot and parsed fields after it:\n%s", queries[0])
+	}
+}
+func TestInfluxDBRepositoryReadsLogsInIngestOrder(t *testing.T) {
+	var queries []string
+	server := newStubFluxServer(t, &queries,
+		stubIngestedLogsResponse("first,100", "second,100"),
+		stubIngestedLogsResponse("first,100", "second,100", "third,101"),
+	)
+	defer server.Close()
+	repo := NewInfluxDBRepository(server.URL, "token", "org", "metrics")
+	defer repo.Close()
+	ctx := context.Background()
+	entries, cursor, err := repo.ReadLogsSince(ctx, entities.EncodeLogCursor(time.Unix(0, 50), 0), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(logMessages(entries)) != "[first second]" || cursor != entities.EncodeLogCursor(time.Unix(0, 100), 2) {
+		t.Fatalf("expected a full page ending in a tie to resume after it, got %q %q", logMessages(entries), cursor)
+	}
+	for _, fragment := range []string{`r["ingested"] >= 50`, `sort(columns: ["ingested"], desc: false)`, `limit(n: 2)`} {
+		if !strings.Contains(queries[0], fragment) {
+			t.Fatalf("query missing %q:\n%s", fragment, queries[0])
+		}
+	}
+	entries, cursor, err = repo.ReadLogsSince(ctx, cursor, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(logMessages(entries)) != "[third]" || !strings.Contains(queries[1], `r["ingested"] >= 100`) || !strings.Contains(queries[1], `limit(n: 4)`) {
+		t.Fatalf("expected the tied entries to be skipped, got %q:\n%s", logMessages(entries), queries[1])
+	}
+	next, _, err := entities.DecodeLogCursor(cursor)
+	if err != nil || time.Since(next) < logFeedSettle {
+		t.Fatalf("expected a partial page to move the cursor to the settled edge, got %s %v", next, err)
+	}
+}
+func TestInfluxDBRepositoryStampsLogsWithIngestOrder(t *testing.T) {
+	server := newStubWriteServer(http.StatusNoContent)
+	defer server.Close()
+	repo := NewInfluxDBRepository(server.URL, "token", "org", "metrics")
+	defer repo.Close()
+	at := time.Now()
+	entries := []*entities.LogEntry{{Timestamp: at, Message: "a"}, {Timestamp: at, Message: "b"}}
+	for i := 0; i < 2; i++ {
+		if err := repo.WriteLogs(context.Background(), entries); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(server.lines()) != 4 {
+		t.Fatalf("expected 4 points, got %q", server.lines())
+	}
+	var last int64
+	for _, line := range server.lines() {
+		_, rest, _ := strings.Cut(line, "ingested=")
+		stamp, err := strconv.ParseInt(strings.SplitN(rest, "i", 2)[0], 10, 64)
+		if err != nil || stamp <= last {
+			t.Fatalf("expected strictly increasing ingest stamps, got %q", server.lines())
+		}
+		last = stamp
+	}
+}
+func TestInfluxDBRepositoryNudgesLogsSharingSeriesAndTimestamp(t *testing.T) {
+	server := newStubWriteServer(http.StatusNoContent)
+	defer server.Close()
+	repo := NewInfluxDBRepository(server.URL, "token", "org", "metrics")
+	defer repo.Close()
+	at := time.Unix(1700000000, 0)
+	entry := func(container, message string, timestamp time.Time) *entities.LogEntry {
+		return &entities.LogEntry{Timestamp: timestamp, ContainerID: container, Message: message}
+	}
+	ctx := context.Background()
+	if err := repo.WriteLogs(ctx, []*entities.LogEntry{entry("abc", "one", at), entry("abc", "two", at), entry("def", "other", at)}); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.WriteLogs(ctx, []*entities.LogEntry{entry("abc", "three", at), entry("abc", "late", at.Add(-time.Second))}); err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"1700000000000000000", "1700000000000000001", "1700000000000000000", "1700000000000000002", "1699999999000000000"}
+	lines := server.lines()
+	if len(lines) != len(expected) {
+		t.Fatalf("expected %d points, got %q", len(expected), lines)
+	}
+	for i, line := range lines {
+		if !strings.HasSuffix(line, " "+expected[i]) {
+			t.Fatalf("point %d: expected timestamp %s, got %q", i, expected[i], line)
+		}
+	}
+}
\ No newline at end of file
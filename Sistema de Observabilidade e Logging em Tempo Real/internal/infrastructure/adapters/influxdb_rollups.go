@@ -0,0 +1,88 @@
+package adapters
+import (
+	"context"
+	"time"
+	influxdb2 "github.com/influxdata/influxdb-client-go/v2"
+	"github.com/influxdata/influxdb-client-go/v2/api/write"
+	"observability-system/internal/domain/entities"
+	"observability-system/internal/infrastructure/flux"
+)
+const rollupMeasurement = "metrics_rollup"
+func (r *InfluxDBRepository) SaveRollups(ctx context.Context, rollups []*entities.MetricsRollup) error {
+	points := make([]*write.Point, 0, len(rollups))
+	for _, rollup := range rollups {
+		points = append(points, influxdb2.NewPoint(
+			rollupMeasurement,
+			withLabels(map[string]string{
+				"metric":      rollup.Name,
+				"metric_type": string(rollup.Type),
+				"unit":        rollup.Unit,
+				"tier":        rollup.Tier,
+			}, rollup.Labels),
+			map[string]interface{}{
+				"min":   rollup.Min,
+				"max":   rollup.Max,
+				"mean":  rollup.Mean,
+				"p95":   rollup.P95,
+				"last":  rollup.Last,
+				"count": rollup.Count,
+			},
+			rollup.Timestamp,
+		))
+	}
+	return r.writePoints(ctx, points)
+}
+func (r *InfluxDBRepository) FindRollups(ctx context.Context, tier string, selector map[string]string, duration time.Duration) ([]*entities.MetricsRollup, error) {
+	query := flux.From(r.bucket).
+		Range(duration).
+		Filter(flux.Equal("_measurement", rollupMeasurement)).
+		Filter(flux.Equal("tier", tier)).
+		Filter(selectorPredicates(selector)...).
+		String()
+	var result []*entities.MetricsRollup
+	err := r.circuitBreaker.Execute(ctx, func() error {
+		queryResult, err := r.queryAPI.Query(ctx, query)
+		if err != nil {
+			return err
+		}
+		defer queryResult.Close()
+		pivot := newRollupPivot()
+		for queryResult.Next() {
+			record := queryResult.Record()
+			values := record.Values()
+			name, _ := values["metric"].(string)
+			metricType, _ := values["metric_type"].(string)
+			unit, _ := values["unit"].(string)
+			pivot.add(name, recordLabels(values, "metric", "metric_type", "unit", "tier"), metricType, unit, tier, record.Field(), record.Time(), record.Value())
+		}
+		if err := queryResult.Err(); err != nil {
+			return err
+		}
+		result = pivot.rollups()
+		return nil
+	})
+	return result, err
+}
+func (r *InfluxDBRepository) LastRollupTime(ctx context.Context, tier string, lookback time.Duration) (time.Time, error) {
+	query := flux.From(r.bucket).
+		Range(lookback).
+		Filter(flux.Equal("_measurement", rollupMeasurement)).
+		Filter(flux.Equal("tier", tier), flux.Equal("_field", "count")).
+		Last().
+		String()
+	var last time.Time
+	err := r.circuitBreaker.Execute(ctx, func() error {
+		queryResult, err := r.queryAPI.Query(ctx, query)
+		if err != nil {
+			return err
+		}
+		defer queryResult.Close()
+		for queryResult.Next() {
+			if t := queryResult.Record().Time(); t.After(last) {
+				last = t
+			}
+		}
+		return queryResult.Err()
+	})
+	return last, err
+}
\ No newline at end of file
@@ -0,0 +1,100 @@
+package adapters
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+const maxLogLineBytes = 1 << 20
+type logPosition struct {
+	Timestamp       time.Time
+	Inode           uint64
+	Offset          int64
+	Fingerprint     uint64
+	FingerprintSize int64
+}
+type logCheckpoint struct {
+	path      string
+	positions map[string]logPosition
+	dirty     bool
+	mu        sync.Mutex
+}
+func openLogCheckpoint(path string) (*logCheckpoint, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	c := &logCheckpoint{
+		path:      path,
+		positions: make(map[string]logPosition),
+	}
+	data, err := os.ReadFile(path)
+	if err == nil {
+		if err := json.Unmarshal(data, &c.positions); err != nil {
+			return nil, err
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+	return c, nil
+}
+func (c *logCheckpoint) get(key string) (logPosition, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	position, ok := c.positions[key]
+	return position, ok
+}
+func (c *logCheckpoint) set(key string, position logPosition) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.positions[key] = position
+	c.dirty = true
+}
+func (c *logCheckpoint) delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.positions[key]; ok {
+		delete(c.positions, key)
+		c.dirty = true
+	}
+}
+func (c *logCheckpoint) keys() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	keys := make([]string, 0, len(c.positions))
+	for key := range c.positions {
+		keys = append(keys, key)
+	}
+	return keys
+}
+func (c *logCheckpoint) byInode() map[uint64]logPosition {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	positions := make(map[uint64]logPosition, len(c.positions))
+	for _, position := range c.positions {
+		if position.Inode != 0 {
+			positions[position.Inode] = position
+		}
+	}
+	return positions
+}
+func (c *logCheckpoint) save() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.dirty {
+		return nil
+	}
+	data, err := json.Marshal(c.positions)
+	if err != nil {
+		return err
+	}
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, c.path); err != nil {
+		return err
+	}
+	c.dirty = false
+	return nil
+}
\ No newline at end of file
@@ -0,0 +1,23 @@
+package adapters
+import (
+	"context"
+	"observability-system/internal/domain/entities"
+	"observability-system/internal/domain/ports"
+)
+type MultiLogSink struct {
+	sinks []ports.LogSink
+}
+func NewMultiLogSink(sinks ...ports.LogSink) *MultiLogSink {
+	return &MultiLogSink{
+		sinks: sinks,
+	}
+}
+func (m *MultiLogSink) WriteLogs(ctx context.Context, entries []*entities.LogEntry) error {
+	var firstErr error
+	for _, sink := range m.sinks {
+		if err := sink.WriteLogs(ctx, entries); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
\ No newline at end of file
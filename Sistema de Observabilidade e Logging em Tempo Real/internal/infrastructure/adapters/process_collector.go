@@ -26,6 +26,13 @@ func NewProcessCollectorAdapter() (*ProcessCollectorAdapter, error) {
 func (d *ProcessCollectorAdapter) ListContainers(ctx context.Context) ([]string, error) {
 	return d.processes, nil
 }
+func (d *ProcessCollectorAdapter) CollectSamples(ctx context.Context, processName string) ([]entities.Sample, error) {
+	metrics, err := d.CollectMetrics(ctx, processName)
+	if err != nil || metrics == nil {
+		return nil, err
+	}
+	return metrics.Samples(), nil
+}
 func (d *ProcessCollectorAdapter) CollectMetrics(ctx context.Context, processName string) (*entities.ContainerMetrics, error) {
 	baseLoad := rand.Float64() * 30
 	spike := 0.0
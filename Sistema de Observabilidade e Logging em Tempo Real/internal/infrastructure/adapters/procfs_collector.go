@@ -0,0 +1,270 @@
+package adapters
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"observability-system/internal/domain/entities"
+)
+type ProcFSCollectorAdapter struct {
+	procRoot string
+	matches  []string
+	samples  map[string]procCPUSample
+	mu       sync.Mutex
+}
+type procCPUSample struct {
+	processTicks uint64
+	totalTicks   uint64
+}
+func NewProcFSCollectorAdapter(procRoot string, matches []string) (*ProcFSCollectorAdapter, error) {
+	if procRoot == "" {
+		procRoot = "/proc"
+	}
+	if _, err := os.Stat(filepath.Join(procRoot, "stat")); err != nil {
+		return nil, fmt.Errorf("procfs not available at %s: %w", procRoot, err)
+	}
+	return &ProcFSCollectorAdapter{
+		procRoot: procRoot,
+		matches:  matches,
+		samples:  make(map[string]procCPUSample),
+	}, nil
+}
+func (c *ProcFSCollectorAdapter) ListContainers(ctx context.Context) ([]string, error) {
+	entries, err := os.ReadDir(c.procRoot)
+	if err != nil {
+		return nil, err
+	}
+	var pids []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		if _, err := strconv.Atoi(entry.Name()); err != nil {
+			continue
+		}
+		name, err := c.readComm(entry.Name())
+		if err != nil {
+			continue
+		}
+		if c.matchesProcess(name, c.readCmdline(entry.Name())) {
+			pids = append(pids, entry.Name())
+		}
+	}
+	c.forgetExited(pids)
+	return pids, nil
+}
+func (c *ProcFSCollectorAdapter) CollectSamples(ctx context.Context, pid string) ([]entities.Sample, error) {
+	metrics, err := c.CollectMetrics(ctx, pid)
+	if err != nil || metrics == nil {
+		return nil, err
+	}
+	return metrics.Samples(), nil
+}
+func (c *ProcFSCollectorAdapter) CollectMetrics(ctx context.Context, pid string) (*entities.ContainerMetrics, error) {
+	name, err := c.readComm(pid)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	processTicks, err := c.readProcessTicks(pid)
+	if err != nil {
+		return nil, err
+	}
+	totalTicks, cpuCount, err := c.readTotalTicks()
+	if err != nil {
+		return nil, err
+	}
+	memoryUsage, err := c.readRSS(pid)
+	if err != nil {
+		return nil, err
+	}
+	memoryLimit, err := c.readMemTotal()
+	if err != nil {
+		return nil, err
+	}
+	readBytes, writeBytes := c.readIO(pid)
+	var memPercent float64
+	if memoryLimit > 0 {
+		memPercent = float64(memoryUsage) / float64(memoryLimit) * 100.0
+	}
+	return &entities.ContainerMetrics{
+		ContainerID:   fmt.Sprintf("process-%s", pid),
+		ContainerName: name,
+		CPUPercent:    c.cpuPercent(pid, processTicks, totalTicks, cpuCount),
+		MemoryUsage:   memoryUsage,
+		MemoryLimit:   memoryLimit,
+		MemoryPercent: memPercent,
+		DiskRead:      readBytes,
+		DiskWrite:     writeBytes,
+		Timestamp:     time.Now(),
+	}, nil
+}
+func (c *ProcFSCollectorAdapter) Close() error {
+	return nil
+}
+func (c *ProcFSCollectorAdapter) matchesProcess(name, cmdline string) bool {
+	if len(c.matches) == 0 {
+		return true
+	}
+	for _, match := range c.matches {
+		if match == name || strings.Contains(cmdline, match) {
+			return true
+		}
+	}
+	return false
+}
+func (c *ProcFSCollectorAdapter) cpuPercent(pid string, processTicks, totalTicks uint64, cpuCount int) float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	previous, ok := c.samples[pid]
+	c.samples[pid] = procCPUSample{processTicks: processTicks, totalTicks: totalTicks}
+	if !ok || totalTicks <= previous.totalTicks || processTicks < previous.processTicks {
+		return 0.0
+	}
+	processDelta := float64(processTicks - previous.processTicks)
+	totalDelta := float64(totalTicks - previous.totalTicks)
+	return processDelta / totalDelta * float64(cpuCount) * 100.0
+}
+func (c *ProcFSCollectorAdapter) forgetExited(pids []string) {
+	alive := make(map[string]bool, len(pids))
+	for _, pid := range pids {
+		alive[pid] = true
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for pid := range c.samples {
+		if !alive[pid] {
+			delete(c.samples, pid)
+		}
+	}
+}
+func (c *ProcFSCollectorAdapter) readComm(pid string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(c.procRoot, pid, "comm"))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+func (c *ProcFSCollectorAdapter) readCmdline(pid string) string {
+	data, err := os.ReadFile(filepath.Join(c.procRoot, pid, "cmdline"))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(strings.ReplaceAll(string(data), "\x00", " "))
+}
+func (c *ProcFSCollectorAdapter) readProcessTicks(pid string) (uint64, error) {
+	data, err := os.ReadFile(filepath.Join(c.procRoot, pid, "stat"))
+	if err != nil {
+		return 0, err
+	}
+	stat := string(data)
+	end := strings.LastIndex(stat, ")")
+	if end < 0 {
+		return 0, fmt.Errorf("malformed stat for pid %s", pid)
+	}
+	fields := strings.Fields(stat[end+1:])
+	if len(fields) < 13 {
+		return 0, fmt.Errorf("malformed stat for pid %s", pid)
+	}
+	utime, err := strconv.ParseUint(fields[11], 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	stime, err := strconv.ParseUint(fields[12], 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	return utime + stime, nil
+}
+func (c *ProcFSCollectorAdapter) readTotalTicks() (uint64, int, error) {
+	file, err := os.Open(filepath.Join(c.procRoot, "stat"))
+	if err != nil {
+		return 0, 0, err
+	}
+	defer file.Close()
+	var total uint64
+	cpuCount := 0
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || !strings.HasPrefix(fields[0], "cpu") {
+			continue
+		}
+		if fields[0] != "cpu" {
+			cpuCount++
+			continue
+		}
+		for _, field := range fields[1:] {
+			ticks, err := strconv.ParseUint(field, 10, 64)
+			if err != nil {
+				return 0, 0, err
+			}
+			total += ticks
+		}
+	}
+	if cpuCount == 0 {
+		cpuCount = 1
+	}
+	return total, cpuCount, scanner.Err()
+}
+func (c *ProcFSCollectorAdapter) readRSS(pid string) (uint64, error) {
+	values, err := readKeyValueFile(filepath.Join(c.procRoot, pid, "status"))
+	if err != nil {
+		return 0, err
+	}
+	return parseKilobytes(values["VmRSS"]), nil
+}
+func (c *ProcFSCollectorAdapter) readMemTotal() (uint64, error) {
+	values, err := readKeyValueFile(filepath.Join(c.procRoot, "meminfo"))
+	if err != nil {
+		return 0, err
+	}
+	return parseKilobytes(values["MemTotal"]), nil
+}
+func (c *ProcFSCollectorAdapter) readIO(pid string) (uint64, uint64) {
+	values, err := readKeyValueFile(filepath.Join(c.procRoot, pid, "io"))
+	if err != nil {
+		return 0, 0
+	}
+	readBytes, _ := strconv.ParseUint(values["read_bytes"], 10, 64)
+	writeBytes, _ := strconv.ParseUint(values["write_bytes"], 10, 64)
+	return readBytes, writeBytes
+}
+func readKeyValueFile(path string) (map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	values := make(map[string]string)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+		values[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	return values, scanner.Err()
+}
+func parseKilobytes(value string) uint64 {
+	fields := strings.Fields(value)
+	if len(fields) == 0 {
+		return 0
+	}
+	n, err := strconv.ParseUint(fields[0], 10, 64)
+	if err != nil {
+		return 0
+	}
+	if len(fields) > 1 && strings.EqualFold(fields[1], "kB") {
+		return n * 1024
+	}
+	return n
+}
\ No newline at end of file
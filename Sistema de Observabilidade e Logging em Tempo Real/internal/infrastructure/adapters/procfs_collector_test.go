@@ -0,0 +1,96 @@
+package adapters
+import (
+	"context"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+func writeProcFixture(t *testing.T, root, path, content string) {
+	t.Helper()
+	full := filepath.Join(root, path)
+	if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(full, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+func newProcFixture(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	writeProcFixture(t, root, "stat", "cpu  100 0 100 800 0 0 0 0 0 0\ncpu0 50 0 50 400 0 0 0 0 0 0\ncpu1 50 0 50 400 0 0 0 0 0 0\n")
+	writeProcFixture(t, root, "meminfo", "MemTotal:        1000 kB\nMemFree:          500 kB\n")
+	writeProcFixture(t, root, "42/comm", "redis-server\n")
+	writeProcFixture(t, root, "42/cmdline", "/usr/bin/redis-server\x00--port\x006379\x00")
+	writeProcFixture(t, root, "42/stat", "42 (redis server) S 1 42 42 0 -1 4194560 100 0 0 0 10 10 0 0 20 0 4 0 100 0 0\n")
+	writeProcFixture(t, root, "42/status", "Name:\tredis-server\nVmRSS:\t     250 kB\n")
+	writeProcFixture(t, root, "42/io", "rchar: 10\nwchar: 20\nread_bytes: 4096\nwrite_bytes: 8192\n")
+	writeProcFixture(t, root, "77/comm", "bash\n")
+	writeProcFixture(t, root, "77/cmdline", "bash\x00")
+	writeProcFixture(t, root, "self/comm", "bash\n")
+	return root
+}
+func TestProcFSCollectorListsMatchingProcesses(t *testing.T) {
+	root := newProcFixture(t)
+	collector, err := NewProcFSCollectorAdapter(root, []string{"redis-server"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pids, err := collector.ListContainers(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pids) != 1 || pids[0] != "42" {
+		t.Fatalf("expected [42], got %v", pids)
+	}
+	collector.matches = []string{"--port"}
+	pids, _ = collector.ListContainers(context.Background())
+	if len(pids) != 1 || pids[0] != "42" {
+		t.Fatalf("expected cmdline match [42], got %v", pids)
+	}
+}
+func TestProcFSCollectorComputesCPUFromDeltas(t *testing.T) {
+	root := newProcFixture(t)
+	collector, err := NewProcFSCollectorAdapter(root, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	first, err := collector.CollectMetrics(ctx, "42")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.CPUPercent != 0 {
+		t.Fatalf("expected 0%% CPU on first sample, got %.2f", first.CPUPercent)
+	}
+	if first.ContainerName != "redis-server" || first.ContainerID != "process-42" {
+		t.Fatalf("unexpected identity %s/%s", first.ContainerID, first.ContainerName)
+	}
+	if first.MemoryUsage != 250*1024 || first.MemoryLimit != 1000*1024 || first.MemoryPercent != 25 {
+		t.Fatalf("unexpected memory %d/%d %.2f", first.MemoryUsage, first.MemoryLimit, first.MemoryPercent)
+	}
+	if first.DiskRead != 4096 || first.DiskWrite != 8192 {
+		t.Fatalf("unexpected io %d/%d", first.DiskRead, first.DiskWrite)
+	}
+	writeProcFixture(t, root, "stat", "cpu  150 0 150 900 0 0 0 0 0 0\ncpu0 75 0 75 450 0 0 0 0 0 0\ncpu1 75 0 75 450 0 0 0 0 0 0\n")
+	writeProcFixture(t, root, "42/stat", "42 (redis server) S 1 42 42 0 -1 4194560 100 0 0 0 30 10 0 0 20 0 4 0 100 0 0\n")
+	second, err := collector.CollectMetrics(ctx, "42")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(second.CPUPercent-20.0) > 0.001 {
+		t.Fatalf("expected 20%% CPU, got %.4f", second.CPUPercent)
+	}
+}
+func TestProcFSCollectorIgnoresExitedProcess(t *testing.T) {
+	root := newProcFixture(t)
+	collector, err := NewProcFSCollectorAdapter(root, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	metrics, err := collector.CollectMetrics(context.Background(), "999")
+	if err != nil || metrics != nil {
+		t.Fatalf("expected nil metrics for missing pid, got %v, %v", metrics, err)
+	}
+}
\ No newline at end of file
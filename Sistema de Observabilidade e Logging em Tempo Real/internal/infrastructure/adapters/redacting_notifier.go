@@ -0,0 +1,21 @@
+package adapters
+import (
+	"context"
+	"observability-system/internal/domain/entities"
+	"observability-system/internal/domain/ports"
+)
+type RedactingNotifier struct {
+	notifier ports.Notifier
+	redactor ports.Redactor
+}
+func NewRedactingNotifier(notifier ports.Notifier, redactor ports.Redactor) *RedactingNotifier {
+	return &RedactingNotifier{
+		notifier: notifier,
+		redactor: redactor,
+	}
+}
+func (n *RedactingNotifier) Notify(ctx context.Context, alert *entities.Alert) error {
+	redacted := *alert
+	n.redactor.RedactAlert(&redacted)
+	return n.notifier.Notify(ctx, &redacted)
+}
\ No newline at end of file
@@ -1,23 +1,32 @@
 package adapters
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 	"github.com/redis/go-redis/v9"
 	"observability-system/internal/domain/entities"
 	"observability-system/internal/infrastructure/resilience"
 )
+const alertStateTTL = 24 * time.Hour
 type RedisAlertRepository struct {
 	client         *redis.Client
+	retention      time.Duration
 	circuitBreaker *resilience.CircuitBreaker
 	retryPolicy    *resilience.RetryPolicy
 }
 func NewRedisAlertRepository(addr string) *RedisAlertRepository {
+	return NewRedisAlertRepositoryWithRetention(addr, 24*time.Hour)
+}
+func NewRedisAlertRepositoryWithRetention(addr string, retention time.Duration) *RedisAlertRepository {
 	client := redis.NewClient(&redis.Options{
 		Addr: addr,
 	})
 	return &RedisAlertRepository{
 		client:         client,
+		retention:      retention,
 		circuitBreaker: resilience.NewCircuitBreaker(5, 30*time.Second),
 		retryPolicy:    resilience.NewRetryPolicy(3, 500*time.Millisecond, 2.0),
 	}
@@ -26,7 +35,10 @@ func (r *RedisAlertRepository) Save(ctx context.Context, alert *entities.Alert)
 	return r.circuitBreaker.Execute(ctx, func() error {
 		return r.retryPolicy.Execute(ctx, func() error {
 			key := fmt.Sprintf("alert:%s:%s:%d", alert.ContainerID, alert.Type, alert.Timestamp.Unix())
-			return r.client.Set(ctx, key, alert.Message, 24*time.Hour).Err()
+			if alert.Resolved() {
+				key = fmt.Sprintf("alert:%s:%s:resolved:%d", alert.ContainerID, alert.Type, alert.Timestamp.Unix())
+			}
+			return r.client.Set(ctx, key, alert.Message, r.retention).Err()
 		})
 	})
 }
@@ -48,6 +60,122 @@ func (r *RedisAlertRepository) SetCooldown(ctx context.Context, containerID stri
 		})
 	})
 }
+func (r *RedisAlertRepository) ListAlertStates(ctx context.Context) ([]*entities.AlertInstance, error) {
+	var instances []*entities.AlertInstance
+	err := r.circuitBreaker.Execute(ctx, func() error {
+		instances = nil
+		iter := r.client.Scan(ctx, 0, "alertstate:*", 500).Iterator()
+		for iter.Next(ctx) {
+			data, err := r.client.Get(ctx, iter.Val()).Bytes()
+			if err == redis.Nil {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			var instance entities.AlertInstance
+			if err := json.Unmarshal(data, &instance); err != nil {
+				continue
+			}
+			instances = append(instances, &instance)
+		}
+		return iter.Err()
+	})
+	return instances, err
+}
+func (r *RedisAlertRepository) SaveAlertStates(ctx context.Context, saved []*entities.AlertInstance, deleted []string) error {
+	if len(saved) == 0 && len(deleted) == 0 {
+		return nil
+	}
+	encoded := make([][]byte, len(saved))
+	for i, instance := range saved {
+		data, err := json.Marshal(instance)
+		if err != nil {
+			return err
+		}
+		encoded[i] = data
+	}
+	return r.circuitBreaker.Execute(ctx, func() error {
+		return r.retryPolicy.Execute(ctx, func() error {
+			_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+				for i, instance := range saved {
+					pipe.Set(ctx, "alertstate:"+instance.Fingerprint, encoded[i], alertStateTTL)
+				}
+				for _, fingerprint := range deleted {
+					pipe.Del(ctx, "alertstate:"+fingerprint)
+				}
+				return nil
+			})
+			return err
+		})
+	})
+}
+func (r *RedisAlertRepository) SaveSilence(ctx context.Context, silence *entities.Silence) error {
+	data, err := json.Marshal(silence)
+	if err != nil {
+		return err
+	}
+	ttl := time.Until(silence.EndsAt) + silenceRetention
+	if ttl <= 0 {
+		return nil
+	}
+	return r.circuitBreaker.Execute(ctx, func() error {
+		return r.retryPolicy.Execute(ctx, func() error {
+			return r.client.Set(ctx, "silence:"+silence.ID, data, ttl).Err()
+		})
+	})
+}
+func (r *RedisAlertRepository) ListSilences(ctx context.Context) ([]*entities.Silence, error) {
+	var silences []*entities.Silence
+	err := r.circuitBreaker.Execute(ctx, func() error {
+		silences = nil
+		iter := r.client.Scan(ctx, 0, "silence:*", 500).Iterator()
+		for iter.Next(ctx) {
+			data, err := r.client.Get(ctx, iter.Val()).Bytes()
+			if err == redis.Nil {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			var silence entities.Silence
+			if err := json.Unmarshal(data, &silence); err != nil {
+				continue
+			}
+			silences = append(silences, &silence)
+		}
+		return iter.Err()
+	})
+	return silences, err
+}
+func (r *RedisAlertRepository) DeleteAlertsBefore(ctx context.Context, before time.Time) (int64, error) {
+	var deleted int64
+	err := r.circuitBreaker.Execute(ctx, func() error {
+		iter := r.client.Scan(ctx, 0, "alert:*", 500).Iterator()
+		var expired []string
+		for iter.Next(ctx) {
+			key := iter.Val()
+			seconds, err := strconv.ParseInt(key[strings.LastIndex(key, ":")+1:], 10, 64)
+			if err != nil || !time.Unix(seconds, 0).Before(before) {
+				continue
+			}
+			expired = append(expired, key)
+		}
+		if err := iter.Err(); err != nil {
+			return err
+		}
+		for start := 0; start < len(expired); start += 500 {
+			end := min(start+500, len(expired))
+			n, err := r.client.Del(ctx, expired[start:end]...).Result()
+			deleted += n
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return deleted, err
+}
 func (r *RedisAlertRepository) Close() error {
 	return r.client.Close()
 }
\ No newline at end of file
@@ -0,0 +1,155 @@
+package adapters
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"observability-system/internal/domain/entities"
+)
+type fakeRedis struct {
+	listener net.Listener
+	mu       sync.Mutex
+	values   map[string]string
+	ttls     map[string]time.Duration
+}
+func newFakeRedis(t *testing.T) *fakeRedis {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := &fakeRedis{listener: listener, values: make(map[string]string), ttls: make(map[string]time.Duration)}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go server.serve(conn)
+		}
+	}()
+	return server
+}
+func (s *fakeRedis) serve(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	for {
+		args, err := readRESPCommand(reader)
+		if err != nil {
+			return
+		}
+		if _, err := io.WriteString(conn, s.handle(args)); err != nil {
+			return
+		}
+	}
+}
+func (s *fakeRedis) handle(args []string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch strings.ToUpper(args[0]) {
+	case "SET":
+		s.values[args[1]] = args[2]
+		if len(args) == 5 {
+			amount, _ := strconv.Atoi(args[4])
+			unit := time.Second
+			if strings.EqualFold(args[3], "px") {
+				unit = time.Millisecond
+			}
+			s.ttls[args[1]] = time.Duration(amount) * unit
+		}
+		return "+OK\r\n"
+	case "GET":
+		value, ok := s.values[args[1]]
+		if !ok {
+			return "$-1\r\n"
+		}
+		return fmt.Sprintf("$%d\r\n%s\r\n", len(value), value)
+	case "SCAN":
+		var keys []string
+		for key := range s.values {
+			if matched, _ := path.Match(args[3], key); matched {
+				keys = append(keys, fmt.Sprintf("$%d\r\n%s\r\n", len(key), key))
+			}
+		}
+		return fmt.Sprintf("*2\r\n$1\r\n0\r\n*%d\r\n%s", len(keys), strings.Join(keys, ""))
+	}
+	return "-ERR unknown command\r\n"
+}
+func readRESPCommand(reader *bufio.Reader) ([]string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	count, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "*")))
+	if err != nil {
+		return nil, err
+	}
+	args := make([]string, count)
+	for i := range args {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		size, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "$")))
+		if err != nil {
+			return nil, err
+		}
+		data := make([]byte, size+2)
+		if _, err := io.ReadFull(reader, data); err != nil {
+			return nil, err
+		}
+		args[i] = string(data[:size])
+	}
+	return args, nil
+}
+func TestRedisSilencesRoundTrip(t *testing.T) {
+	server := newFakeRedis(t)
+	repo := NewRedisAlertRepository(server.listener.Addr().String())
+	defer repo.Close()
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Second)
+	active := &entities.Silence{ID: "active", Matchers: map[string]string{"container_name": "api-*"}, StartsAt: now, EndsAt: now.Add(time.Hour), CreatedBy: "ops", Comment: "deploy"}
+	if err := repo.SaveSilence(ctx, active); err != nil {
+		t.Fatal(err)
+	}
+	stale := &entities.Silence{ID: "stale", Matchers: map[string]string{"alertname": "CPU"}, StartsAt: now.Add(-50 * time.Hour), EndsAt: now.Add(-48 * time.Hour), CreatedBy: "ops"}
+	if err := repo.SaveSilence(ctx, stale); err != nil {
+		t.Fatal(err)
+	}
+	server.mu.Lock()
+	server.values["silence:corrupt"] = "{"
+	ttl := server.ttls["silence:active"]
+	server.mu.Unlock()
+	if ttl < silenceRetention || ttl > time.Hour+silenceRetention {
+		t.Fatalf("expected the silence to be kept until its end plus %s, got ttl %s", silenceRetention, ttl)
+	}
+	silences, err := repo.ListSilences(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(silences) != 1 {
+		t.Fatalf("expected only the active silence to be listed, got %d", len(silences))
+	}
+	got := silences[0]
+	if got.ID != "active" || got.Matchers["container_name"] != "api-*" || got.CreatedBy != "ops" || got.Comment != "deploy" || !got.EndsAt.Equal(active.EndsAt) {
+		t.Fatalf("unexpected silence %+v", got)
+	}
+	got.EndsAt = now
+	if err := repo.SaveSilence(ctx, got); err != nil {
+		t.Fatal(err)
+	}
+	silences, err = repo.ListSilences(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(silences) != 1 || !silences[0].EndsAt.Equal(now) {
+		t.Fatalf("expected the expired silence to overwrite the original, got %+v", silences)
+	}
+}
\ No newline at end of file
@@ -0,0 +1,28 @@
+package adapters
+import (
+	"context"
+	"log"
+	"observability-system/internal/domain/entities"
+	"observability-system/internal/domain/ports"
+)
+type SilencingNotifier struct {
+	notifier ports.Notifier
+	silencer ports.AlertSilencer
+}
+func NewSilencingNotifier(notifier ports.Notifier, silencer ports.AlertSilencer) *SilencingNotifier {
+	return &SilencingNotifier{
+		notifier: notifier,
+		silencer: silencer,
+	}
+}
+func (n *SilencingNotifier) Notify(ctx context.Context, alert *entities.Alert) error {
+	silenced, err := n.silencer.IsSilenced(ctx, alert)
+	if err != nil {
+		log.Printf("Failed to check silences, notifying anyway: %v", err)
+	}
+	if silenced {
+		log.Printf("Silenced %s alert for %s", alert.Type, alert.ContainerID)
+		return ports.ErrSilenced
+	}
+	return n.notifier.Notify(ctx, alert)
+}
\ No newline at end of file
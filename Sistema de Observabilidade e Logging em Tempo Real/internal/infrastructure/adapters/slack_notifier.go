@@ -36,12 +36,17 @@ func (n *SlackNotifier) Notify(ctx context.Context, alert *entities.Alert) error
 	if alert.Value > alert.Threshold*1.2 {
 		color = "danger"
 	}
+	text, title := "🚨 *Container Alert*", fmt.Sprintf("%s - %s Alert", alert.ContainerName, alert.Type)
+	if alert.Resolved() {
+		color = "good"
+		text, title = "✅ *Container Alert Resolved*", fmt.Sprintf("%s - %s Resolved", alert.ContainerName, alert.Type)
+	}
 	msg := slackMessage{
-		Text: "🚨 *Container Alert*",
+		Text: text,
 		Attachments: []slackAttachment{
 			{
 				Color:  color,
-				Title:  fmt.Sprintf("%s - %s Alert", alert.ContainerName, alert.Type),
+				Title:  title,
 				Text:   alert.Message,
 				Footer: "Observability System",
 				Ts:     alert.Timestamp.Unix(),
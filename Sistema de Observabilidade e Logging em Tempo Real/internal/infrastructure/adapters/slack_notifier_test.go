@@ -0,0 +1,33 @@
+package adapters
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"observability-system/internal/domain/entities"
+)
+func TestSlackNotifierStylesResolvedAlerts(t *testing.T) {
+	var got slackMessage
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer server.Close()
+	notifier := NewSlackNotifier(server.URL)
+	alert := entities.NewAlert("abc", "web", entities.AlertTypeCPU, 95, 90)
+	if err := notifier.Notify(context.Background(), alert); err != nil {
+		t.Fatal(err)
+	}
+	if got.Attachments[0].Color != "warning" || got.Attachments[0].Title != "web - CPU Alert" {
+		t.Fatalf("unexpected firing message %+v", got)
+	}
+	alert.Status = entities.AlertStatusResolved
+	if err := notifier.Notify(context.Background(), alert); err != nil {
+		t.Fatal(err)
+	}
+	if got.Attachments[0].Color != "good" || got.Attachments[0].Title != "web - CPU Resolved" {
+		t.Fatalf("unexpected resolved message %+v", got)
+	}
+}
\ No newline at end of file
@@ -0,0 +1,186 @@
+package adapters
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+	"observability-system/internal/domain/entities"
+	"observability-system/internal/domain/ports"
+	"observability-system/internal/infrastructure/wal"
+)
+var ErrRetentionUnsupported = errors.New("metrics backend does not support retention deletes")
+type SpoolingMetricsRepository struct {
+	backend        ports.MetricsRepository
+	spool          *wal.Log
+	replayInterval time.Duration
+	done           chan struct{}
+	wg             sync.WaitGroup
+	closeOnce      sync.Once
+}
+func NewSpoolingMetricsRepository(backend ports.MetricsRepository, options wal.Options, replayInterval time.Duration) (*SpoolingMetricsRepository, error) {
+	spool, err := wal.Open(options)
+	if err != nil {
+		return nil, err
+	}
+	if replayInterval <= 0 {
+		replayInterval = 10 * time.Second
+	}
+	r := &SpoolingMetricsRepository{
+		backend:        backend,
+		spool:          spool,
+		replayInterval: replayInterval,
+		done:           make(chan struct{}),
+	}
+	r.wg.Add(1)
+	go r.replayLoop()
+	return r, nil
+}
+func (r *SpoolingMetricsRepository) Save(ctx context.Context, samples []entities.Sample) error {
+	if !r.spool.Empty() {
+		return r.append(samples)
+	}
+	if err := r.backend.Save(ctx, samples); err != nil {
+		log.Printf("Metrics store unavailable, spooling %d samples to disk: %v", len(samples), err)
+		return r.append(samples)
+	}
+	return nil
+}
+func (r *SpoolingMetricsRepository) FindSeries(ctx context.Context, selector map[string]string, duration time.Duration) ([]*entities.Series, error) {
+	return r.backend.FindSeries(ctx, selector, duration)
+}
+func (r *SpoolingMetricsRepository) DeleteMetrics(ctx context.Context, tier string, selector map[string]string, before time.Time) (int64, error) {
+	if tier == entities.RetentionTierRaw && !r.spool.Empty() {
+		if err := r.dropSpooled(selector, before); err != nil {
+			return 0, fmt.Errorf("failed to drop expired spooled metrics: %w", err)
+		}
+	}
+	store, ok := r.backend.(ports.MetricsRetentionStore)
+	if !ok {
+		return 0, ErrRetentionUnsupported
+	}
+	return store.DeleteMetrics(ctx, tier, selector, before)
+}
+func (r *SpoolingMetricsRepository) PendingBytes() int64 {
+	return r.spool.Size()
+}
+func (r *SpoolingMetricsRepository) Close() error {
+	var err error
+	r.closeOnce.Do(func() {
+		close(r.done)
+		r.wg.Wait()
+		err = r.spool.Close()
+		if backendErr := r.backend.Close(); err == nil {
+			err = backendErr
+		}
+	})
+	return err
+}
+func (r *SpoolingMetricsRepository) append(samples []entities.Sample) error {
+	record, err := json.Marshal(samples)
+	if err != nil {
+		return err
+	}
+	return r.spool.Append(record)
+}
+func (r *SpoolingMetricsRepository) dropSpooled(selector map[string]string, before time.Time) error {
+	rule := entities.RetentionRule{Tier: entities.RetentionTierRaw, Selector: selector}
+	dropped := 0
+	err := r.spool.Rewrite(func(record []byte) []byte {
+		samples, err := decodeSpooledSamples(record)
+		if err != nil {
+			return record
+		}
+		kept := samples[:0]
+		for _, sample := range samples {
+			labels := make(map[string]string, len(sample.Labels)+1)
+			for name, value := range sample.Labels {
+				labels[name] = value
+			}
+			labels["metric"] = sample.Name
+			if sample.Timestamp.Before(before) && rule.Matches(labels) {
+				dropped++
+				continue
+			}
+			kept = append(kept, sample)
+		}
+		if len(kept) == 0 {
+			return nil
+		}
+		if len(kept) == len(samples) {
+			return record
+		}
+		encoded, err := json.Marshal(kept)
+		if err != nil {
+			return record
+		}
+		return encoded
+	})
+	if dropped > 0 {
+		log.Printf("Dropped %d spooled samples older than %s", dropped, before.Format(time.RFC3339))
+	}
+	return err
+}
+func (r *SpoolingMetricsRepository) replayLoop() {
+	defer r.wg.Done()
+	ticker := time.NewTicker(r.replayInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.done:
+			return
+		case <-ticker.C:
+			if r.spool.Empty() {
+				continue
+			}
+			if err := r.replay(); err != nil {
+				log.Printf("Metrics store still unavailable, %d bytes spooled: %v", r.spool.Size(), err)
+			}
+		}
+	}
+}
+func (r *SpoolingMetricsRepository) replay() error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-r.done:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	replayed := 0
+	err := r.spool.Replay(func(record []byte) error {
+		samples, err := decodeSpooledSamples(record)
+		if err != nil {
+			log.Printf("Discarding unreadable spooled metrics record: %v", err)
+			return nil
+		}
+		if err := r.backend.Save(ctx, samples); err != nil {
+			return err
+		}
+		replayed++
+		return nil
+	})
+	if replayed > 0 {
+		log.Printf("Replayed %d spooled metrics to the metrics store", replayed)
+	}
+	return err
+}
+func decodeSpooledSamples(record []byte) ([]entities.Sample, error) {
+	if trimmed := bytes.TrimSpace(record); len(trimmed) > 0 && trimmed[0] == '{' {
+		var metrics entities.ContainerMetrics
+		if err := json.Unmarshal(trimmed, &metrics); err != nil {
+			return nil, err
+		}
+		return metrics.Samples(), nil
+	}
+	var samples []entities.Sample
+	if err := json.Unmarshal(record, &samples); err != nil {
+		return nil, err
+	}
+	return samples, nil
+}
\ No newline at end of file
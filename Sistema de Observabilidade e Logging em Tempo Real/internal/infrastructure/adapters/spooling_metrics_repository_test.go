@@ -0,0 +1,147 @@
+package adapters
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+	"observability-system/internal/domain/entities"
+	"observability-system/internal/infrastructure/wal"
+)
+type flakyMetricsRepository struct {
+	mu       sync.Mutex
+	failures map[string]bool
+	saved    []string
+}
+func (r *flakyMetricsRepository) Save(ctx context.Context, samples []entities.Sample) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, sample := range samples {
+		if r.failures[sample.Name] || r.failures["*"] {
+			return errors.New("metrics store unavailable")
+		}
+	}
+	for _, sample := range samples {
+		r.saved = append(r.saved, sample.Name)
+	}
+	return nil
+}
+func (r *flakyMetricsRepository) FindSeries(ctx context.Context, selector map[string]string, duration time.Duration) ([]*entities.Series, error) {
+	return nil, nil
+}
+func (r *flakyMetricsRepository) Close() error {
+	return nil
+}
+func (r *flakyMetricsRepository) fail(names ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.failures = map[string]bool{}
+	for _, name := range names {
+		r.failures[name] = true
+	}
+}
+func (r *flakyMetricsRepository) savedNames() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.saved...)
+}
+func TestSpoolingMetricsRepositoryReplaysInOrderAfterOutage(t *testing.T) {
+	ctx := context.Background()
+	backend := &flakyMetricsRepository{}
+	repo, err := NewSpoolingMetricsRepository(backend, wal.Options{Dir: t.TempDir(), SyncPolicy: wal.SyncNever}, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Close()
+	save := func(name string) {
+		t.Helper()
+		if err := repo.Save(ctx, []entities.Sample{{Name: name, Value: 1, Timestamp: time.Now()}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	save("m1")
+	backend.fail("*")
+	save("m2")
+	backend.fail()
+	save("m3")
+	if repo.PendingBytes() == 0 {
+		t.Fatal("expected samples to stay spooled behind the outage")
+	}
+	if got := backend.savedNames(); len(got) != 1 || got[0] != "m1" {
+		t.Fatalf("expected later samples to queue behind spooled ones, got %v", got)
+	}
+	backend.fail("m3")
+	if err := repo.replay(); err == nil {
+		t.Fatal("expected replay to report the backend error")
+	}
+	backend.fail()
+	if err := repo.replay(); err != nil {
+		t.Fatal(err)
+	}
+	if got := backend.savedNames(); len(got) != 3 || got[1] != "m2" || got[2] != "m3" {
+		t.Fatalf("expected spooled samples replayed once and in order, got %v", got)
+	}
+	if repo.PendingBytes() != 0 {
+		t.Fatalf("expected empty spool, %d bytes pending", repo.PendingBytes())
+	}
+}
+func TestSpoolingMetricsRepositoryForwardsRetentionDeletes(t *testing.T) {
+	unsupported, err := NewSpoolingMetricsRepository(&flakyMetricsRepository{}, wal.Options{Dir: t.TempDir(), SyncPolicy: wal.SyncNever}, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer unsupported.Close()
+	if _, err := unsupported.DeleteMetrics(context.Background(), entities.RetentionTierRaw, nil, time.Now()); !errors.Is(err, ErrRetentionUnsupported) {
+		t.Fatalf("expected ErrRetentionUnsupported, got %v", err)
+	}
+	db := openEmbeddedDB(t)
+	backend := NewEmbeddedMetricsRepository(db)
+	repo, err := NewSpoolingMetricsRepository(backend, wal.Options{Dir: t.TempDir(), SyncPolicy: wal.SyncNever}, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Close()
+	old := time.Now().Add(-3 * time.Hour)
+	if err := repo.Save(context.Background(), []entities.Sample{{Name: "cpu", Value: 1, Timestamp: old}}); err != nil {
+		t.Fatal(err)
+	}
+	db.Flush()
+	deleted, err := repo.DeleteMetrics(context.Background(), entities.RetentionTierRaw, nil, time.Now().Add(-time.Hour))
+	if err != nil || deleted != 1 {
+		t.Fatalf("expected the delete to reach the backend, got %d %v", deleted, err)
+	}
+}
+func TestSpoolingMetricsRepositoryDropsExpiredSpooledSamples(t *testing.T) {
+	ctx := context.Background()
+	backend := &flakyMetricsRepository{}
+	repo, err := NewSpoolingMetricsRepository(backend, wal.Options{Dir: t.TempDir(), SyncPolicy: wal.SyncNever}, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Close()
+	now := time.Now()
+	backend.fail("*")
+	for _, samples := range [][]entities.Sample{
+		{{Name: "old_api", Labels: map[string]string{"env": "prod"}, Timestamp: now.Add(-3 * time.Hour)}, {Name: "new_api", Labels: map[string]string{"env": "prod"}, Timestamp: now}},
+		{{Name: "old_dev", Labels: map[string]string{"env": "dev"}, Timestamp: now.Add(-3 * time.Hour)}},
+		{{Name: "old_only", Labels: map[string]string{"env": "prod"}, Timestamp: now.Add(-4 * time.Hour)}},
+	} {
+		if err := repo.Save(ctx, samples); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := repo.DeleteMetrics(ctx, "1m", nil, now.Add(-time.Hour)); !errors.Is(err, ErrRetentionUnsupported) {
+		t.Fatalf("expected rollup tiers to leave the spool alone, got %v", err)
+	}
+	if _, err := repo.DeleteMetrics(ctx, entities.RetentionTierRaw, map[string]string{"env": "prod"}, now.Add(-time.Hour)); !errors.Is(err, ErrRetentionUnsupported) {
+		t.Fatalf("expected the backend error after dropping spooled samples, got %v", err)
+	}
+	backend.fail()
+	if err := repo.replay(); err != nil {
+		t.Fatal(err)
+	}
+	if got := backend.savedNames(); fmt.Sprint(got) != "[new_api old_dev]" {
+		t.Fatalf("expected only unexpired or unselected samples to be replayed, got %v", got)
+	}
+}
\ No newline at end of file
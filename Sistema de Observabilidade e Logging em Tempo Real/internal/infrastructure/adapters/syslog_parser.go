@@ -0,0 +1,186 @@
+package adapters
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"observability-system/internal/domain/entities"
+)
+const (
+	syslogLogSource       = "syslog"
+	syslogDefaultPriority = 13
+)
+var syslogFacilities = []string{
+	"kern", "user", "mail", "daemon", "auth", "syslog", "lpr", "news",
+	"uucp", "cron", "authpriv", "ftp", "ntp", "security", "console", "solaris-cron",
+	"local0", "local1", "local2", "local3", "local4", "local5", "local6", "local7",
+}
+var syslogSeverities = []string{"emerg", "alert", "crit", "err", "warning", "notice", "info", "debug"}
+var syslogLevels = []string{"fatal", "fatal", "fatal", "error", "warn", "info", "info", "debug"}
+var errSyslogEmpty = errors.New("empty syslog message")
+func parseSyslog(data []byte, now time.Time) (*entities.LogEntry, error) {
+	data = bytes.TrimRight(data, "\r\n\x00")
+	if len(data) == 0 {
+		return nil, errSyslogEmpty
+	}
+	priority, rest, ok := parseSyslogPriority(data)
+	if !ok {
+		priority, rest = syslogDefaultPriority, data
+	}
+	entry := &entities.LogEntry{
+		Source: syslogLogSource,
+		Labels: map[string]string{
+			"facility": syslogFacilities[priority/8],
+			"severity": syslogSeverities[priority%8],
+		},
+		Level: syslogLevels[priority%8],
+	}
+	if ok && len(rest) > 1 && rest[0] == '1' && rest[1] == ' ' {
+		if err := parseRFC5424(entry, string(rest[2:]), now); err != nil {
+			return nil, err
+		}
+		return entry, nil
+	}
+	parseRFC3164(entry, string(rest), now)
+	return entry, nil
+}
+func parseSyslogPriority(data []byte) (int, []byte, bool) {
+	if len(data) < 3 || data[0] != '<' {
+		return 0, data, false
+	}
+	end := bytes.IndexByte(data[:min(len(data), 5)], '>')
+	if end < 2 {
+		return 0, data, false
+	}
+	priority, err := strconv.Atoi(string(data[1:end]))
+	if err != nil || priority < 0 || priority > 191 {
+		return 0, data, false
+	}
+	return priority, data[end+1:], true
+}
+func parseRFC5424(entry *entities.LogEntry, text string, now time.Time) error {
+	var header [5]string
+	for i := range header {
+		field, rest, ok := strings.Cut(text, " ")
+		if !ok && i < len(header)-1 {
+			return fmt.Errorf("truncated RFC 5424 header")
+		}
+		header[i], text = field, rest
+	}
+	entry.Timestamp = now
+	if header[0] != "-" {
+		timestamp, err := time.Parse(time.RFC3339Nano, header[0])
+		if err != nil {
+			return fmt.Errorf("invalid RFC 5424 timestamp %q", header[0])
+		}
+		entry.Timestamp = timestamp
+	}
+	for i, name := range []string{"hostname", "app_name", "procid", "msgid"} {
+		if value := header[i+1]; value != "-" && value != "" {
+			entry.Labels[name] = value
+		}
+	}
+	fields, rest, err := parseStructuredData(text)
+	if err != nil {
+		return err
+	}
+	if len(fields) > 0 {
+		entry.Fields = fields
+	}
+	entry.Message = strings.TrimPrefix(strings.TrimPrefix(rest, " "), "\ufeff")
+	return nil
+}
+func parseStructuredData(text string) (map[string]string, string, error) {
+	if text == "" || strings.HasPrefix(text, "-") {
+		return nil, strings.TrimPrefix(text, "-"), nil
+	}
+	if text[0] != '[' {
+		return nil, "", fmt.Errorf("invalid RFC 5424 structured data")
+	}
+	fields := make(map[string]string)
+	for len(text) > 0 && text[0] == '[' {
+		end := strings.IndexAny(text, " ]")
+		if end < 0 {
+			return nil, "", fmt.Errorf("unterminated structured data element")
+		}
+		id := text[1:end]
+		text = text[end:]
+		for len(text) > 0 && text[0] == ' ' {
+			text = text[1:]
+			eq := strings.IndexByte(text, '=')
+			if eq < 0 || len(text) < eq+2 || text[eq+1] != '"' {
+				return nil, "", fmt.Errorf("invalid structured data parameter in %q", id)
+			}
+			name := text[:eq]
+			var value strings.Builder
+			i := eq + 2
+			for ; i < len(text) && text[i] != '"'; i++ {
+				if text[i] == '\\' && i+1 < len(text) && strings.IndexByte(`"\]`, text[i+1]) >= 0 {
+					i++
+				}
+				value.WriteByte(text[i])
+			}
+			if i >= len(text) {
+				return nil, "", fmt.Errorf("unterminated structured data value in %q", id)
+			}
+			fields[id+"."+name] = value.String()
+			text = text[i+1:]
+		}
+		if len(text) == 0 || text[0] != ']' {
+			return nil, "", fmt.Errorf("unterminated structured data element %q", id)
+		}
+		text = text[1:]
+	}
+	return fields, text, nil
+}
+func parseRFC3164(entry *entities.LogEntry, text string, now time.Time) {
+	entry.Timestamp = now
+	if timestamp, rest, ok := parseRFC3164Timestamp(text, now); ok {
+		entry.Timestamp, text = timestamp, rest
+		if host, rest, ok := strings.Cut(text, " "); ok && host != "" && !strings.HasSuffix(host, ":") {
+			entry.Labels["hostname"], text = host, rest
+		}
+	}
+	if tag, rest, ok := parseRFC3164Tag(text); ok {
+		name, pid, hasPID := strings.Cut(tag, "[")
+		entry.Labels["app_name"] = name
+		if hasPID {
+			entry.Labels["procid"] = strings.TrimSuffix(pid, "]")
+		}
+		text = rest
+	}
+	entry.Message = text
+}
+func parseRFC3164Timestamp(text string, now time.Time) (time.Time, string, bool) {
+	if len(text) >= 16 && text[15] == ' ' {
+		if parsed, err := time.ParseInLocation(time.Stamp, text[:15], now.Location()); err == nil {
+			timestamp := time.Date(now.Year(), parsed.Month(), parsed.Day(), parsed.Hour(), parsed.Minute(), parsed.Second(), 0, now.Location())
+			if timestamp.After(now.Add(24 * time.Hour)) {
+				timestamp = timestamp.AddDate(-1, 0, 0)
+			}
+			return timestamp, text[16:], true
+		}
+	}
+	if stamp, rest, ok := strings.Cut(text, " "); ok {
+		if timestamp, err := time.Parse(time.RFC3339Nano, stamp); err == nil {
+			return timestamp, rest, true
+		}
+	}
+	return time.Time{}, text, false
+}
+func parseRFC3164Tag(text string) (string, string, bool) {
+	for i := 0; i < len(text) && i <= 48; i++ {
+		switch c := text[i]; {
+		case c == ':':
+			if i == 0 {
+				return "", text, false
+			}
+			return text[:i], strings.TrimPrefix(text[i+1:], " "), true
+		case c == ' ' || c == '\t':
+			return "", text, false
+		}
+	}
+	return "", text, false
+}
\ No newline at end of file
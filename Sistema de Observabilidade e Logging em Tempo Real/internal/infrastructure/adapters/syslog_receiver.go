@@ -0,0 +1,226 @@
+package adapters
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+	"observability-system/internal/domain/entities"
+)
+const (
+	syslogMaxDatagram     = 64 * 1024
+	syslogMaxLengthDigits = 7
+)
+type SyslogOptions struct {
+	UDPAddr   string
+	TCPAddr   string
+	TLSConfig *tls.Config
+}
+type SyslogReceiverAdapter struct {
+	udp       net.PacketConn
+	tcp       net.Listener
+	conns     map[net.Conn]struct{}
+	closed    bool
+	wg        sync.WaitGroup
+	closeOnce sync.Once
+	mu        sync.Mutex
+}
+func NewSyslogReceiverAdapter(options SyslogOptions) (*SyslogReceiverAdapter, error) {
+	if options.UDPAddr == "" && options.TCPAddr == "" {
+		return nil, fmt.Errorf("syslog receiver needs a UDP or TCP address")
+	}
+	r := &SyslogReceiverAdapter{conns: make(map[net.Conn]struct{})}
+	if options.UDPAddr != "" {
+		udp, err := net.ListenPacket("udp", options.UDPAddr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to listen for syslog on udp %s: %w", options.UDPAddr, err)
+		}
+		r.udp = udp
+	}
+	if options.TCPAddr != "" {
+		tcp, err := net.Listen("tcp", options.TCPAddr)
+		if err != nil {
+			r.Close()
+			return nil, fmt.Errorf("failed to listen for syslog on tcp %s: %w", options.TCPAddr, err)
+		}
+		if options.TLSConfig != nil {
+			tcp = tls.NewListener(tcp, options.TLSConfig)
+		}
+		r.tcp = tcp
+	}
+	return r, nil
+}
+func (r *SyslogReceiverAdapter) Run(ctx context.Context, entries chan<- *entities.LogEntry) error {
+	if r.udp != nil {
+		r.wg.Add(1)
+		go r.serveUDP(ctx, entries)
+	}
+	if r.tcp != nil {
+		r.wg.Add(1)
+		go r.serveTCP(ctx, entries)
+	}
+	<-ctx.Done()
+	r.Close()
+	r.wg.Wait()
+	return nil
+}
+func (r *SyslogReceiverAdapter) Close() error {
+	r.closeOnce.Do(func() {
+		if r.udp != nil {
+			r.udp.Close()
+		}
+		if r.tcp != nil {
+			r.tcp.Close()
+		}
+		r.mu.Lock()
+		r.closed = true
+		for conn := range r.conns {
+			conn.Close()
+		}
+		r.mu.Unlock()
+	})
+	return nil
+}
+func (r *SyslogReceiverAdapter) serveUDP(ctx context.Context, entries chan<- *entities.LogEntry) {
+	defer r.wg.Done()
+	buf := make([]byte, syslogMaxDatagram)
+	for {
+		n, addr, err := r.udp.ReadFrom(buf)
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				log.Printf("Syslog UDP receive failed: %v", err)
+			}
+			return
+		}
+		if !r.emit(ctx, entries, buf[:n], addr) {
+			return
+		}
+	}
+}
+func (r *SyslogReceiverAdapter) serveTCP(ctx context.Context, entries chan<- *entities.LogEntry) {
+	defer r.wg.Done()
+	for {
+		conn, err := r.tcp.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				log.Printf("Syslog TCP accept failed: %v", err)
+			}
+			return
+		}
+		r.mu.Lock()
+		if r.closed {
+			r.mu.Unlock()
+			conn.Close()
+			return
+		}
+		r.conns[conn] = struct{}{}
+		r.wg.Add(1)
+		r.mu.Unlock()
+		go r.serveConn(ctx, conn, entries)
+	}
+}
+func (r *SyslogReceiverAdapter) serveConn(ctx context.Context, conn net.Conn, entries chan<- *entities.LogEntry) {
+	defer r.wg.Done()
+	defer func() {
+		r.mu.Lock()
+		delete(r.conns, conn)
+		r.mu.Unlock()
+		conn.Close()
+	}()
+	reader := bufio.NewReader(conn)
+	for {
+		frame, err := readSyslogFrame(reader)
+		if len(frame) > 0 && !r.emit(ctx, entries, frame, conn.RemoteAddr()) {
+			return
+		}
+		if err != nil {
+			if err != io.EOF && !errors.Is(err, net.ErrClosed) {
+				log.Printf("Syslog connection from %s failed: %v", conn.RemoteAddr(), err)
+			}
+			return
+		}
+	}
+}
+func (r *SyslogReceiverAdapter) emit(ctx context.Context, entries chan<- *entities.LogEntry, data []byte, addr net.Addr) bool {
+	entry, err := parseSyslog(data, time.Now())
+	if err != nil {
+		if err != errSyslogEmpty {
+			log.Printf("Dropping syslog message from %s: %v", addr, err)
+		}
+		return true
+	}
+	if _, ok := entry.Labels["hostname"]; !ok && addr != nil {
+		if host, _, err := net.SplitHostPort(addr.String()); err == nil {
+			entry.Labels["hostname"] = host
+		}
+	}
+	select {
+	case entries <- entry:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+func readSyslogFrame(reader *bufio.Reader) ([]byte, error) {
+	var first byte
+	for {
+		peek, err := reader.Peek(1)
+		if err != nil {
+			return nil, err
+		}
+		if first = peek[0]; first != '\n' && first != '\r' && first != 0 {
+			break
+		}
+		reader.Discard(1)
+	}
+	if length, header, ok := peekOctetCount(reader); ok {
+		if length > maxLogLineBytes {
+			return nil, fmt.Errorf("syslog frame of %d bytes exceeds limit", length)
+		}
+		reader.Discard(header)
+		frame := make([]byte, length)
+		if _, err := io.ReadFull(reader, frame); err != nil {
+			return nil, err
+		}
+		return frame, nil
+	}
+	var frame []byte
+	for {
+		c, err := reader.ReadByte()
+		if err != nil {
+			return frame, err
+		}
+		if c == '\n' || c == 0 {
+			return frame, nil
+		}
+		if len(frame) >= maxLogLineBytes {
+			return nil, fmt.Errorf("syslog line exceeds %d bytes", maxLogLineBytes)
+		}
+		frame = append(frame, c)
+	}
+}
+func peekOctetCount(reader *bufio.Reader) (int, int, bool) {
+	for digits := 1; digits <= syslogMaxLengthDigits; digits++ {
+		head, err := reader.Peek(digits + 1)
+		if err != nil || head[0] < '1' || head[0] > '9' {
+			return 0, 0, false
+		}
+		if next := head[digits]; next >= '0' && next <= '9' {
+			continue
+		} else if next != ' ' {
+			return 0, 0, false
+		}
+		if head, err = reader.Peek(digits + 2); err != nil || head[digits+1] != '<' {
+			return 0, 0, false
+		}
+		length, _ := strconv.Atoi(string(head[:digits]))
+		return length, digits + 1, true
+	}
+	return 0, 0, false
+}
\ No newline at end of file
@@ -0,0 +1,271 @@
+package adapters
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+	"observability-system/internal/domain/entities"
+)
+func runSyslogReceiver(t *testing.T, options SyslogOptions) (*SyslogReceiverAdapter, chan *entities.LogEntry) {
+	t.Helper()
+	receiver, err := NewSyslogReceiverAdapter(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	entries := make(chan *entities.LogEntry, 16)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		receiver.Run(ctx, entries)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	return receiver, entries
+}
+func receiveSyslog(t *testing.T, entries chan *entities.LogEntry, count int) []*entities.LogEntry {
+	t.Helper()
+	var got []*entities.LogEntry
+	for len(got) < count {
+		select {
+		case entry := <-entries:
+			got = append(got, entry)
+		case <-time.After(2 * time.Second):
+			t.Fatalf("timed out after %d of %d syslog entries", len(got), count)
+		}
+	}
+	return got
+}
+func TestParseSyslogFormats(t *testing.T) {
+	now := time.Date(2024, 1, 2, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		input   string
+		ts      time.Time
+		level   string
+		message string
+		labels  map[string]string
+		fields  map[string]string
+	}{
+		{
+			name:    "rfc3164",
+			input:   "<34>Oct 11 22:14:15 mymachine su[123]: 'su root' failed for lonvick on /dev/pts/8",
+			ts:      time.Date(2023, 10, 11, 22, 14, 15, 0, time.UTC),
+			level:   "fatal",
+			message: "'su root' failed for lonvick on /dev/pts/8",
+			labels:  map[string]string{"facility": "auth", "severity": "crit", "hostname": "mymachine", "app_name": "su", "procid": "123"},
+		},
+		{
+			name:    "rfc3164 without hostname or header",
+			input:   "<14>cron: job finished",
+			ts:      now,
+			level:   "info",
+			message: "job finished",
+			labels:  map[string]string{"facility": "user", "severity": "info", "app_name": "cron"},
+		},
+		{
+			name:    "missing priority",
+			input:   "plain message without header",
+			ts:      now,
+			level:   "info",
+			message: "plain message without header",
+			labels:  map[string]string{"facility": "user", "severity": "notice"},
+		},
+		{
+			name:    "rfc5424 with structured data",
+			input:   `<165>1 2003-10-11T22:14:15.003Z mymachine.example.com evntslog - ID47 [exampleSDID@32473 iut="3" eventSource="Application" eventID="1011"][meta note="a \"quoted\" \] value"] ` + "\ufeffAn application event",
+			ts:      time.Date(2003, 10, 11, 22, 14, 15, 3000000, time.UTC),
+			level:   "info",
+			message: "An application event",
+			labels:  map[string]string{"facility": "local4", "severity": "notice", "hostname": "mymachine.example.com", "app_name": "evntslog", "msgid": "ID47"},
+			fields: map[string]string{
+				"exampleSDID@32473.iut":         "3",
+				"exampleSDID@32473.eventSource": "Application",
+				"exampleSDID@32473.eventID":     "1011",
+				"meta.note":                     `a "quoted" ] value`,
+			},
+		},
+		{
+			name:    "rfc5424 with nil values",
+			input:   "<11>1 - - - - - -",
+			ts:      now,
+			level:   "error",
+			message: "",
+			labels:  map[string]string{"facility": "user", "severity": "err"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry, err := parseSyslog([]byte(tt.input), now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !entry.Timestamp.Equal(tt.ts) || entry.Level != tt.level || entry.Message != tt.message || entry.Source != "syslog" {
+				t.Fatalf("unexpected entry %+v", entry)
+			}
+			if len(entry.Labels) != len(tt.labels) {
+				t.Fatalf("expected labels %v, got %v", tt.labels, entry.Labels)
+			}
+			for k, v := range tt.labels {
+				if entry.Labels[k] != v {
+					t.Fatalf("expected labels %v, got %v", tt.labels, entry.Labels)
+				}
+			}
+			if len(entry.Fields) != len(tt.fields) {
+				t.Fatalf("expected fields %v, got %v", tt.fields, entry.Fields)
+			}
+			for k, v := range tt.fields {
+				if entry.Fields[k] != v {
+					t.Fatalf("expected fields %v, got %v", tt.fields, entry.Fields)
+				}
+			}
+		})
+	}
+	if _, err := parseSyslog([]byte("<13>1 2003-10-11T22:14:15Z host app - - [broken"), now); err == nil {
+		t.Fatal("expected error for unterminated structured data")
+	}
+}
+func TestSyslogReceiverTCPFraming(t *testing.T) {
+	receiver, entries := runSyslogReceiver(t, SyslogOptions{TCPAddr: "127.0.0.1:0"})
+	conn, err := net.Dial("tcp", receiver.tcp.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	first := "<14>Jan  1 00:00:00 host app: two"
+	framed := "<14>1 2024-01-01T00:00:00Z host app - - - line one\nstill line one"
+	payload := strconv.Itoa(len(first)) + " " + first +
+		"<14>three\n" +
+		"\n<14>four\x00" +
+		strconv.Itoa(len(framed)) + " " + framed
+	if _, err := conn.Write([]byte(payload)); err != nil {
+		t.Fatal(err)
+	}
+	got := receiveSyslog(t, entries, 4)
+	want := []string{"two", "three", "four", "line one\nstill line one"}
+	for i, entry := range got {
+		if entry.Message != want[i] {
+			t.Fatalf("entry %d: expected %q, got %q", i, want[i], entry.Message)
+		}
+	}
+	if got[1].Labels["hostname"] != "127.0.0.1" {
+		t.Fatalf("expected peer address as hostname, got %v", got[1].Labels)
+	}
+}
+func TestSyslogReceiverUDP(t *testing.T) {
+	receiver, entries := runSyslogReceiver(t, SyslogOptions{UDPAddr: "127.0.0.1:0"})
+	conn, err := net.Dial("udp", receiver.udp.LocalAddr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if _, err := conn.Write([]byte("<11>Jan  1 00:00:00 web nginx[7]: upstream timed out\n")); err != nil {
+		t.Fatal(err)
+	}
+	got := receiveSyslog(t, entries, 1)[0]
+	if got.Message != "upstream timed out" || got.Level != "error" || got.Labels["hostname"] != "web" || got.Labels["procid"] != "7" {
+		t.Fatalf("unexpected entry %+v", got)
+	}
+}
+func TestSyslogReceiverTLS(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "syslog-test"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	receiver, entries := runSyslogReceiver(t, SyslogOptions{
+		TCPAddr:   "127.0.0.1:0",
+		TLSConfig: &tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}}},
+	})
+	conn, err := tls.Dial("tcp", receiver.tcp.Addr().String(), &tls.Config{RootCAs: pool})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if _, err := conn.Write([]byte("<13>1 2024-01-01T00:00:00Z host app - - - secure line\n")); err != nil {
+		t.Fatal(err)
+	}
+	if got := receiveSyslog(t, entries, 1)[0]; got.Message != "secure line" || got.Labels["app_name"] != "app" {
+		t.Fatalf("unexpected entry %+v", got)
+	}
+}
+func TestSyslogReceiverTCPFramingRequiresPriorityAfterLength(t *testing.T) {
+	receiver, entries := runSyslogReceiver(t, SyslogOptions{TCPAddr: "127.0.0.1:0"})
+	conn, err := net.Dial("tcp", receiver.tcp.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if _, err := conn.Write([]byte("15 workers started\n7 <14>one\n12345678 bytes\n<14>app: last\n")); err != nil {
+		t.Fatal(err)
+	}
+	got := receiveSyslog(t, entries, 4)
+	want := []string{"15 workers started", "one", "12345678 bytes", "last"}
+	for i, entry := range got {
+		if entry.Message != want[i] {
+			t.Fatalf("entry %d: expected %q, got %q", i, want[i], entry.Message)
+		}
+	}
+}
+func TestSyslogReceiverRejectsConnectionsAfterClose(t *testing.T) {
+	receiver, err := NewSyslogReceiverAdapter(SyslogOptions{TCPAddr: "127.0.0.1:0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer receiver.tcp.Close()
+	receiver.mu.Lock()
+	receiver.closed = true
+	receiver.mu.Unlock()
+	conn, err := net.Dial("tcp", receiver.tcp.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	receiver.wg.Add(1)
+	go receiver.serveTCP(context.Background(), make(chan *entities.LogEntry))
+	done := make(chan struct{})
+	go func() {
+		receiver.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("receiver kept serving a connection accepted after close")
+	}
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	if _, err := conn.Read(make([]byte, 1)); err == nil {
+		t.Fatal("expected the late connection to be closed")
+	}
+	if len(receiver.conns) != 0 {
+		t.Fatalf("expected no registered connections, got %d", len(receiver.conns))
+	}
+}
\ No newline at end of file
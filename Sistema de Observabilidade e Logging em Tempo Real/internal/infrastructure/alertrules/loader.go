@@ -0,0 +1,202 @@
+package alertrules
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+	"gopkg.in/yaml.v3"
+	"observability-system/internal/domain/entities"
+)
+type ruleFile struct {
+	Rules []yaml.Node `yaml:"rules"`
+}
+type ruleSpec struct {
+	Name           string            `yaml:"name"`
+	Expr           string            `yaml:"expr"`
+	Comparator     string            `yaml:"comparator"`
+	Threshold      *float64          `yaml:"threshold"`
+	ClearThreshold *float64          `yaml:"clear_threshold"`
+	Selector       map[string]string `yaml:"selector"`
+	Severity       string            `yaml:"severity"`
+	Annotations    map[string]string `yaml:"annotations"`
+	Cooldown       string            `yaml:"cooldown"`
+	RepeatInterval string            `yaml:"repeat_interval"`
+	For            string            `yaml:"for"`
+}
+var (
+	severities = map[string]bool{"info": true, "warning": true, "critical": true}
+	fileFields = map[string]bool{"rules": true}
+	ruleFields = map[string]bool{"name": true, "expr": true, "comparator": true, "threshold": true, "clear_threshold": true, "selector": true, "severity": true, "annotations": true, "cooldown": true, "repeat_interval": true, "for": true}
+)
+func LoadFiles(paths []string) ([]entities.AlertRule, error) {
+	var rules []entities.AlertRule
+	names := make(map[string]string)
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		loaded, err := Parse(path, data)
+		if err != nil {
+			return nil, err
+		}
+		for _, rule := range loaded {
+			if previous, ok := names[rule.Name]; ok {
+				return nil, fmt.Errorf("%s: rule %q is already defined in %s", path, rule.Name, previous)
+			}
+			names[rule.Name] = path
+		}
+		rules = append(rules, loaded...)
+	}
+	return rules, nil
+}
+func Parse(filename string, data []byte) ([]entities.AlertRule, error) {
+	var document yaml.Node
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		message := strings.TrimPrefix(err.Error(), "yaml: ")
+		var line int
+		if _, scanErr := fmt.Sscanf(message, "line %d:", &line); scanErr == nil {
+			return nil, fmt.Errorf("%s:%d: %s", filename, line, strings.TrimSpace(message[strings.Index(message, ":")+1:]))
+		}
+		return nil, fmt.Errorf("%s: %s", filename, message)
+	}
+	if len(document.Content) == 0 {
+		return nil, nil
+	}
+	var file ruleFile
+	if err := decodeStrict(document.Content[0], &file, fileFields); err != nil {
+		return nil, fmt.Errorf("%s:%d: %s", filename, err.line, err.msg)
+	}
+	var rules []entities.AlertRule
+	var errs []error
+	seen := make(map[string]int)
+	for i := range file.Rules {
+		node := &file.Rules[i]
+		rule, err := parseRule(node)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s:%d: %s", filename, err.line, err.msg))
+			continue
+		}
+		if line, ok := seen[rule.Name]; ok {
+			errs = append(errs, fmt.Errorf("%s:%d: rule %q is already defined on line %d", filename, node.Line, rule.Name, line))
+			continue
+		}
+		seen[rule.Name] = node.Line
+		rules = append(rules, rule)
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return rules, nil
+}
+type ruleError struct {
+	line int
+	msg  string
+}
+func parseRule(node *yaml.Node) (entities.AlertRule, *ruleError) {
+	var spec ruleSpec
+	if err := decodeStrict(node, &spec, ruleFields); err != nil {
+		return entities.AlertRule{}, err
+	}
+	fail := func(field, format string, args ...interface{}) *ruleError {
+		prefix := "rule"
+		if spec.Name != "" {
+			prefix = fmt.Sprintf("rule %q", spec.Name)
+		}
+		return &ruleError{line: fieldLine(node, field), msg: prefix + ": " + fmt.Sprintf(format, args...)}
+	}
+	rule := entities.AlertRule{
+		Name:        spec.Name,
+		Comparator:  entities.Comparator(spec.Comparator),
+		Selector:    spec.Selector,
+		Severity:    spec.Severity,
+		Annotations: spec.Annotations,
+		Cooldown:    entities.DefaultAlertCooldown,
+	}
+	if spec.Name == "" {
+		return rule, fail("name", "name is required")
+	}
+	if spec.Expr == "" {
+		return rule, fail("expr", "expr is required")
+	}
+	expr, err := entities.ParseMetricExpr(spec.Expr)
+	if err != nil {
+		return rule, fail("expr", "%v", err)
+	}
+	rule.Expr = expr
+	if !rule.Comparator.Valid() {
+		return rule, fail("comparator", "comparator must be one of >, >=, <, <=, ==, != (got %q)", spec.Comparator)
+	}
+	if spec.Threshold == nil {
+		return rule, fail("threshold", "threshold is required")
+	}
+	rule.Threshold = *spec.Threshold
+	if spec.ClearThreshold != nil {
+		if !rule.Comparator.ValidClear(rule.Threshold, *spec.ClearThreshold) {
+			return rule, fail("clear_threshold", "clear_threshold %v must be on the healthy side of threshold %v for comparator %s", *spec.ClearThreshold, rule.Threshold, rule.Comparator)
+		}
+		rule.ClearThreshold = spec.ClearThreshold
+	}
+	if rule.Severity == "" {
+		rule.Severity = "warning"
+	}
+	if !severities[rule.Severity] {
+		return rule, fail("severity", "severity must be info, warning or critical (got %q)", spec.Severity)
+	}
+	if spec.Cooldown != "" {
+		if rule.Cooldown, err = time.ParseDuration(spec.Cooldown); err != nil || rule.Cooldown < 0 {
+			return rule, fail("cooldown", "invalid cooldown %q", spec.Cooldown)
+		}
+	}
+	if spec.RepeatInterval != "" {
+		if rule.RepeatInterval, err = time.ParseDuration(spec.RepeatInterval); err != nil || rule.RepeatInterval < 0 {
+			return rule, fail("repeat_interval", "invalid repeat interval %q", spec.RepeatInterval)
+		}
+	}
+	if spec.For != "" {
+		if rule.For, err = time.ParseDuration(spec.For); err != nil || rule.For < 0 {
+			return rule, fail("for", "invalid for duration %q", spec.For)
+		}
+	}
+	if err := entities.ValidateLabelSelector(spec.Selector); err != nil {
+		return rule, fail("selector", "invalid selector: %v", err)
+	}
+	for name, text := range spec.Annotations {
+		if _, err := entities.ParseAnnotationTemplate(text); err != nil {
+			return rule, fail("annotations", "invalid annotation %s: %v", name, strings.TrimPrefix(err.Error(), "template: annotation:"))
+		}
+	}
+	return rule, nil
+}
+func decodeStrict(node *yaml.Node, out interface{}, known map[string]bool) *ruleError {
+	if node.Kind != yaml.MappingNode {
+		return &ruleError{line: node.Line, msg: "expected a mapping"}
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if key := node.Content[i]; !known[key.Value] {
+			return &ruleError{line: key.Line, msg: fmt.Sprintf("unknown field %q", key.Value)}
+		}
+	}
+	if err := node.Decode(out); err != nil {
+		var typeErr *yaml.TypeError
+		if errors.As(err, &typeErr) && len(typeErr.Errors) > 0 {
+			message := typeErr.Errors[0]
+			var line int
+			if _, scanErr := fmt.Sscanf(message, "line %d:", &line); scanErr == nil {
+				return &ruleError{line: line, msg: strings.TrimSpace(message[strings.Index(message, ":")+1:])}
+			}
+			return &ruleError{line: node.Line, msg: message}
+		}
+		return &ruleError{line: node.Line, msg: err.Error()}
+	}
+	return nil
+}
+func fieldLine(node *yaml.Node, field string) int {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == field {
+			return node.Content[i+1].Line
+		}
+	}
+	return node.Line
+}
\ No newline at end of file
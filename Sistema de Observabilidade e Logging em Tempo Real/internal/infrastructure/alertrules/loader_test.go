@@ -0,0 +1,136 @@
+package alertrules
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"observability-system/internal/domain/entities"
+)
+const validRules = `rules:
+  - name: HighMemoryRatio
+    expr: container_memory_usage_bytes / container_memory_limit_bytes * 100
+    comparator: ">="
+    threshold: 80
+    clear_threshold: 70
+    selector:
+      container_name: "api-*"
+    severity: critical
+    cooldown: 10m
+    repeat_interval: 1h
+    for: 2m
+    annotations:
+      summary: "{{ $labels.container_name }} memory at {{ $value }}% (limit {{ $threshold }}%)"
+  - name: NginxErrors
+    expr: nginx_http_requests_total
+    comparator: ">"
+    threshold: 100
+    selector:
+      response: "5*"
+`
+func TestParseRulesAndEvaluate(t *testing.T) {
+	rules, err := Parse("rules.yml", []byte(validRules))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rules) != 2 {
+		t.Fatalf("expected 2 rules, got %d", len(rules))
+	}
+	memory := rules[0]
+	if memory.Severity != "critical" || memory.Cooldown != 10*time.Minute || memory.RepeatInterval != time.Hour || memory.For != 2*time.Minute || memory.Comparator != entities.ComparatorGreaterEqual {
+		t.Fatalf("unexpected rule %+v", memory)
+	}
+	if rules[1].Severity != "warning" || rules[1].Cooldown != entities.DefaultAlertCooldown || rules[1].RepeatInterval != 0 || rules[1].For != 0 {
+		t.Fatalf("expected defaults, got %+v", rules[1])
+	}
+	if memory.ClearThreshold == nil || *memory.ClearThreshold != 70 || rules[1].ClearThreshold != nil {
+		t.Fatalf("unexpected clear thresholds %v %v", memory.ClearThreshold, rules[1].ClearThreshold)
+	}
+	if memory.Breached(75, false) || !memory.Breached(75, true) || memory.Breached(69, true) {
+		t.Fatal("expected hysteresis between clear and trigger thresholds")
+	}
+	labels := map[string]string{"container_id": "c1", "container_name": "api-1"}
+	if !entities.MatchLabelSelector(memory.Selector, labels) {
+		t.Fatal("expected selector to match api-1")
+	}
+	value, ok := memory.Expr.Eval(map[string]float64{"container_memory_usage_bytes": 900, "container_memory_limit_bytes": 1000})
+	if !ok || value != 90 || !memory.Comparator.Compare(value, memory.Threshold) {
+		t.Fatalf("unexpected evaluation %v %v", value, ok)
+	}
+	if _, ok := memory.Expr.Eval(map[string]float64{"container_memory_usage_bytes": 900}); ok {
+		t.Fatal("expected evaluation to fail without every referenced metric")
+	}
+	if _, ok := memory.Expr.Eval(map[string]float64{"container_memory_usage_bytes": 900, "container_memory_limit_bytes": 0}); ok {
+		t.Fatal("expected evaluation to fail on division by zero")
+	}
+	if got := memory.RenderAnnotations(value, labels)["summary"]; got != "api-1 memory at 90% (limit 80%)" {
+		t.Fatalf("unexpected summary %q", got)
+	}
+}
+func TestMetricExpressionPrecedence(t *testing.T) {
+	values := map[string]float64{"a": 2, "b": 3, "c": 4}
+	for text, want := range map[string]float64{
+		"a + b * c":     14,
+		"(a + b) * c":   20,
+		"-a + c / 2":    0,
+		"a - b - c":     -5,
+		"100 * a / c":   50,
+		"  c  ":         4,
+		"a * (b - 1.5)": 3,
+	} {
+		expr, err := entities.ParseMetricExpr(text)
+		if err != nil {
+			t.Fatalf("%q: %v", text, err)
+		}
+		if got, ok := expr.Eval(values); !ok || got != want {
+			t.Errorf("%q = %v, want %v", text, got, want)
+		}
+	}
+	for _, text := range []string{"", "a +", "(a", "a b", "1 + 2", "a $ b"} {
+		if _, err := entities.ParseMetricExpr(text); err == nil {
+			t.Errorf("expected error for %q", text)
+		}
+	}
+}
+func TestParseReportsLineNumbers(t *testing.T) {
+	tests := []struct {
+		spec string
+		want string
+	}{
+		{"rules:\n  - name: a\n    expr: cpu\n    comparator: '>'\n", "rules.yml:2: rule \"a\": threshold is required"},
+		{"rules:\n  - name: a\n    expr: cpu +\n    comparator: '>'\n    threshold: 1\n", "rules.yml:3: rule \"a\": invalid expression"},
+		{"rules:\n  - name: a\n    expr: cpu\n    comparator: '=>'\n    threshold: 1\n", "rules.yml:4: rule \"a\": comparator must be one of"},
+		{"rules:\n  - name: a\n    expr: cpu\n    comparator: '>'\n    threshold: high\n", "rules.yml:5: cannot unmarshal"},
+		{"rules:\n  - name: a\n    expr: cpu\n    comparator: '>'\n    threshold: 1\n    thresold: 2\n", "rules.yml:6: unknown field \"thresold\""},
+		{"rules:\n  - name: a\n    expr: cpu\n    comparator: '>'\n    threshold: 1\n    severity: page\n", "rules.yml:6: rule \"a\": severity must be"},
+		{"rules:\n  - name: a\n    expr: cpu\n    comparator: '>'\n    threshold: 1\n    annotations:\n      summary: '{{ $value'\n", "rules.yml:7: rule \"a\": invalid annotation summary"},
+		{"rules:\n  - name: a\n    expr: cpu\n    comparator: '>'\n    threshold: 1\n    clear_threshold: 2\n", "rules.yml:6: rule \"a\": clear_threshold 2 must be on the healthy side"},
+		{"rules:\n  - name: a\n    expr: cpu\n    comparator: '>'\n    threshold: 1\n    for: -1m\n", "rules.yml:6: rule \"a\": invalid for duration"},
+		{"rules:\n  - name: a\n    expr: cpu\n    comparator: '>'\n    threshold: 1\n    repeat_interval: soon\n", "rules.yml:6: rule \"a\": invalid repeat interval"},
+		{"rules:\n  - name: a\n    expr: cpu\n    comparator: '>'\n    threshold: 1\n  - name: a\n    expr: mem\n    comparator: '>'\n    threshold: 1\n", "rules.yml:6: rule \"a\" is already defined on line 2"},
+		{"rules:\n  - name: a\n    expr: cpu\n    comparator: \"\t\n", "rules.yml:4: found unexpected end of stream"},
+		{"rules:\n  - expr: cpu\n", "rules.yml:2: rule: name is required"},
+	}
+	for _, tt := range tests {
+		_, err := Parse("rules.yml", []byte(tt.spec))
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Parse(%q) error = %v, want %q", tt.spec, err, tt.want)
+		}
+	}
+}
+func TestLoadFilesRejectsDuplicatesAcrossFiles(t *testing.T) {
+	dir := t.TempDir()
+	first, second := filepath.Join(dir, "a.yml"), filepath.Join(dir, "b.yml")
+	if err := os.WriteFile(first, []byte(validRules), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(second, []byte("rules:\n  - name: NginxErrors\n    expr: x\n    comparator: '>'\n    threshold: 1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadFiles([]string{first}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadFiles([]string{first, second}); err == nil || !strings.Contains(err.Error(), "already defined in "+first) {
+		t.Fatalf("expected duplicate error, got %v", err)
+	}
+}
\ No newline at end of file
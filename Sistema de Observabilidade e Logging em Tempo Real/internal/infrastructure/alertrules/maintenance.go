@@ -0,0 +1,72 @@
+package alertrules
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"observability-system/internal/domain/entities"
+	"observability-system/internal/infrastructure/logparse"
+)
+var windowNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+func ParseMaintenanceWindows(spec string) ([]entities.MaintenanceWindow, error) {
+	var windows []entities.MaintenanceWindow
+	names := make(map[string]bool)
+	for number, line := range strings.Split(spec, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		selector, options, err := logparse.ParseSelectorLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("maintenance window %d: %w", number+1, err)
+		}
+		window, err := newMaintenanceWindow(selector, options)
+		if err != nil {
+			return nil, fmt.Errorf("maintenance window %d: %w", number+1, err)
+		}
+		if names[window.Name] {
+			return nil, fmt.Errorf("maintenance window %d: duplicate name %q", number+1, window.Name)
+		}
+		names[window.Name] = true
+		windows = append(windows, window)
+	}
+	return windows, nil
+}
+func newMaintenanceWindow(selector map[string]string, options map[string]string) (entities.MaintenanceWindow, error) {
+	window := entities.MaintenanceWindow{Matchers: selector}
+	for name, value := range options {
+		var err error
+		switch name {
+		case "name":
+			if !windowNamePattern.MatchString(value) {
+				err = fmt.Errorf("must contain only letters, digits, '_' or '-'")
+			}
+			window.Name = value
+		case "schedule":
+			window.Schedule, err = entities.ParseCronSchedule(value)
+		case "duration":
+			window.Duration, err = time.ParseDuration(value)
+			if err == nil && (window.Duration <= 0 || window.Duration > entities.MaxMaintenanceWindow) {
+				err = fmt.Errorf("must be positive and at most %s", entities.MaxMaintenanceWindow)
+			}
+		case "timezone":
+			window.Location, err = time.LoadLocation(value)
+		case "comment":
+			window.Comment = value
+		default:
+			return window, fmt.Errorf("unknown option %q", name)
+		}
+		if err != nil {
+			return window, fmt.Errorf("invalid %s %q: %w", name, value, err)
+		}
+	}
+	switch {
+	case window.Name == "":
+		return window, fmt.Errorf("a name is required")
+	case window.Schedule == nil:
+		return window, fmt.Errorf("a schedule is required")
+	case window.Duration == 0:
+		return window, fmt.Errorf("a duration is required")
+	}
+	return window, nil
+}
\ No newline at end of file
@@ -0,0 +1,66 @@
+package alertrules
+import (
+	"strings"
+	"testing"
+	"time"
+)
+func TestParseMaintenanceWindows(t *testing.T) {
+	windows, err := ParseMaintenanceWindows(strings.Join([]string{
+		"# weekly deploys",
+		`{container_name="api-*"} name=deploys schedule="30 2 * * 6" duration=90m timezone=UTC comment="weekly deploy"`,
+		`{} name=patching schedule="0 4 1,15 * *" duration=1h`,
+	}, "\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(windows) != 2 {
+		t.Fatalf("expected 2 windows, got %d", len(windows))
+	}
+	deploys, patching := windows[0], windows[1]
+	if deploys.Duration != 90*time.Minute || deploys.Comment != "weekly deploy" || deploys.Schedule.String() != "30 2 * * 6" {
+		t.Fatalf("unexpected window %+v", deploys)
+	}
+	saturday := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		at   time.Time
+		want bool
+	}{
+		{saturday.Add(2*time.Hour + 29*time.Minute), false},
+		{saturday.Add(2*time.Hour + 30*time.Minute), true},
+		{saturday.Add(3*time.Hour + 59*time.Minute), true},
+		{saturday.Add(4 * time.Hour), false},
+		{saturday.Add(7*24*time.Hour + 3*time.Hour), true},
+		{saturday.Add(24*time.Hour + 3*time.Hour), false},
+	}
+	for _, tt := range tests {
+		if got := deploys.Active(tt.at); got != tt.want {
+			t.Errorf("deploys.Active(%s) = %v, want %v", tt.at, got, tt.want)
+		}
+	}
+	if !deploys.Matches(map[string]string{"container_name": "api-1"}) || deploys.Matches(map[string]string{"container_name": "db"}) {
+		t.Fatal("unexpected selector matching")
+	}
+	if !patching.Active(time.Date(2024, 6, 15, 4, 30, 0, 0, time.UTC)) || patching.Active(time.Date(2024, 6, 16, 4, 30, 0, 0, time.UTC)) {
+		t.Fatal("expected patching window on the 1st and 15th only")
+	}
+}
+func TestParseMaintenanceWindowsErrors(t *testing.T) {
+	for spec, want := range map[string]string{
+		"name=missing_selector":                                                         "maintenance window 1: expected {selector}",
+		`{} schedule="* * * * *" duration=1h`:                                           "a name is required",
+		`{} name=a duration=1h`:                                                         "a schedule is required",
+		`{} name=a schedule="* * * * *"`:                                                "a duration is required",
+		`{} name=a schedule="61 * * * *" duration=1h`:                                   "minute value \"61\" must be between 0 and 59",
+		`{} name=a schedule="* * *" duration=1h`:                                        "must have 5 fields",
+		`{} name=a schedule="*/0 * * * *" duration=1h`:                                  "invalid step",
+		`{} name=a schedule="5-1 * * * *" duration=1h`:                                  "invalid range",
+		`{} name=a schedule=@daily duration=8d`:                                         "invalid duration",
+		`{} name=a schedule=@daily duration=1h timezone=Mars/Base`:                      "invalid timezone",
+		"{} name=a schedule=@daily duration=1h\n{} name=a schedule=@weekly duration=1h": "maintenance window 2: duplicate name",
+	} {
+		_, err := ParseMaintenanceWindows(spec)
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("ParseMaintenanceWindows(%q) error = %v, want %q", spec, err, want)
+		}
+	}
+}
\ No newline at end of file
@@ -0,0 +1,191 @@
+package flux
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+type Aggregate string
+const (
+	Mean  Aggregate = "mean"
+	Min   Aggregate = "min"
+	Max   Aggregate = "max"
+	Sum   Aggregate = "sum"
+	Count Aggregate = "count"
+	Last  Aggregate = "last"
+)
+type Predicate struct {
+	expr    string
+	imports []string
+}
+type Query struct {
+	bucket  string
+	stages  []string
+	imports []string
+}
+func From(bucket string) *Query {
+	return &Query{bucket: bucket}
+}
+func (q *Query) Range(lookback time.Duration) *Query {
+	return q.pipe(fmt.Sprintf("range(start: -%s)", Duration(lookback)))
+}
+func (q *Query) RangeBetween(start, stop time.Time) *Query {
+	return q.pipe(fmt.Sprintf("range(start: %s, stop: %s)", Time(start), Time(stop)))
+}
+func (q *Query) Filter(predicates ...Predicate) *Query {
+	if len(predicates) == 0 {
+		return q
+	}
+	predicate := And(predicates...)
+	for _, name := range predicate.imports {
+		q.use(name)
+	}
+	return q.pipe(fmt.Sprintf("filter(fn: (r) => %s)", predicate.expr))
+}
+func (q *Query) AggregateWindow(every time.Duration, fn Aggregate) *Query {
+	return q.pipe(fmt.Sprintf("aggregateWindow(every: %s, fn: %s, createEmpty: false)", Duration(every), fn))
+}
+func (q *Query) Group(columns ...string) *Query {
+	return q.pipe(fmt.Sprintf("group(columns: %s)", List(columns)))
+}
+func (q *Query) Pivot(rowKey, columnKey []string, valueColumn string) *Query {
+	return q.pipe(fmt.Sprintf("pivot(rowKey: %s, columnKey: %s, valueColumn: %s)", List(rowKey), List(columnKey), String(valueColumn)))
+}
+func (q *Query) Sort(desc bool, columns ...string) *Query {
+	return q.pipe(fmt.Sprintf("sort(columns: %s, desc: %t)", List(columns), desc))
+}
+func (q *Query) Limit(n int) *Query {
+	return q.pipe(fmt.Sprintf("limit(n: %d)", n))
+}
+func (q *Query) Last() *Query {
+	return q.pipe("last()")
+}
+func (q *Query) Count() *Query {
+	return q.pipe("count()")
+}
+func (q *Query) String() string {
+	var b strings.Builder
+	for _, name := range q.imports {
+		b.WriteString("import ")
+		b.WriteString(String(name))
+		b.WriteString("\n")
+	}
+	b.WriteString("from(bucket: ")
+	b.WriteString(String(q.bucket))
+	b.WriteString(")")
+	for _, stage := range q.stages {
+		b.WriteString("\n|> ")
+		b.WriteString(stage)
+	}
+	return b.String()
+}
+func (q *Query) pipe(stage string) *Query {
+	q.stages = append(q.stages, stage)
+	return q
+}
+func (q *Query) use(name string) {
+	for _, imported := range q.imports {
+		if imported == name {
+			return
+		}
+	}
+	q.imports = append(q.imports, name)
+}
+func Equal(column, value string) Predicate {
+	return compare(column, "==", value)
+}
+func NotEqual(column, value string) Predicate {
+	return compare(column, "!=", value)
+}
+func AtLeast(column string, value int64) Predicate {
+	return Predicate{expr: fmt.Sprintf("r[%s] >= %d", String(column), value)}
+}
+func AtMost(column string, value int64) Predicate {
+	return Predicate{expr: fmt.Sprintf("r[%s] <= %d", String(column), value)}
+}
+func ContainsFold(column, substr string) Predicate {
+	return Predicate{
+		expr:    fmt.Sprintf("strings.containsStr(v: strings.toLower(v: r[%s]), substr: %s)", String(column), String(strings.ToLower(substr))),
+		imports: []string{"strings"},
+	}
+}
+func Matches(column, pattern string) Predicate {
+	return Predicate{
+		expr:    fmt.Sprintf("r[%s] =~ regexp.compile(v: %s)", String(column), String(pattern)),
+		imports: []string{"regexp"},
+	}
+}
+func Exists(column string) Predicate {
+	return Predicate{expr: fmt.Sprintf("exists r[%s]", String(column))}
+}
+func If(condition, then, otherwise Predicate) Predicate {
+	imports := append(append(append([]string(nil), condition.imports...), then.imports...), otherwise.imports...)
+	return Predicate{
+		expr:    fmt.Sprintf("if %s then (%s) else (%s)", condition.expr, then.expr, otherwise.expr),
+		imports: imports,
+	}
+}
+func And(predicates ...Predicate) Predicate {
+	return join(" and ", predicates)
+}
+func Or(predicates ...Predicate) Predicate {
+	return join(" or ", predicates)
+}
+func compare(column, operator, value string) Predicate {
+	return Predicate{expr: fmt.Sprintf("r[%s] %s %s", String(column), operator, String(value))}
+}
+func join(operator string, predicates []Predicate) Predicate {
+	if len(predicates) == 1 {
+		return predicates[0]
+	}
+	parts := make([]string, len(predicates))
+	var imports []string
+	for i, predicate := range predicates {
+		parts[i] = "(" + predicate.expr + ")"
+		imports = append(imports, predicate.imports...)
+	}
+	return Predicate{expr: strings.Join(parts, operator), imports: imports}
+}
+func String(value string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for i := 0; i < len(value); i++ {
+		switch c := value[i]; c {
+		case '\\', '"':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case '$':
+			if i+1 < len(value) && value[i+1] == '{' {
+				b.WriteString(`\${`)
+				i++
+				continue
+			}
+			b.WriteByte(c)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+func List(values []string) string {
+	quoted := make([]string, len(values))
+	for i, value := range values {
+		quoted[i] = String(value)
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
+func Duration(d time.Duration) string {
+	if d < time.Second {
+		d = time.Second
+	}
+	return fmt.Sprintf("%ds", int64(d/time.Second))
+}
+func Time(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
\ No newline at end of file
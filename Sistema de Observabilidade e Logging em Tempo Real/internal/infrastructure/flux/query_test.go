@@ -0,0 +1,136 @@
+package flux
+import (
+	"strings"
+	"testing"
+	"time"
+)
+func unquote(t *testing.T, s string) (string, string) {
+	t.Helper()
+	if !strings.HasPrefix(s, `"`) {
+		t.Fatalf("expected string literal, got %q", s)
+	}
+	var b strings.Builder
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '"':
+			return b.String(), s[i+1:]
+		case '$':
+			if i+1 < len(s) && s[i+1] == '{' {
+				t.Fatalf("unescaped interpolation in %q", s)
+			}
+			b.WriteByte('$')
+		case '\\':
+			i++
+			if i >= len(s) {
+				t.Fatalf("dangling escape in %q", s)
+			}
+			switch s[i] {
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			case 't':
+				b.WriteByte('\t')
+			case '\\', '"':
+				b.WriteByte(s[i])
+			case '$':
+				if i+1 >= len(s) || s[i+1] != '{' {
+					t.Fatalf("invalid escape in %q", s)
+				}
+				b.WriteString("${")
+				i++
+			default:
+				t.Fatalf("invalid escape \\%c in %q", s[i], s)
+			}
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	t.Fatalf("unterminated string literal %q", s)
+	return "", ""
+}
+func TestQueryBuilderStages(t *testing.T) {
+	query := From("metrics").
+		Range(time.Hour).
+		Filter(Equal("_measurement", "container_metrics")).
+		Filter(Or(Equal("container_id", "a"), Equal("container_id", "b"))).
+		AggregateWindow(5*time.Minute, Mean).
+		Group("container_id").
+		Limit(10).
+		String()
+	expected := `from(bucket: "metrics")
+|> range(start: -3600s)
+|> filter(fn: (r) => r["_measurement"] == "container_metrics")
+|> filter(fn: (r) => (r["container_id"] == "a") or (r["container_id"] == "b"))
+|> aggregateWindow(every: 300s, fn: mean, createEmpty: false)
+|> group(columns: ["container_id"])
+|> limit(n: 10)`
+	if query != expected {
+		t.Fatalf("unexpected query:\n%s", query)
+	}
+}
+func TestQueryBuilderSkipsEmptyFilter(t *testing.T) {
+	query := From("metrics").RangeBetween(time.Unix(0, 0), time.Unix(60, 0)).Filter().String()
+	if query != "from(bucket: \"metrics\")\n|> range(start: 1970-01-01T00:00:00Z, stop: 1970-01-01T00:01:00Z)" {
+		t.Fatalf("unexpected query:\n%s", query)
+	}
+}
+func TestQueryBuilderImportsFunctionPackages(t *testing.T) {
+	query := From("metrics").
+		Range(time.Hour).
+		Filter(ContainsFold("_value", "Timeout"), Matches("_value", `^err\s`)).
+		Pivot([]string{"_time"}, []string{"_field"}, "_value").
+		Filter(ContainsFold("_value", "db")).
+		Sort(true, "_time").
+		String()
+	expected := `import "strings"
+import "regexp"
+from(bucket: "metrics")
+|> range(start: -3600s)
+|> filter(fn: (r) => (strings.containsStr(v: strings.toLower(v: r["_value"]), substr: "timeout")) and (r["_value"] =~ regexp.compile(v: "^err\\s")))
+|> pivot(rowKey: ["_time"], columnKey: ["_field"], valueColumn: "_value")
+|> filter(fn: (r) => strings.containsStr(v: strings.toLower(v: r["_value"]), substr: "db"))
+|> sort(columns: ["_time"], desc: true)`
+	if query != expected {
+		t.Fatalf("unexpected query:\n%s", query)
+	}
+}
+func TestQueryBuilderConditionalPredicates(t *testing.T) {
+	query := From("logs").
+		Range(time.Hour).
+		Filter(If(Exists("user_id"), Equal("user_id", "42"), And(Exists("fields"), Matches("fields", `"user_id":"42"`)))).
+		String()
+	expected := `import "regexp"
+from(bucket: "logs")
+|> range(start: -3600s)
+|> filter(fn: (r) => if exists r["user_id"] then (r["user_id"] == "42") else ((exists r["fields"]) and (r["fields"] =~ regexp.compile(v: "\"user_id\":\"42\""))))`
+	if query != expected {
+		t.Fatalf("unexpected query:\n%s", query)
+	}
+}
+func FuzzFilterCannotEscapeLiteral(f *testing.F) {
+	f.Add("abc123")
+	f.Add(`") or true or ("`)
+	f.Add(`\") |> drop(columns: ["_value"]) //`)
+	f.Add("${token}")
+	f.Add("\\${x}\"\n|> yield()")
+	f.Add("$${{")
+	f.Fuzz(func(t *testing.T, containerID string) {
+		query := From("metrics").Range(time.Hour).Filter(Equal("container_id", containerID)).Limit(1).String()
+		lines := strings.SplitN(query, "\n", 4)
+		if len(lines) != 4 || lines[3] != "|> limit(n: 1)" {
+			t.Fatalf("query structure changed: %q", query)
+		}
+		prefix := `|> filter(fn: (r) => r["container_id"] == `
+		if !strings.HasPrefix(lines[2], prefix) {
+			t.Fatalf("filter stage changed: %q", lines[2])
+		}
+		value, rest := unquote(t, strings.TrimPrefix(lines[2], prefix))
+		if value != containerID {
+			t.Fatalf("literal round trip mismatch: %q != %q", value, containerID)
+		}
+		if rest != ")" {
+			t.Fatalf("literal escaped the filter, trailing %q", rest)
+		}
+	})
+}
\ No newline at end of file
@@ -0,0 +1,79 @@
+package grpc
+import (
+	"time"
+	"observability-system/internal/domain/entities"
+	pb "observability-system/proto/gen"
+)
+func (s *MetricsServer) Broadcast(samples []entities.Sample) error {
+	for _, metric := range MetricDataFromSamples(samples) {
+		s.broadcastMetric(metric)
+	}
+	return nil
+}
+func MetricDataFromSamples(samples []entities.Sample) []*pb.MetricData {
+	grouped := make(map[string][]entities.Sample)
+	for _, sample := range samples {
+		id := sample.Labels["container_id"]
+		grouped[id] = append(grouped[id], sample)
+	}
+	var result []*pb.MetricData
+	for _, metrics := range entities.ContainerMetricsFromSamples(samples) {
+		result = append(result, &pb.MetricData{
+			ContainerId:   metrics.ContainerID,
+			ContainerName: metrics.ContainerName,
+			CpuPercent:    metrics.CPUPercent,
+			MemoryUsage:   metrics.MemoryUsage,
+			MemoryLimit:   metrics.MemoryLimit,
+			MemoryPercent: metrics.MemoryPercent,
+			NetworkRx:     metrics.NetworkRx,
+			NetworkTx:     metrics.NetworkTx,
+			Timestamp:     metrics.Timestamp.Unix(),
+			Samples:       SamplesToProto(grouped[metrics.ContainerID]),
+		})
+		delete(grouped, metrics.ContainerID)
+	}
+	for id, rest := range grouped {
+		result = append(result, &pb.MetricData{ContainerId: id, Samples: SamplesToProto(rest)})
+	}
+	return result
+}
+func SamplesToProto(samples []entities.Sample) []*pb.Sample {
+	result := make([]*pb.Sample, 0, len(samples))
+	for _, sample := range samples {
+		buckets := make([]*pb.HistogramBucket, 0, len(sample.Buckets))
+		for _, bucket := range sample.Buckets {
+			buckets = append(buckets, &pb.HistogramBucket{UpperBound: bucket.UpperBound, Count: bucket.Count})
+		}
+		result = append(result, &pb.Sample{
+			Name:      sample.Name,
+			Labels:    sample.Labels,
+			Value:     sample.Value,
+			Type:      string(sample.Type),
+			Unit:      sample.Unit,
+			Timestamp: sample.Timestamp.UnixNano(),
+			Count:     sample.Count,
+			Buckets:   buckets,
+		})
+	}
+	return result
+}
+func SamplesFromProto(samples []*pb.Sample) []entities.Sample {
+	result := make([]entities.Sample, 0, len(samples))
+	for _, sample := range samples {
+		var buckets []entities.HistogramBucket
+		for _, bucket := range sample.Buckets {
+			buckets = append(buckets, entities.HistogramBucket{UpperBound: bucket.UpperBound, Count: bucket.Count})
+		}
+		result = append(result, entities.Sample{
+			Name:      sample.Name,
+			Labels:    sample.Labels,
+			Value:     sample.Value,
+			Type:      entities.MetricType(sample.Type),
+			Unit:      sample.Unit,
+			Timestamp: time.Unix(0, sample.Timestamp),
+			Count:     sample.Count,
+			Buckets:   buckets,
+		})
+	}
+	return result
+}
\ No newline at end of file
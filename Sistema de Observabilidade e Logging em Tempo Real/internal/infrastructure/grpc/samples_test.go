@@ -0,0 +1,34 @@
+package grpc
+import (
+	"reflect"
+	"testing"
+	"time"
+	"google.golang.org/protobuf/proto"
+	"observability-system/internal/domain/entities"
+	pb "observability-system/proto/gen"
+)
+func TestSamplesSurviveTheWireFormat(t *testing.T) {
+	at := time.Unix(1700000000, 123456789)
+	samples := []entities.Sample{
+		{Name: "cpu_percent", Labels: map[string]string{"container_id": "c1", "container_name": "api"}, Value: 42.5, Type: entities.MetricTypeGauge, Unit: "percent", Timestamp: at},
+		{Name: "request_seconds", Labels: map[string]string{"container_id": "c2"}, Value: 3.5, Type: entities.MetricTypeHistogram, Unit: "seconds", Count: 7, Buckets: []entities.HistogramBucket{{UpperBound: 0.5, Count: 4}, {UpperBound: 1, Count: 7}}, Timestamp: at},
+	}
+	data, err := proto.Marshal(&pb.MetricData{Samples: SamplesToProto(samples)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded pb.MetricData
+	if err := proto.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	got := SamplesFromProto(decoded.Samples)
+	for i := range got {
+		if !got[i].Timestamp.Equal(samples[i].Timestamp) {
+			t.Fatalf("sample %d: expected timestamp %s, got %s", i, samples[i].Timestamp, got[i].Timestamp)
+		}
+		got[i].Timestamp = samples[i].Timestamp
+	}
+	if !reflect.DeepEqual(got, samples) {
+		t.Fatalf("expected %+v, got %+v", samples, got)
+	}
+}
\ No newline at end of file
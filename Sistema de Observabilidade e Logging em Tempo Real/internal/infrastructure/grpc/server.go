@@ -4,7 +4,6 @@ import (
 	"io"
 	"log"
 	"sync"
-	"time"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	pb "observability-system/proto/gen"
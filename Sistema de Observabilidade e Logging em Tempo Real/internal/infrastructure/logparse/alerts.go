@@ -0,0 +1,80 @@
+package logparse
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"observability-system/internal/domain/entities"
+)
+var logAlertNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+func ParseLogAlertRules(spec string) ([]entities.LogAlertRule, error) {
+	var rules []entities.LogAlertRule
+	names := make(map[string]bool)
+	for number, line := range strings.Split(spec, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		selector, options, err := ParseSelectorLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("log alert rule %d: %w", number+1, err)
+		}
+		rule, err := newLogAlertRule(selector, options)
+		if err != nil {
+			return nil, fmt.Errorf("log alert rule %d: %w", number+1, err)
+		}
+		if names[rule.Name] {
+			return nil, fmt.Errorf("log alert rule %d: duplicate name %q", number+1, rule.Name)
+		}
+		names[rule.Name] = true
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+func newLogAlertRule(selector map[string]string, options map[string]string) (entities.LogAlertRule, error) {
+	rule := entities.LogAlertRule{
+		Window:   entities.DefaultLogAlertWindow,
+		Cooldown: entities.DefaultLogAlertCooldown,
+	}
+	for name, value := range options {
+		var err error
+		switch name {
+		case "name":
+			if !logAlertNamePattern.MatchString(value) {
+				err = fmt.Errorf("must contain only letters, digits, '_' or '-'")
+			}
+			rule.Name = value
+		case "pattern":
+			rule.Pattern, err = regexp.Compile(value)
+		case "threshold":
+			rule.Threshold, err = strconv.Atoi(value)
+			if err == nil && rule.Threshold < 0 {
+				err = fmt.Errorf("must not be negative")
+			}
+		case "window":
+			rule.Window, err = time.ParseDuration(value)
+			if err == nil && rule.Window <= 0 {
+				err = fmt.Errorf("must be positive")
+			}
+		case "cooldown":
+			rule.Cooldown, err = time.ParseDuration(value)
+			if err == nil && rule.Cooldown < 0 {
+				err = fmt.Errorf("must not be negative")
+			}
+		default:
+			return rule, fmt.Errorf("unknown option %q", name)
+		}
+		if err != nil {
+			return rule, fmt.Errorf("invalid %s %q: %w", name, value, err)
+		}
+	}
+	if rule.Name == "" {
+		return rule, fmt.Errorf("a name is required")
+	}
+	if level, ok := selector["level"]; ok {
+		selector["level"] = strings.ToLower(level)
+	}
+	rule.Selector = selector
+	return rule, nil
+}
\ No newline at end of file
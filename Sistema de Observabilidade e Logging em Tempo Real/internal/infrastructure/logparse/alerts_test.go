@@ -0,0 +1,60 @@
+package logparse
+import (
+	"strings"
+	"testing"
+	"time"
+	"observability-system/internal/domain/entities"
+)
+func TestParseLogAlertRules(t *testing.T) {
+	rules, err := ParseLogAlertRules(strings.Join([]string{
+		"# comment",
+		`{container_name="api*"} name=api_timeouts pattern="timed? ?out" threshold=10 window=2m cooldown=10m`,
+		"{level=FATAL} name=fatal",
+	}, "\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rules) != 2 {
+		t.Fatalf("expected 2 rules, got %d", len(rules))
+	}
+	timeouts, fatal := rules[0], rules[1]
+	if timeouts.Threshold != 10 || timeouts.Window != 2*time.Minute || timeouts.Cooldown != 10*time.Minute || timeouts.AlertType() != "LOG_API_TIMEOUTS" {
+		t.Fatalf("unexpected rule %+v", timeouts)
+	}
+	if fatal.Threshold != 0 || fatal.Window != entities.DefaultLogAlertWindow || fatal.Cooldown != entities.DefaultLogAlertCooldown {
+		t.Fatalf("unexpected defaults %+v", fatal)
+	}
+	tests := []struct {
+		rule  entities.LogAlertRule
+		entry *entities.LogEntry
+		want  bool
+	}{
+		{timeouts, &entities.LogEntry{ContainerName: "api-1", Message: "upstream timed out"}, true},
+		{timeouts, &entities.LogEntry{ContainerName: "api-1", Message: "request served"}, false},
+		{timeouts, &entities.LogEntry{ContainerName: "worker", Message: "upstream timeout"}, false},
+		{fatal, &entities.LogEntry{Level: "fatal", Message: "out of memory"}, true},
+		{fatal, &entities.LogEntry{Level: "error", Message: "out of memory"}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.rule.Matches(tt.entry); got != tt.want {
+			t.Errorf("%s.Matches(%+v) = %v, want %v", tt.rule.Name, tt.entry, got, tt.want)
+		}
+	}
+}
+func TestParseLogAlertRulesErrors(t *testing.T) {
+	for spec, want := range map[string]string{
+		"name=missing_selector":    "log alert rule 1: expected {selector}",
+		"{} pattern=x":             "a name is required",
+		"{} name=bad pattern=(":    "invalid pattern",
+		"{} name=neg threshold=-1": "invalid threshold",
+		"{} name=a\n{} name=a":     "log alert rule 2: duplicate name",
+		"{} name=x window=0s":      "invalid window",
+		"{} name=x severity=high":  "unknown option",
+		"{} name=\"has space\"":    "invalid name",
+	} {
+		_, err := ParseLogAlertRules(spec)
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("ParseLogAlertRules(%q) error = %v, want %q", spec, err, want)
+		}
+	}
+}
\ No newline at end of file
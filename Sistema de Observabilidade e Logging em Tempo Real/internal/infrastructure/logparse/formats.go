@@ -0,0 +1,197 @@
+package logparse
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+var (
+	timestampKeys = []string{"timestamp", "@timestamp", "time", "ts", "t", "datetime"}
+	levelKeys     = []string{"level", "lvl", "severity", "loglevel", "log.level", "level_name"}
+	messageKeys   = []string{"message", "msg", "@message", "log"}
+)
+var timestampLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999Z0700",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999 -0700",
+	"02/Jan/2006:15:04:05 -0700",
+	time.RFC1123Z,
+	time.RFC1123,
+	time.RubyDate,
+}
+var localTimestampLayouts = []string{
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+	"2006/01/02 15:04:05.999999999",
+	"2006-01-02 15:04:05,999",
+}
+func parseJSON(line string) (map[string]string, bool) {
+	trimmed := strings.TrimSpace(line)
+	if !strings.HasPrefix(trimmed, "{") || !strings.HasSuffix(trimmed, "}") {
+		return nil, false
+	}
+	decoder := json.NewDecoder(strings.NewReader(trimmed))
+	decoder.UseNumber()
+	var object map[string]interface{}
+	if err := decoder.Decode(&object); err != nil {
+		return nil, false
+	}
+	fields := make(map[string]string)
+	flattenJSON(fields, "", object)
+	return fields, true
+}
+func flattenJSON(fields map[string]string, prefix string, value interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, nested := range v {
+			if prefix != "" {
+				key = prefix + "." + key
+			}
+			flattenJSON(fields, key, nested)
+		}
+	case string:
+		fields[prefix] = v
+	case json.Number:
+		fields[prefix] = v.String()
+	case bool:
+		fields[prefix] = strconv.FormatBool(v)
+	case nil:
+	default:
+		var b bytes.Buffer
+		encoder := json.NewEncoder(&b)
+		encoder.SetEscapeHTML(false)
+		if err := encoder.Encode(v); err == nil {
+			fields[prefix] = strings.TrimSpace(b.String())
+		}
+	}
+}
+func parseLogfmt(line string) (map[string]string, bool) {
+	fields := make(map[string]string)
+	i, n := 0, len(line)
+	for {
+		for i < n && (line[i] == ' ' || line[i] == '\t') {
+			i++
+		}
+		if i >= n {
+			break
+		}
+		start := i
+		for i < n && line[i] != '=' && line[i] != ' ' && line[i] != '\t' && line[i] != '"' {
+			i++
+		}
+		if i == start || i >= n || line[i] != '=' {
+			return nil, false
+		}
+		key := line[start:i]
+		i++
+		if i < n && line[i] == '"' {
+			end := i + 1
+			for end < n && line[end] != '"' {
+				if line[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= n {
+				return nil, false
+			}
+			quoted := line[i : end+1]
+			value, err := strconv.Unquote(quoted)
+			if err != nil {
+				value = quoted[1 : len(quoted)-1]
+			}
+			fields[key] = value
+			i = end + 1
+			continue
+		}
+		valueStart := i
+		for i < n && line[i] != ' ' && line[i] != '\t' {
+			i++
+		}
+		fields[key] = line[valueStart:i]
+	}
+	return fields, len(fields) > 0
+}
+func parseTimestamp(value string, now time.Time) (time.Time, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, false
+	}
+	if number, err := strconv.ParseFloat(value, 64); err == nil {
+		return parseEpoch(number)
+	}
+	for _, layout := range timestampLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, true
+		}
+	}
+	for _, layout := range localTimestampLayouts {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, true
+		}
+	}
+	if t, err := time.ParseInLocation("Jan _2 15:04:05.999999999", value, time.Local); err == nil {
+		t = t.AddDate(now.Year(), 0, 0)
+		if t.After(now.Add(24 * time.Hour)) {
+			t = t.AddDate(-1, 0, 0)
+		}
+		return t, true
+	}
+	return time.Time{}, false
+}
+func parseEpoch(number float64) (time.Time, bool) {
+	if number <= 0 || math.IsInf(number, 0) || math.IsNaN(number) {
+		return time.Time{}, false
+	}
+	switch {
+	case number >= 1e17:
+		return time.Unix(0, int64(number)), true
+	case number >= 1e14:
+		return time.UnixMicro(int64(number)), true
+	case number >= 1e11:
+		return time.UnixMilli(int64(number)), true
+	}
+	seconds, fraction := math.Modf(number)
+	return time.Unix(int64(seconds), int64(fraction*1e9)), true
+}
+func normalizeLevel(value string) (string, bool) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "trace", "10":
+		return "trace", true
+	case "debug", "dbg", "20":
+		return "debug", true
+	case "info", "information", "informational", "notice", "30":
+		return "info", true
+	case "warn", "warning", "40":
+		return "warn", true
+	case "error", "err", "eror", "50":
+		return "error", true
+	case "fatal", "critical", "crit", "panic", "alert", "emerg", "emergency", "60":
+		return "fatal", true
+	}
+	return "", false
+}
+func detectLevel(message string) string {
+	tokens := strings.Fields(message)
+	if len(tokens) > 4 {
+		tokens = tokens[:4]
+	}
+	for i, token := range tokens {
+		bracketed := strings.HasPrefix(token, "[") || strings.HasSuffix(token, "]") || strings.HasSuffix(token, ":")
+		trimmed := strings.Trim(token, "[]:")
+		if i > 0 && !bracketed && trimmed != strings.ToUpper(trimmed) {
+			continue
+		}
+		if level, ok := normalizeLevel(trimmed); ok && !isNumeric(trimmed) {
+			return level
+		}
+	}
+	return ""
+}
+func isNumeric(value string) bool {
+	_, err := strconv.Atoi(value)
+	return err == nil
+}
\ No newline at end of file
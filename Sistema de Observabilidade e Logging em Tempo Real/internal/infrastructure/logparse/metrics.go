@@ -0,0 +1,200 @@
+package logparse
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"observability-system/internal/domain/entities"
+)
+const maxLogMetricSeries = 10000
+var metricNamePattern = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+type extractedSeries struct {
+	sample entities.Sample
+	dirty  bool
+}
+type MetricExtractor struct {
+	rules   []entities.LogMetricRule
+	series  map[string]*extractedSeries
+	dropped bool
+}
+func ParseLogMetricRules(spec string) ([]entities.LogMetricRule, error) {
+	var rules []entities.LogMetricRule
+	types := make(map[string]entities.MetricType)
+	for number, line := range strings.Split(spec, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		selector, options, err := ParseSelectorLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("log metric rule %d: %w", number+1, err)
+		}
+		rule, err := newLogMetricRule(selector, options)
+		if err != nil {
+			return nil, fmt.Errorf("log metric rule %d: %w", number+1, err)
+		}
+		if previous, ok := types[rule.Name]; ok && previous != rule.Type {
+			return nil, fmt.Errorf("log metric rule %d: %s is already defined as a %s", number+1, rule.Name, previous)
+		}
+		types[rule.Name] = rule.Type
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+func newLogMetricRule(selector map[string]string, options map[string]string) (entities.LogMetricRule, error) {
+	rule := entities.LogMetricRule{Type: entities.MetricTypeCounter, Selector: selector}
+	for name, value := range options {
+		var err error
+		switch name {
+		case "name":
+			if !metricNamePattern.MatchString(value) {
+				err = fmt.Errorf("not a valid metric name")
+			}
+			rule.Name = value
+		case "type":
+			rule.Type = entities.MetricType(value)
+			if rule.Type != entities.MetricTypeCounter && rule.Type != entities.MetricTypeHistogram {
+				err = fmt.Errorf("must be counter or histogram")
+			}
+		case "pattern":
+			rule.Pattern, err = regexp.Compile(value)
+		case "field":
+			rule.ValueField = value
+		case "labels":
+			rule.Labels = strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ' ' })
+		case "buckets":
+			rule.Buckets, err = parseBuckets(value)
+		case "unit":
+			rule.Unit = value
+		default:
+			return rule, fmt.Errorf("unknown option %q", name)
+		}
+		if err != nil {
+			return rule, fmt.Errorf("invalid %s %q: %w", name, value, err)
+		}
+	}
+	if rule.Name == "" {
+		return rule, fmt.Errorf("a name is required")
+	}
+	if rule.Type == entities.MetricTypeHistogram {
+		if rule.ValueField == "" {
+			return rule, fmt.Errorf("histogram %s needs a value field", rule.Name)
+		}
+		if rule.Buckets == nil {
+			rule.Buckets = entities.DefaultLogMetricBuckets
+		}
+	} else if rule.Buckets != nil {
+		return rule, fmt.Errorf("buckets only apply to histograms")
+	}
+	return rule, nil
+}
+func parseBuckets(value string) ([]float64, error) {
+	var buckets []float64
+	for _, part := range strings.Split(value, ",") {
+		bound, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return nil, err
+		}
+		if len(buckets) > 0 && bound <= buckets[len(buckets)-1] {
+			return nil, fmt.Errorf("bucket bounds must increase")
+		}
+		buckets = append(buckets, bound)
+	}
+	return buckets, nil
+}
+func parseMetricValue(value string) (float64, bool) {
+	if number, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+		return number, true
+	}
+	if duration, err := time.ParseDuration(strings.TrimSpace(value)); err == nil {
+		return duration.Seconds(), true
+	}
+	return 0, false
+}
+func NewMetricExtractor(rules []entities.LogMetricRule) *MetricExtractor {
+	return &MetricExtractor{
+		rules:  rules,
+		series: make(map[string]*extractedSeries),
+	}
+}
+func (m *MetricExtractor) Observe(entry *entities.LogEntry) {
+	if len(m.rules) == 0 {
+		return
+	}
+	labels := entry.LabelSet()
+	for _, rule := range m.rules {
+		if !rule.Matches(entry, labels) {
+			continue
+		}
+		value := 1.0
+		if rule.ValueField != "" {
+			var ok bool
+			if value, ok = parseMetricValue(labels[rule.ValueField]); !ok {
+				continue
+			}
+		}
+		if rule.Type == entities.MetricTypeCounter && value < 0 {
+			continue
+		}
+		series := m.lookup(rule, rule.SeriesLabels(entry, labels))
+		if series == nil {
+			continue
+		}
+		series.dirty = true
+		series.sample.Value += value
+		if rule.Type == entities.MetricTypeHistogram {
+			series.sample.Count++
+			for i := range series.sample.Buckets {
+				if value <= series.sample.Buckets[i].UpperBound {
+					series.sample.Buckets[i].Count++
+				}
+			}
+		}
+	}
+}
+func (m *MetricExtractor) lookup(rule entities.LogMetricRule, labels map[string]string) *extractedSeries {
+	key := entities.SeriesKey(rule.Name, labels)
+	if series, ok := m.series[key]; ok {
+		return series
+	}
+	if len(m.series) >= maxLogMetricSeries {
+		if !m.dropped {
+			log.Printf("Log metrics reached %d series, dropping new series such as %s", maxLogMetricSeries, key)
+			m.dropped = true
+		}
+		return nil
+	}
+	series := &extractedSeries{sample: entities.Sample{
+		Name:   rule.Name,
+		Labels: labels,
+		Type:   rule.Type,
+		Unit:   rule.Unit,
+	}}
+	for _, bound := range rule.Buckets {
+		series.sample.Buckets = append(series.sample.Buckets, entities.HistogramBucket{UpperBound: bound})
+	}
+	m.series[key] = series
+	return series
+}
+func (m *MetricExtractor) Collect(now time.Time) []entities.Sample {
+	keys := make([]string, 0, len(m.series))
+	for key, series := range m.series {
+		if series.dirty {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	samples := make([]entities.Sample, 0, len(keys))
+	for _, key := range keys {
+		series := m.series[key]
+		series.dirty = false
+		sample := series.sample
+		sample.Buckets = append([]entities.HistogramBucket(nil), sample.Buckets...)
+		sample.Timestamp = now
+		samples = append(samples, sample)
+	}
+	return samples
+}
\ No newline at end of file
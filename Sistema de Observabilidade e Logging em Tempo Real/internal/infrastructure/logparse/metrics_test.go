@@ -0,0 +1,85 @@
+package logparse
+import (
+	"strings"
+	"testing"
+	"time"
+	"observability-system/internal/domain/entities"
+)
+func TestMetricExtractorDerivesCountersAndHistograms(t *testing.T) {
+	parser, err := NewParser(`{container_name=*demo-app} %{COMMONAPACHELOG}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rules, err := ParseLogMetricRules(strings.Join([]string{
+		`{container_name=*demo-app} name=nginx_http_requests_total labels=response,verb`,
+		`{container_name=*demo-app} name=nginx_http_response_bytes_total field=bytes unit=bytes`,
+		`{latency=*} name=http_request_duration_seconds type=histogram field=latency buckets=0.1,0.5,1 labels=route`,
+	}, "\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	extractor := NewMetricExtractor(rules)
+	lines := []string{
+		`10.0.0.1 - - [01/Mar/2024:10:00:00 +0000] "GET / HTTP/1.1" 200 612`,
+		`10.0.0.1 - - [01/Mar/2024:10:00:01 +0000] "GET /missing HTTP/1.1" 404 153`,
+		`10.0.0.2 - - [01/Mar/2024:10:00:02 +0000] "GET / HTTP/1.1" 200 612`,
+	}
+	for _, line := range lines {
+		extractor.Observe(parseLine(t, parser, entities.LogEntry{ContainerID: "c1", ContainerName: "observability-demo-app", Message: line}))
+	}
+	for _, latency := range []string{"0.05", "250ms", "0.7", "3", "not-a-number"} {
+		extractor.Observe(parseLine(t, parser, entities.LogEntry{ContainerID: "api", Message: `{"msg":"done","route":"/users","latency":"` + latency + `"}`}))
+	}
+	now := time.Date(2024, 3, 1, 10, 1, 0, 0, time.UTC)
+	samples := extractor.Collect(now)
+	byKey := make(map[string]entities.Sample)
+	for _, sample := range samples {
+		if !sample.Timestamp.Equal(now) {
+			t.Fatalf("unexpected sample timestamp %s", sample.Timestamp)
+		}
+		byKey[entities.SeriesKey(sample.Name, sample.Labels)] = sample
+	}
+	if len(samples) != 4 {
+		t.Fatalf("expected 4 series, got %v", byKey)
+	}
+	ok := entities.SeriesKey("nginx_http_requests_total", map[string]string{"container_id": "c1", "container_name": "observability-demo-app", "response": "200", "verb": "GET"})
+	if got := byKey[ok]; got.Value != 2 || got.Type != entities.MetricTypeCounter {
+		t.Fatalf("unexpected 200 counter %+v", got)
+	}
+	bytes := entities.SeriesKey("nginx_http_response_bytes_total", map[string]string{"container_id": "c1", "container_name": "observability-demo-app"})
+	if got := byKey[bytes]; got.Value != 1377 || got.Unit != "bytes" {
+		t.Fatalf("unexpected bytes counter %+v", got)
+	}
+	latency := byKey[entities.SeriesKey("http_request_duration_seconds", map[string]string{"container_id": "api", "route": "/users"})]
+	if latency.Type != entities.MetricTypeHistogram || latency.Count != 4 || latency.Value != 4 {
+		t.Fatalf("unexpected histogram %+v", latency)
+	}
+	for i, want := range []uint64{1, 2, 3} {
+		if latency.Buckets[i].Count != want {
+			t.Fatalf("unexpected buckets %+v", latency.Buckets)
+		}
+	}
+	if got := extractor.Collect(now); len(got) != 0 {
+		t.Fatalf("expected only changed series, got %v", got)
+	}
+	extractor.Observe(parseLine(t, parser, entities.LogEntry{ContainerID: "c1", ContainerName: "observability-demo-app", Message: lines[0]}))
+	if got := extractor.Collect(now); len(got) != 2 || got[0].Value != 3 {
+		t.Fatalf("expected cumulative counters for changed series, got %+v", got)
+	}
+}
+func TestParseLogMetricRulesErrors(t *testing.T) {
+	for spec, want := range map[string]string{
+		"{} type=counter":                              "a name is required",
+		"{} name=bad-name":                             "not a valid metric name",
+		"{} name=x type=gauge":                         "must be counter or histogram",
+		"{} name=x type=histogram":                     "needs a value field",
+		"{} name=x type=histogram field=v buckets=1,1": "bucket bounds must increase",
+		"{} name=x buckets=1":                          "buckets only apply to histograms",
+		"{} name=x\n{} name=x type=histogram field=v":  "log metric rule 2: x is already defined as a counter",
+	} {
+		_, err := ParseLogMetricRules(spec)
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("ParseLogMetricRules(%q) error = %v, want %q", spec, err, want)
+		}
+	}
+}
\ No newline at end of file
@@ -0,0 +1,193 @@
+package logparse
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"observability-system/internal/domain/entities"
+)
+const (
+	defaultMultilineMaxLines = 500
+	defaultMultilineTimeout  = 2 * time.Second
+)
+type MultilineRule struct {
+	Selector map[string]string
+	Start    *regexp.Regexp
+	Continue *regexp.Regexp
+	MaxLines int
+	Timeout  time.Duration
+}
+var MultilinePresets = map[string]MultilineRule{
+	"go": {
+		Continue: regexp.MustCompile(`^(\s*$|goroutine \d+ \[|\t|\[signal |created by |exit status \d+$|[\w./*()\[\]-]+\(.*\)$)`),
+	},
+	"java": {
+		Continue: regexp.MustCompile(`^(\s+at\s|\s*\.\.\. \d+ (more|common frames omitted)|\s*Caused by:|\s*Suppressed:|[\w$.]+(Exception|Error|Throwable)(: .*)?$)`),
+	},
+	"python": {
+		Continue: regexp.MustCompile(`^(\s+|\s*$|Traceback \(most recent call last\):|During handling of the above exception|The above exception was the direct cause|\w*(Error|Exception|Warning|Exit|Interrupt)(: .*)?$)`),
+	},
+}
+type pendingEvent struct {
+	entry   *entities.LogEntry
+	lines   []string
+	rule    *MultilineRule
+	updated time.Time
+}
+type Multiline struct {
+	rules   []MultilineRule
+	pending map[string]*pendingEvent
+	now     func() time.Time
+}
+func ParseMultilineRules(spec string) ([]MultilineRule, error) {
+	var rules []MultilineRule
+	for number, line := range strings.Split(spec, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		selector, options, err := ParseSelectorLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("multiline rule %d: %w", number+1, err)
+		}
+		rule, err := newMultilineRule(selector, options)
+		if err != nil {
+			return nil, fmt.Errorf("multiline rule %d: %w", number+1, err)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+func newMultilineRule(selector map[string]string, options map[string]string) (MultilineRule, error) {
+	var rule MultilineRule
+	for name, value := range options {
+		var err error
+		switch name {
+		case "preset":
+			preset, ok := MultilinePresets[value]
+			if !ok {
+				return rule, fmt.Errorf("unknown multiline preset %q", value)
+			}
+			if rule.Start == nil {
+				rule.Start = preset.Start
+			}
+			if rule.Continue == nil {
+				rule.Continue = preset.Continue
+			}
+		case "start":
+			rule.Start, err = regexp.Compile(value)
+		case "continue":
+			rule.Continue, err = regexp.Compile(value)
+		case "max_lines":
+			rule.MaxLines, err = strconv.Atoi(value)
+			if err == nil && rule.MaxLines <= 0 {
+				err = fmt.Errorf("must be positive")
+			}
+		case "timeout":
+			rule.Timeout, err = time.ParseDuration(value)
+			if err == nil && rule.Timeout <= 0 {
+				err = fmt.Errorf("must be positive")
+			}
+		default:
+			return rule, fmt.Errorf("unknown option %q", name)
+		}
+		if err != nil {
+			return rule, fmt.Errorf("invalid %s %q: %w", name, value, err)
+		}
+	}
+	if rule.Start == nil && rule.Continue == nil {
+		return rule, fmt.Errorf("a start or continue pattern is required")
+	}
+	rule.Selector = selector
+	if rule.MaxLines == 0 {
+		rule.MaxLines = defaultMultilineMaxLines
+	}
+	if rule.Timeout == 0 {
+		rule.Timeout = defaultMultilineTimeout
+	}
+	return rule, nil
+}
+func NewMultiline(rules []MultilineRule) *Multiline {
+	return &Multiline{
+		rules:   rules,
+		pending: make(map[string]*pendingEvent),
+		now:     time.Now,
+	}
+}
+func (m *Multiline) Add(entry *entities.LogEntry) []*entities.LogEntry {
+	rule := m.match(entry)
+	if rule == nil {
+		return []*entities.LogEntry{entry}
+	}
+	key := streamKey(entry)
+	now := m.now()
+	pending := m.pending[key]
+	if pending != nil && rule.continues(entry.Message) {
+		pending.lines = append(pending.lines, entry.Message)
+		pending.updated = now
+		if len(pending.lines) >= rule.MaxLines {
+			delete(m.pending, key)
+			return []*entities.LogEntry{pending.finish()}
+		}
+		return nil
+	}
+	var completed []*entities.LogEntry
+	if pending != nil {
+		completed = append(completed, pending.finish())
+	}
+	m.pending[key] = &pendingEvent{
+		entry:   entry,
+		lines:   []string{entry.Message},
+		rule:    rule,
+		updated: now,
+	}
+	return completed
+}
+func (m *Multiline) Expire(now time.Time) []*entities.LogEntry {
+	return m.flush(func(pending *pendingEvent) bool {
+		return !now.Before(pending.updated.Add(pending.rule.Timeout))
+	})
+}
+func (m *Multiline) Drain() []*entities.LogEntry {
+	return m.flush(func(*pendingEvent) bool { return true })
+}
+func (m *Multiline) flush(expired func(*pendingEvent) bool) []*entities.LogEntry {
+	var completed []*entities.LogEntry
+	for key, pending := range m.pending {
+		if expired(pending) {
+			completed = append(completed, pending.finish())
+			delete(m.pending, key)
+		}
+	}
+	sort.SliceStable(completed, func(i, j int) bool {
+		return completed[i].Timestamp.Before(completed[j].Timestamp)
+	})
+	return completed
+}
+func (m *Multiline) match(entry *entities.LogEntry) *MultilineRule {
+	if len(m.rules) == 0 {
+		return nil
+	}
+	labels := entry.LabelSet()
+	for i := range m.rules {
+		if selectorMatches(m.rules[i].Selector, labels) {
+			return &m.rules[i]
+		}
+	}
+	return nil
+}
+func (r *MultilineRule) continues(line string) bool {
+	if r.Continue != nil && r.Continue.MatchString(line) {
+		return true
+	}
+	return r.Start != nil && !r.Start.MatchString(line)
+}
+func (p *pendingEvent) finish() *entities.LogEntry {
+	p.entry.Message = strings.Join(p.lines, "\n")
+	return p.entry
+}
+func streamKey(entry *entities.LogEntry) string {
+	return strings.Join([]string{entry.Source, entry.ContainerID, string(entry.Stream), entry.Labels["filename"]}, "\x00")
+}
\ No newline at end of file
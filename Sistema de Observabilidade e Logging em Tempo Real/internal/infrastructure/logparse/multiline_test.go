@@ -0,0 +1,109 @@
+package logparse
+import (
+	"strings"
+	"testing"
+	"time"
+	"observability-system/internal/domain/entities"
+)
+func feedMultiline(m *Multiline, source string, lines ...string) []*entities.LogEntry {
+	var completed []*entities.LogEntry
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, line := range lines {
+		entry := &entities.LogEntry{
+			Timestamp:   base.Add(time.Duration(i) * time.Millisecond),
+			Source:      "docker",
+			ContainerID: source,
+			Stream:      entities.LogStreamStderr,
+			Message:     line,
+		}
+		completed = append(completed, m.Add(entry)...)
+	}
+	return completed
+}
+func messages(entries []*entities.LogEntry) []string {
+	var result []string
+	for _, entry := range entries {
+		result = append(result, entry.Message)
+	}
+	return result
+}
+func TestMultilinePresetsStitchStackTraces(t *testing.T) {
+	rules, err := ParseMultilineRules(strings.Join([]string{
+		"{container_id=go-app} preset=go",
+		"{container_id=java-app} preset=java",
+		"{container_id=py-app} preset=python",
+	}, "\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	goPanic := []string{
+		"panic: runtime error: index out of range [3] with length 3",
+		"",
+		"goroutine 1 [running]:",
+		"main.main()",
+		"\t/app/main.go:12 +0x1d",
+		"exit status 2",
+	}
+	javaTrace := []string{
+		"2024-01-01 00:00:00 ERROR OrderService - checkout failed",
+		"java.lang.IllegalStateException: cart is empty",
+		"\tat com.shop.OrderService.checkout(OrderService.java:42)",
+		"\tat com.shop.Api.handle(Api.java:10)",
+		"Caused by: java.lang.NullPointerException",
+		"\t... 12 more",
+	}
+	pythonTrace := []string{
+		"ERROR:root:job failed",
+		"Traceback (most recent call last):",
+		`  File "/app/job.py", line 3, in <module>`,
+		"    run()",
+		"ValueError: bad input",
+	}
+	for _, tc := range []struct {
+		source string
+		lines  []string
+	}{
+		{"go-app", goPanic},
+		{"java-app", javaTrace},
+		{"py-app", pythonTrace},
+	} {
+		m := NewMultiline(rules)
+		completed := feedMultiline(m, tc.source, append(append([]string{"service started"}, tc.lines...), "next event")...)
+		completed = append(completed, m.Drain()...)
+		got := messages(completed)
+		if len(got) != 3 || got[0] != "service started" || got[1] != strings.Join(tc.lines, "\n") || got[2] != "next event" {
+			t.Fatalf("%s: unexpected events %q", tc.source, got)
+		}
+	}
+}
+func TestMultilineStartPatternMaxLinesAndTimeout(t *testing.T) {
+	rules, err := ParseMultilineRules(`{source=docker} start="^\d{4}-\d{2}-\d{2}" max_lines=3 timeout=5s`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := NewMultiline(rules)
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	m.now = func() time.Time { return now }
+	completed := feedMultiline(m, "a", "2024-01-01 first", "  detail 1", "  detail 2", "  detail 3", "2024-01-01 second")
+	if got := messages(completed); len(got) != 2 || got[0] != "2024-01-01 first\n  detail 1\n  detail 2" || got[1] != "  detail 3" {
+		t.Fatalf("expected max_lines to split the event, got %q", got)
+	}
+	completed = feedMultiline(m, "b", "2024-01-01 other stream")
+	if len(completed) != 0 {
+		t.Fatalf("streams must be stitched independently, got %q", messages(completed))
+	}
+	if expired := m.Expire(now.Add(4 * time.Second)); len(expired) != 0 {
+		t.Fatalf("nothing should expire before the timeout, got %q", messages(expired))
+	}
+	if expired := messages(m.Expire(now.Add(5 * time.Second))); len(expired) != 2 || expired[0] != "2024-01-01 other stream" || expired[1] != "2024-01-01 second" {
+		t.Fatalf("expected pending events flushed after the timeout, got %q", expired)
+	}
+	if passthrough := NewMultiline(rules).Add(&entities.LogEntry{Source: "file", Message: "x"}); len(passthrough) != 1 {
+		t.Fatalf("entries without a matching rule should pass straight through")
+	}
+	for _, spec := range []string{"{source=docker}", "{source=docker} preset=ruby", `{source=docker} start="("`, "{source=docker} continue=x max_lines=0", "preset=go"} {
+		if _, err := ParseMultilineRules(spec); err == nil {
+			t.Fatalf("expected error for %q", spec)
+		}
+	}
+}
\ No newline at end of file
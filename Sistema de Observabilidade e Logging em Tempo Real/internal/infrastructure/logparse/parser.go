@@ -0,0 +1,179 @@
+package logparse
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"observability-system/internal/domain/entities"
+)
+var patternName = regexp.MustCompile(`^[A-Z][A-Z0-9_]*$`)
+type Rule struct {
+	Selector map[string]string
+	Pattern  string
+	compiled *pattern
+}
+type Parser struct {
+	rules []Rule
+	now   func() time.Time
+}
+func NewParser(spec string) (*Parser, error) {
+	custom := make(map[string]string)
+	var rules []Rule
+	for number, line := range strings.Split(spec, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "{") {
+			end := strings.Index(line, "}")
+			if end < 0 {
+				return nil, fmt.Errorf("log parse rule %d: unterminated selector", number+1)
+			}
+			selector, err := parseSelector(line[1:end])
+			if err != nil {
+				return nil, fmt.Errorf("log parse rule %d: %w", number+1, err)
+			}
+			rules = append(rules, Rule{Selector: selector, Pattern: strings.TrimSpace(line[end+1:])})
+			continue
+		}
+		name, definition, _ := strings.Cut(line, " ")
+		if !patternName.MatchString(name) || strings.TrimSpace(definition) == "" {
+			return nil, fmt.Errorf("log parse rule %d: expected {selector} <pattern> or NAME <pattern>", number+1)
+		}
+		custom[name] = strings.TrimSpace(definition)
+	}
+	for i := range rules {
+		if rules[i].Pattern == "" {
+			return nil, fmt.Errorf("log parse rule %s: missing pattern", selectorString(rules[i].Selector))
+		}
+		compiled, err := compilePattern(rules[i].Pattern, custom)
+		if err != nil {
+			return nil, fmt.Errorf("log parse rule %s: %w", selectorString(rules[i].Selector), err)
+		}
+		rules[i].compiled = compiled
+	}
+	return &Parser{rules: rules, now: time.Now}, nil
+}
+func (p *Parser) Parse(entry *entities.LogEntry) {
+	fields, ok := p.applyRules(entry)
+	if !ok {
+		fields, ok = parseJSON(entry.Message)
+	}
+	if !ok {
+		fields, ok = parseLogfmt(entry.Message)
+	}
+	if ok {
+		p.applyFields(entry, fields)
+	}
+	if entry.Level == "" {
+		entry.Level = detectLevel(entry.Message)
+	}
+}
+func (p *Parser) applyRules(entry *entities.LogEntry) (map[string]string, bool) {
+	if len(p.rules) == 0 {
+		return nil, false
+	}
+	labels := entry.LabelSet()
+	for _, rule := range p.rules {
+		if !selectorMatches(rule.Selector, labels) {
+			continue
+		}
+		if fields, ok := rule.compiled.match(entry.Message); ok {
+			return fields, true
+		}
+	}
+	return nil, false
+}
+func (p *Parser) applyFields(entry *entities.LogEntry, fields map[string]string) {
+	for _, key := range timestampKeys {
+		if value, ok := fields[key]; ok {
+			if timestamp, ok := parseTimestamp(value, p.now()); ok {
+				entry.Timestamp = timestamp
+				delete(fields, key)
+			}
+			break
+		}
+	}
+	for _, key := range levelKeys {
+		if value, ok := fields[key]; ok {
+			if level, ok := normalizeLevel(value); ok {
+				entry.Level = level
+			} else {
+				entry.Level = strings.ToLower(value)
+			}
+			delete(fields, key)
+			break
+		}
+	}
+	for _, key := range messageKeys {
+		if value, ok := fields[key]; ok {
+			entry.Message = value
+			delete(fields, key)
+			break
+		}
+	}
+	if len(fields) == 0 {
+		return
+	}
+	if entry.Fields == nil {
+		entry.Fields = make(map[string]string, len(fields))
+	}
+	for name, value := range fields {
+		entry.Fields[name] = value
+	}
+}
+func ParseSelectorLine(line string) (map[string]string, map[string]string, error) {
+	end := strings.Index(line, "}")
+	if !strings.HasPrefix(line, "{") || end < 0 {
+		return nil, nil, fmt.Errorf("expected {selector} key=value ...")
+	}
+	selector, err := parseSelector(line[1:end])
+	if err != nil {
+		return nil, nil, err
+	}
+	options, ok := parseLogfmt(strings.TrimSpace(line[end+1:]))
+	if !ok {
+		return nil, nil, fmt.Errorf("expected key=value options")
+	}
+	return selector, options, nil
+}
+func parseSelector(text string) (map[string]string, error) {
+	selector := make(map[string]string)
+	for _, matcher := range strings.Split(text, ",") {
+		if strings.TrimSpace(matcher) == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(matcher, "=")
+		name, value = strings.TrimSpace(name), strings.Trim(strings.TrimSpace(value), `"`)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid matcher %q", matcher)
+		}
+		selector[name] = value
+	}
+	if err := entities.ValidateLabelSelector(selector); err != nil {
+		return nil, err
+	}
+	return selector, nil
+}
+func selectorMatches(selector map[string]string, labels map[string]string) bool {
+	for name, value := range selector {
+		actual, ok := labels[name]
+		if !ok {
+			return false
+		}
+		if matched, _ := path.Match(value, actual); !matched {
+			return false
+		}
+	}
+	return true
+}
+func selectorString(selector map[string]string) string {
+	pairs := make([]string, 0, len(selector))
+	for name, value := range selector {
+		pairs = append(pairs, name+"="+value)
+	}
+	sort.Strings(pairs)
+	return "{" + strings.Join(pairs, ",") + "}"
+}
\ No newline at end of file
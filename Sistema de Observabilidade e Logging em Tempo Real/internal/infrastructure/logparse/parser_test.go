@@ -0,0 +1,116 @@
+package logparse
+import (
+	"strings"
+	"testing"
+	"time"
+	"observability-system/internal/domain/entities"
+)
+func parseLine(t *testing.T, parser *Parser, entry entities.LogEntry) *entities.LogEntry {
+	t.Helper()
+	if entry.Timestamp.IsZero() {
+		entry.Timestamp = time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	}
+	parser.Parse(&entry)
+	return &entry
+}
+func TestParserAutoDetectsJSONAndLogfmt(t *testing.T) {
+	parser, err := NewParser("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry := parseLine(t, parser, entities.LogEntry{
+		Message: `{"ts":"2024-03-01T10:00:00.5Z","level":"WARNING","msg":"disk almost full","disk":{"mount":"/data","used":0.93},"tags":["a","b"]}`,
+	})
+	if entry.Message != "disk almost full" || entry.Level != "warn" {
+		t.Fatalf("unexpected json parse %+v", entry)
+	}
+	if !entry.Timestamp.Equal(time.Date(2024, 3, 1, 10, 0, 0, 5e8, time.UTC)) {
+		t.Fatalf("unexpected timestamp %s", entry.Timestamp)
+	}
+	if entry.Fields["disk.mount"] != "/data" || entry.Fields["disk.used"] != "0.93" || entry.Fields["tags"] != `["a","b"]` {
+		t.Fatalf("unexpected json fields %v", entry.Fields)
+	}
+	if _, ok := entry.Fields["ts"]; ok {
+		t.Fatalf("extracted keys should not remain in fields: %v", entry.Fields)
+	}
+	entry = parseLine(t, parser, entities.LogEntry{
+		Message: `time=1709287200 level=error msg="request failed: \"timeout\"" path=/api/v1 status=504`,
+	})
+	if entry.Message != `request failed: "timeout"` || entry.Level != "error" || entry.Fields["status"] != "504" || entry.Fields["path"] != "/api/v1" {
+		t.Fatalf("unexpected logfmt parse %+v", entry)
+	}
+	if !entry.Timestamp.Equal(time.Unix(1709287200, 0)) {
+		t.Fatalf("unexpected logfmt timestamp %s", entry.Timestamp)
+	}
+	labels := entry.LabelSet()
+	if labels["status"] != "504" || labels["level"] != "error" {
+		t.Fatalf("parsed fields should be exposed as labels, got %v", labels)
+	}
+}
+func TestParserAppliesGrokAndRegexRulesPerSource(t *testing.T) {
+	spec := strings.Join([]string{
+		"# access logs",
+		`{container_name=nginx*} %{COMBINEDAPACHELOG}`,
+		`{filename=/var/log/app/*.log} ^%{APPTIME:timestamp} \[%{LOGLEVEL:level}\] (?P<component>\w+): %{GREEDYDATA:message}$`,
+		`APPTIME %{TIMESTAMP_ISO8601}`,
+	}, "\n")
+	parser, err := NewParser(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry := parseLine(t, parser, entities.LogEntry{
+		ContainerName: "nginx-edge",
+		Message:       `10.0.0.7 - frank [10/Oct/2000:13:55:36 -0700] "GET /apache_pb.gif?x=1 HTTP/1.0" 200 2326 "http://example.com/start" "Mozilla/4.08"`,
+	})
+	if entry.Fields["clientip"] != "10.0.0.7" || entry.Fields["verb"] != "GET" || entry.Fields["request"] != "/apache_pb.gif?x=1" || entry.Fields["response"] != "200" || entry.Fields["auth"] != "frank" {
+		t.Fatalf("unexpected grok fields %v", entry.Fields)
+	}
+	if !entry.Timestamp.Equal(time.Date(2000, 10, 10, 20, 55, 36, 0, time.UTC)) {
+		t.Fatalf("unexpected grok timestamp %s", entry.Timestamp)
+	}
+	entry = parseLine(t, parser, entities.LogEntry{
+		Labels:  map[string]string{"filename": "/var/log/app/worker.log"},
+		Message: "2024-03-01T10:00:00Z [ERROR] scheduler: job 42 crashed",
+	})
+	if entry.Level != "error" || entry.Message != "job 42 crashed" || entry.Fields["component"] != "scheduler" {
+		t.Fatalf("unexpected regex parse %+v", entry)
+	}
+	entry = parseLine(t, parser, entities.LogEntry{
+		Labels:  map[string]string{"filename": "/var/log/other.log"},
+		Message: "2024-03-01T10:00:00Z [ERROR] scheduler: job 42 crashed",
+	})
+	if len(entry.Fields) != 0 || entry.Message != "2024-03-01T10:00:00Z [ERROR] scheduler: job 42 crashed" {
+		t.Fatalf("rules must only apply to matching sources, got %+v", entry)
+	}
+}
+func TestParserFallsBackOnUnparseableLines(t *testing.T) {
+	parser, err := NewParser(`{source=docker} ^(?P<never>\d{20})$`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	original := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, line := range []string{
+		"Starting server on :8080",
+		`{"truncated": "json`,
+		"GET /index.html 200",
+		"",
+	} {
+		entry := parseLine(t, parser, entities.LogEntry{Source: "docker", Message: line, Timestamp: original})
+		if entry.Message != line || len(entry.Fields) != 0 || !entry.Timestamp.Equal(original) {
+			t.Fatalf("line %q should pass through unchanged, got %+v", line, entry)
+		}
+	}
+	entry := parseLine(t, parser, entities.LogEntry{Message: "2024/03/01 10:00:00 [warn] upstream slow"})
+	if entry.Level != "warn" {
+		t.Fatalf("expected level detected from plain text, got %q", entry.Level)
+	}
+	entry = parseLine(t, parser, entities.LogEntry{Message: "user info updated"})
+	if entry.Level != "" {
+		t.Fatalf("lowercase words in prose should not be taken as a level, got %q", entry.Level)
+	}
+	for _, spec := range []string{"{container_name=web", "{source=docker} %{NOPE}", "lowercase pattern", "{source=docker}"} {
+		if _, err := NewParser(spec); err == nil {
+			t.Fatalf("expected error for spec %q", spec)
+		}
+	}
+}
\ No newline at end of file
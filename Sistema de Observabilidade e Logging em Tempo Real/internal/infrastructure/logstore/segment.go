@@ -0,0 +1,309 @@
+package logstore
+import (
+	"bufio"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+const (
+	logSuffix    = ".log"
+	indexSuffix  = ".idx"
+	blockRecords = 128
+)
+type storedRecord struct {
+	Timestamp int64             `json:"ts"`
+	Labels    map[string]string `json:"labels,omitempty"`
+	Fields    map[string]string `json:"fields,omitempty"`
+	Message   string            `json:"message"`
+}
+type indexHeader struct {
+	MinTime int64
+	MaxTime int64
+	Count   int
+}
+type segmentIndex struct {
+	Header        indexHeader
+	FieldsIndexed bool
+	Times         []int64
+	Offsets       []int64
+	Lengths       []uint32
+	Size          int64
+	Postings      map[string][]uint32
+	Trigrams      map[string][]uint32
+}
+type segment struct {
+	seq    uint64
+	header indexHeader
+	index  *segmentIndex
+}
+func newSegmentIndex() *segmentIndex {
+	return &segmentIndex{
+		FieldsIndexed: true,
+		Postings:      make(map[string][]uint32),
+		Trigrams:      make(map[string][]uint32),
+	}
+}
+func (idx *segmentIndex) add(record *storedRecord, offset int64, length int) {
+	id := uint32(len(idx.Times))
+	if id == 0 || record.Timestamp < idx.Header.MinTime {
+		idx.Header.MinTime = record.Timestamp
+	}
+	if id == 0 || record.Timestamp > idx.Header.MaxTime {
+		idx.Header.MaxTime = record.Timestamp
+	}
+	idx.Header.Count++
+	idx.Times = append(idx.Times, record.Timestamp)
+	idx.Offsets = append(idx.Offsets, offset)
+	idx.Lengths = append(idx.Lengths, uint32(length))
+	idx.Size = offset + int64(length)
+	for name, value := range record.Labels {
+		key := postingKey(name, value)
+		idx.Postings[key] = append(idx.Postings[key], id)
+	}
+	for name, value := range record.Fields {
+		if _, shadowed := record.Labels[name]; shadowed {
+			continue
+		}
+		key := postingKey(name, value)
+		idx.Postings[key] = append(idx.Postings[key], id)
+	}
+	block := id / blockRecords
+	text := strings.ToLower(record.Message)
+	for i := 0; i+3 <= len(text); i++ {
+		gram := text[i : i+3]
+		list := idx.Trigrams[gram]
+		if n := len(list); n > 0 && list[n-1] == block {
+			continue
+		}
+		idx.Trigrams[gram] = append(list, block)
+	}
+}
+func (idx *segmentIndex) candidates(matchers map[string][]string, grams []string) []uint32 {
+	var ids []uint32
+	filtered := false
+	for name, values := range matchers {
+		if len(values) == 0 {
+			continue
+		}
+		var union []uint32
+		for _, value := range values {
+			union = unionSorted(union, idx.Postings[postingKey(name, value)])
+		}
+		if filtered {
+			ids = intersectSorted(ids, union)
+		} else {
+			ids, filtered = union, true
+		}
+	}
+	if !filtered {
+		ids = make([]uint32, len(idx.Times))
+		for i := range ids {
+			ids[i] = uint32(i)
+		}
+	}
+	if len(grams) == 0 {
+		return ids
+	}
+	blocks := idx.Trigrams[grams[0]]
+	for _, gram := range grams[1:] {
+		blocks = intersectSorted(blocks, idx.Trigrams[gram])
+	}
+	kept := ids[:0:0]
+	for _, id := range ids {
+		block := id / blockRecords
+		i := sort.Search(len(blocks), func(i int) bool { return blocks[i] >= block })
+		if i < len(blocks) && blocks[i] == block {
+			kept = append(kept, id)
+		}
+	}
+	return kept
+}
+func (idx *segmentIndex) scan(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	if _, err := file.Seek(idx.Size, io.SeekStart); err != nil {
+		return err
+	}
+	reader := bufio.NewReaderSize(file, 64*1024)
+	offset := idx.Size
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		var record storedRecord
+		if json.Unmarshal(line, &record) == nil {
+			idx.add(&record, offset, len(line))
+		}
+		offset += int64(len(line))
+		idx.Size = offset
+	}
+}
+func (idx *segmentIndex) read(file *os.File, id uint32) (*storedRecord, error) {
+	buf := make([]byte, idx.Lengths[id])
+	if _, err := file.ReadAt(buf, idx.Offsets[id]); err != nil {
+		return nil, err
+	}
+	var record storedRecord
+	if err := json.Unmarshal(buf, &record); err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+func writeIndex(path string, idx *segmentIndex) error {
+	tmp := path + ".tmp"
+	file, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	writer := bufio.NewWriter(file)
+	encoder := gob.NewEncoder(writer)
+	err = encoder.Encode(idx.Header)
+	if err == nil {
+		err = encoder.Encode(idx)
+	}
+	if err == nil {
+		err = writer.Flush()
+	}
+	if err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+func readIndexHeader(path string) (indexHeader, error) {
+	var header indexHeader
+	file, err := os.Open(path)
+	if err != nil {
+		return header, err
+	}
+	defer file.Close()
+	err = gob.NewDecoder(bufio.NewReader(file)).Decode(&header)
+	return header, err
+}
+func readIndex(path string) (*segmentIndex, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	decoder := gob.NewDecoder(bufio.NewReader(file))
+	var header indexHeader
+	if err := decoder.Decode(&header); err != nil {
+		return nil, err
+	}
+	idx := newSegmentIndex()
+	idx.FieldsIndexed = false
+	if err := decoder.Decode(idx); err != nil {
+		return nil, err
+	}
+	return idx, nil
+}
+func reindexSegment(dir string, seq uint64) (*segmentIndex, error) {
+	idx := newSegmentIndex()
+	if err := idx.scan(segmentPath(dir, seq, logSuffix)); err != nil {
+		return nil, err
+	}
+	return idx, nil
+}
+func segmentSeqs(dir string) ([]uint64, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var seqs []uint64
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), logSuffix)
+		if entry.IsDir() || !ok {
+			continue
+		}
+		seq, err := strconv.ParseUint(name, 10, 64)
+		if err != nil {
+			continue
+		}
+		seqs = append(seqs, seq)
+	}
+	sort.Slice(seqs, func(i, j int) bool { return seqs[i] < seqs[j] })
+	return seqs, nil
+}
+func segmentPath(dir string, seq uint64, suffix string) string {
+	return filepath.Join(dir, fmt.Sprintf("%020d%s", seq, suffix))
+}
+func postingKey(name, value string) string {
+	return name + "\x00" + value
+}
+func queryTrigrams(texts ...string) []string {
+	seen := make(map[string]bool)
+	var grams []string
+	for _, text := range texts {
+		text = strings.ToLower(text)
+		for i := 0; i+3 <= len(text); i++ {
+			if gram := text[i : i+3]; !seen[gram] {
+				seen[gram] = true
+				grams = append(grams, gram)
+			}
+		}
+	}
+	return grams
+}
+func unionSorted(a, b []uint32) []uint32 {
+	if len(a) == 0 {
+		return b
+	}
+	if len(b) == 0 {
+		return a
+	}
+	out := make([]uint32, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] < b[j]:
+			out = append(out, a[i])
+			i++
+		case a[i] > b[j]:
+			out = append(out, b[j])
+			j++
+		default:
+			out = append(out, a[i])
+			i++
+			j++
+		}
+	}
+	out = append(out, a[i:]...)
+	return append(out, b[j:]...)
+}
+func intersectSorted(a, b []uint32) []uint32 {
+	var out []uint32
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] < b[j]:
+			i++
+		case a[i] > b[j]:
+			j++
+		default:
+			out = append(out, a[i])
+			i++
+			j++
+		}
+	}
+	return out
+}
\ No newline at end of file
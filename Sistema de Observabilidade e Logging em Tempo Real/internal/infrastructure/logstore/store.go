@@ -0,0 +1,504 @@
+package logstore
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+var (
+	ErrClosed   = errors.New("logstore is closed")
+	ErrReadOnly = errors.New("logstore is opened read-only")
+)
+type Options struct {
+	Dir             string
+	SegmentBytes    int64
+	SegmentDuration time.Duration
+	Retention       time.Duration
+	ReadOnly        bool
+}
+type Record struct {
+	Timestamp time.Time
+	Labels    map[string]string
+	Fields    map[string]string
+	Message   string
+}
+type Query struct {
+	Matchers map[string][]string
+	Start    time.Time
+	End      time.Time
+	Contains string
+	Regexp   *regexp.Regexp
+	Limit    int
+}
+type Position struct {
+	Segment uint64
+	Record  uint32
+}
+type DB struct {
+	options  Options
+	segments []*segment
+	active   *segment
+	file     *os.File
+	created  time.Time
+	closed   bool
+	mu       sync.RWMutex
+}
+type hit struct {
+	record *storedRecord
+	seq    uint64
+	id     uint32
+}
+func Open(options Options) (*DB, error) {
+	if options.SegmentBytes <= 0 {
+		options.SegmentBytes = 16 << 20
+	}
+	if options.SegmentDuration <= 0 {
+		options.SegmentDuration = time.Hour
+	}
+	if err := os.MkdirAll(options.Dir, 0o755); err != nil {
+		return nil, err
+	}
+	db := &DB{options: options}
+	if err := db.refresh(); err != nil {
+		return nil, err
+	}
+	if options.ReadOnly {
+		return db, nil
+	}
+	for i, s := range db.segments {
+		if s.index == nil {
+			if err := db.upgradeIndex(s); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if i < len(db.segments)-1 {
+			if err := db.seal(s); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		file, err := os.OpenFile(db.path(s.seq, logSuffix), os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, err
+		}
+		if err := file.Truncate(s.index.Size); err != nil {
+			file.Close()
+			return nil, err
+		}
+		db.active, db.file, db.created = s, file, time.Now()
+	}
+	return db, nil
+}
+func (db *DB) Append(records ...Record) error {
+	if db.options.ReadOnly {
+		return ErrReadOnly
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if db.closed {
+		return ErrClosed
+	}
+	if len(records) == 0 {
+		return nil
+	}
+	if db.active == nil || db.active.index.Size >= db.options.SegmentBytes || time.Since(db.created) >= db.options.SegmentDuration {
+		if err := db.roll(); err != nil {
+			return err
+		}
+	}
+	var buf bytes.Buffer
+	stored := make([]storedRecord, len(records))
+	lengths := make([]int, len(records))
+	for i, record := range records {
+		stored[i] = storedRecord{
+			Timestamp: record.Timestamp.UnixNano(),
+			Labels:    record.Labels,
+			Fields:    record.Fields,
+			Message:   record.Message,
+		}
+		line, err := json.Marshal(&stored[i])
+		if err != nil {
+			return err
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+		lengths[i] = len(line) + 1
+	}
+	idx := db.active.index
+	if _, err := db.file.Write(buf.Bytes()); err != nil {
+		db.file.Truncate(idx.Size)
+		return err
+	}
+	offset := idx.Size
+	for i := range stored {
+		idx.add(&stored[i], offset, lengths[i])
+		offset += int64(lengths[i])
+	}
+	db.active.header = idx.Header
+	return nil
+}
+func (db *DB) Search(query Query) ([]Record, error) {
+	if db.options.ReadOnly {
+		db.mu.Lock()
+		err := db.refresh()
+		db.mu.Unlock()
+		if err != nil {
+			return nil, err
+		}
+	}
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	if db.closed {
+		return nil, ErrClosed
+	}
+	from, to := int64(math.MinInt64), int64(math.MaxInt64)
+	if !query.Start.IsZero() {
+		from = query.Start.UnixNano()
+	}
+	if !query.End.IsZero() {
+		to = query.End.UnixNano()
+	}
+	var candidates []*segment
+	for _, s := range db.segments {
+		if s.header.Count > 0 && s.header.MaxTime >= from && s.header.MinTime < to {
+			candidates = append(candidates, s)
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].header.MaxTime > candidates[j].header.MaxTime
+	})
+	texts := []string{query.Contains}
+	if query.Regexp != nil {
+		prefix, _ := query.Regexp.LiteralPrefix()
+		texts = append(texts, prefix)
+	}
+	grams := queryTrigrams(texts...)
+	var hits []hit
+	for _, s := range candidates {
+		if query.Limit > 0 && len(hits) >= query.Limit && s.header.MaxTime < hits[query.Limit-1].record.Timestamp {
+			break
+		}
+		found, err := db.searchSegment(s, query, from, to, grams)
+		if err != nil {
+			return nil, err
+		}
+		hits = append(hits, found...)
+		sort.Slice(hits, func(i, j int) bool {
+			a, b := hits[i], hits[j]
+			if a.record.Timestamp != b.record.Timestamp {
+				return a.record.Timestamp > b.record.Timestamp
+			}
+			if a.seq != b.seq {
+				return a.seq > b.seq
+			}
+			return a.id > b.id
+		})
+		if query.Limit > 0 && len(hits) > query.Limit {
+			hits = hits[:query.Limit]
+		}
+	}
+	records := make([]Record, len(hits))
+	for i, h := range hits {
+		records[i] = Record{
+			Timestamp: time.Unix(0, h.record.Timestamp).UTC(),
+			Labels:    h.record.Labels,
+			Fields:    h.record.Fields,
+			Message:   h.record.Message,
+		}
+	}
+	return records, nil
+}
+func (db *DB) Head() (Position, error) {
+	if db.options.ReadOnly {
+		db.mu.Lock()
+		err := db.refresh()
+		db.mu.Unlock()
+		if err != nil {
+			return Position{}, err
+		}
+	}
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	if db.closed {
+		return Position{}, ErrClosed
+	}
+	if len(db.segments) == 0 {
+		return Position{}, nil
+	}
+	last := db.segments[len(db.segments)-1]
+	return Position{Segment: last.seq, Record: uint32(last.header.Count)}, nil
+}
+func (db *DB) Tail(after Position, limit int) ([]Record, Position, error) {
+	if db.options.ReadOnly {
+		db.mu.Lock()
+		err := db.refresh()
+		db.mu.Unlock()
+		if err != nil {
+			return nil, after, err
+		}
+	}
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	if db.closed {
+		return nil, after, ErrClosed
+	}
+	var records []Record
+	position := after
+	for _, s := range db.segments {
+		if s.seq < after.Segment || (s.seq == after.Segment && int(after.Record) >= s.header.Count) {
+			continue
+		}
+		if limit > 0 && len(records) >= limit {
+			break
+		}
+		found, next, err := db.tailSegment(s, after, limit-len(records))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return records, position, err
+		}
+		records = append(records, found...)
+		position = next
+	}
+	return records, position, nil
+}
+func (db *DB) DeleteBefore(cutoff time.Time) (int, error) {
+	if db.options.ReadOnly {
+		return 0, ErrReadOnly
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	return db.deleteBefore(cutoff)
+}
+func (db *DB) Close() error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if db.closed {
+		return nil
+	}
+	db.closed = true
+	if db.file == nil {
+		return nil
+	}
+	err := db.file.Sync()
+	if closeErr := db.file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+func (db *DB) searchSegment(s *segment, query Query, from, to int64, grams []string) ([]hit, error) {
+	idx := s.index
+	if idx == nil {
+		loaded, err := readIndex(db.path(s.seq, indexSuffix))
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		idx = loaded
+		if !idx.FieldsIndexed {
+			if idx, err = reindexSegment(db.options.Dir, s.seq); err != nil {
+				if os.IsNotExist(err) {
+					return nil, nil
+				}
+				return nil, err
+			}
+		}
+	}
+	var ids []uint32
+	for _, id := range idx.candidates(query.Matchers, grams) {
+		if ts := idx.Times[id]; ts >= from && ts < to {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if idx.Times[ids[i]] != idx.Times[ids[j]] {
+			return idx.Times[ids[i]] > idx.Times[ids[j]]
+		}
+		return ids[i] > ids[j]
+	})
+	file, err := os.Open(db.path(s.seq, logSuffix))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+	contains := strings.ToLower(query.Contains)
+	var hits []hit
+	for _, id := range ids {
+		record, err := idx.read(file, id)
+		if err != nil {
+			return nil, err
+		}
+		if contains != "" && !strings.Contains(strings.ToLower(record.Message), contains) {
+			continue
+		}
+		if query.Regexp != nil && !query.Regexp.MatchString(record.Message) {
+			continue
+		}
+		hits = append(hits, hit{record: record, seq: s.seq, id: id})
+		if query.Limit > 0 && len(hits) >= query.Limit {
+			break
+		}
+	}
+	return hits, nil
+}
+func (db *DB) tailSegment(s *segment, after Position, limit int) ([]Record, Position, error) {
+	idx := s.index
+	if idx == nil {
+		loaded, err := readIndex(db.path(s.seq, indexSuffix))
+		if err != nil {
+			return nil, after, err
+		}
+		idx = loaded
+	}
+	var id uint32
+	if s.seq == after.Segment {
+		id = after.Record
+	}
+	file, err := os.Open(db.path(s.seq, logSuffix))
+	if err != nil {
+		return nil, after, err
+	}
+	defer file.Close()
+	var records []Record
+	for ; int(id) < len(idx.Times) && (limit <= 0 || len(records) < limit); id++ {
+		record, err := idx.read(file, id)
+		if err != nil {
+			return nil, after, err
+		}
+		records = append(records, Record{
+			Timestamp: time.Unix(0, record.Timestamp).UTC(),
+			Labels:    record.Labels,
+			Fields:    record.Fields,
+			Message:   record.Message,
+		})
+	}
+	return records, Position{Segment: s.seq, Record: id}, nil
+}
+func (db *DB) refresh() error {
+	seqs, err := segmentSeqs(db.options.Dir)
+	if err != nil {
+		return err
+	}
+	known := make(map[uint64]*segment, len(db.segments))
+	for _, s := range db.segments {
+		known[s.seq] = s
+	}
+	segments := make([]*segment, 0, len(seqs))
+	for _, seq := range seqs {
+		s, ok := known[seq]
+		if !ok {
+			s = &segment{seq: seq}
+		}
+		if ok && s.index == nil {
+			segments = append(segments, s)
+			continue
+		}
+		header, err := readIndexHeader(db.path(seq, indexSuffix))
+		switch {
+		case err == nil:
+			s.header, s.index = header, nil
+		case os.IsNotExist(err):
+			if s.index == nil {
+				s.index = newSegmentIndex()
+			}
+			if err := s.index.scan(db.path(seq, logSuffix)); err != nil {
+				if os.IsNotExist(err) {
+					continue
+				}
+				return err
+			}
+			s.header = s.index.Header
+		default:
+			return err
+		}
+		segments = append(segments, s)
+	}
+	db.segments = segments
+	return nil
+}
+func (db *DB) roll() error {
+	var seq uint64 = 1
+	if db.active != nil {
+		if err := db.file.Sync(); err != nil {
+			return err
+		}
+		if err := db.file.Close(); err != nil {
+			return err
+		}
+		db.file = nil
+		if err := db.seal(db.active); err != nil {
+			return err
+		}
+	}
+	if n := len(db.segments); n > 0 {
+		seq = db.segments[n-1].seq + 1
+	}
+	file, err := os.OpenFile(db.path(seq, logSuffix), os.O_CREATE|os.O_EXCL|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	db.active = &segment{seq: seq, index: newSegmentIndex()}
+	db.file, db.created = file, time.Now()
+	db.segments = append(db.segments, db.active)
+	if db.options.Retention > 0 {
+		if _, err := db.deleteBefore(time.Now().Add(-db.options.Retention)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+func (db *DB) seal(s *segment) error {
+	if err := writeIndex(db.path(s.seq, indexSuffix), s.index); err != nil {
+		return err
+	}
+	s.header, s.index = s.index.Header, nil
+	return nil
+}
+func (db *DB) upgradeIndex(s *segment) error {
+	idx, err := readIndex(db.path(s.seq, indexSuffix))
+	if err != nil || idx.FieldsIndexed {
+		return err
+	}
+	if idx, err = reindexSegment(db.options.Dir, s.seq); err != nil {
+		return err
+	}
+	return writeIndex(db.path(s.seq, indexSuffix), idx)
+}
+func (db *DB) deleteBefore(cutoff time.Time) (int, error) {
+	limit := cutoff.UnixNano()
+	kept := make([]*segment, 0, len(db.segments))
+	deleted := 0
+	var err error
+	for _, s := range db.segments {
+		if err == nil && s.index == nil && s.header.MaxTime < limit {
+			if err = os.Remove(db.path(s.seq, logSuffix)); err == nil || os.IsNotExist(err) {
+				os.Remove(db.path(s.seq, indexSuffix))
+				err = nil
+				deleted++
+				continue
+			}
+		}
+		kept = append(kept, s)
+	}
+	db.segments = kept
+	return deleted, err
+}
+func (db *DB) path(seq uint64, suffix string) string {
+	return segmentPath(db.options.Dir, seq, suffix)
+}
\ No newline at end of file
@@ -0,0 +1,277 @@
+package logstore
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+var base = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+func record(second int, container, level, message string) Record {
+	return Record{
+		Timestamp: base.Add(time.Duration(second) * time.Second),
+		Labels:    map[string]string{"container_id": container, "level": level},
+		Message:   message,
+	}
+}
+func messages(records []Record) []string {
+	out := make([]string, len(records))
+	for i, r := range records {
+		out[i] = r.Message
+	}
+	return out
+}
+func expectMessages(t *testing.T, records []Record, expected ...string) {
+	t.Helper()
+	got := messages(records)
+	if fmt.Sprint(got) != fmt.Sprint(expected) {
+		t.Fatalf("expected %q, got %q", expected, got)
+	}
+}
+func TestSearchFiltersLabelsTextAndTime(t *testing.T) {
+	db, err := Open(Options{Dir: t.TempDir()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	err = db.Append(
+		record(1, "a", "info", "server started"),
+		record(2, "b", "error", "DB Timeout after 5s"),
+		record(3, "a", "error", "db timeout after 7s"),
+		record(4, "c", "warn", "slow query"),
+		record(5, "a", "info", "request served"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	all, err := db.Search(Query{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectMessages(t, all, "request served", "slow query", "db timeout after 7s", "DB Timeout after 5s", "server started")
+	byLabels, _ := db.Search(Query{Matchers: map[string][]string{"container_id": {"a", "b"}, "level": {"error"}}})
+	expectMessages(t, byLabels, "db timeout after 7s", "DB Timeout after 5s")
+	byText, _ := db.Search(Query{Contains: "TIMEOUT"})
+	expectMessages(t, byText, "db timeout after 7s", "DB Timeout after 5s")
+	byRegex, _ := db.Search(Query{Regexp: regexp.MustCompile(`after \d+s$`), Contains: "db"})
+	expectMessages(t, byRegex, "db timeout after 7s", "DB Timeout after 5s")
+	byTime, _ := db.Search(Query{Start: base.Add(2 * time.Second), End: base.Add(4 * time.Second)})
+	expectMessages(t, byTime, "db timeout after 7s", "DB Timeout after 5s")
+	limited, _ := db.Search(Query{Matchers: map[string][]string{"container_id": {"a"}}, Limit: 2})
+	expectMessages(t, limited, "request served", "db timeout after 7s")
+	if all[0].Labels["container_id"] != "a" || !all[0].Timestamp.Equal(base.Add(5*time.Second)) {
+		t.Fatalf("unexpected record %+v", all[0])
+	}
+}
+func TestSegmentsSealAndReopen(t *testing.T) {
+	dir := t.TempDir()
+	db, err := Open(Options{Dir: dir, SegmentBytes: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 300; i++ {
+		if err := db.Append(record(i, fmt.Sprint(i%3), "info", fmt.Sprintf("line %03d", i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+	indexes, _ := filepath.Glob(filepath.Join(dir, "*"+indexSuffix))
+	if len(indexes) != 299 {
+		t.Fatalf("expected 299 sealed segments, got %d", len(indexes))
+	}
+	db, err = Open(Options{Dir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if err := db.Append(record(300, "0", "info", "line 300")); err != nil {
+		t.Fatal(err)
+	}
+	found, err := db.Search(Query{Matchers: map[string][]string{"container_id": {"0"}}, Contains: "line 2", Limit: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectMessages(t, found, "line 297", "line 294", "line 291")
+	latest, _ := db.Search(Query{Limit: 2})
+	expectMessages(t, latest, "line 300", "line 299")
+}
+func TestReopenDropsTornWrite(t *testing.T) {
+	dir := t.TempDir()
+	db, err := Open(Options{Dir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.Append(record(1, "a", "info", "complete"))
+	db.Close()
+	path := segmentPath(dir, 1, logSuffix)
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.WriteString(`{"ts":2,"message":"tor`)
+	file.Close()
+	db, err = Open(Options{Dir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	db.Append(record(2, "a", "info", "after restart"))
+	found, err := db.Search(Query{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectMessages(t, found, "after restart", "complete")
+}
+func TestReadOnlyFollowsWriter(t *testing.T) {
+	dir := t.TempDir()
+	writer, err := Open(Options{Dir: dir, SegmentBytes: 200})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer writer.Close()
+	reader, err := Open(Options{Dir: dir, ReadOnly: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+	if err := reader.Append(record(0, "a", "info", "nope")); err != ErrReadOnly {
+		t.Fatalf("expected ErrReadOnly, got %v", err)
+	}
+	for i := 0; i < 6; i++ {
+		if err := writer.Append(record(i, "a", "info", fmt.Sprintf("event %d", i))); err != nil {
+			t.Fatal(err)
+		}
+		found, err := reader.Search(Query{Limit: 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		expectMessages(t, found, fmt.Sprintf("event %d", i))
+	}
+	found, _ := reader.Search(Query{Contains: "event"})
+	if len(found) != 6 {
+		t.Fatalf("expected 6 events, got %q", messages(found))
+	}
+}
+func TestDeleteBeforeRemovesSealedSegments(t *testing.T) {
+	db, err := Open(Options{Dir: t.TempDir(), SegmentBytes: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	for i := 0; i < 4; i++ {
+		db.Append(record(i*60, "a", "info", fmt.Sprintf("minute %d", i)))
+	}
+	deleted, err := db.DeleteBefore(base.Add(150 * time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deleted != 3 {
+		t.Fatalf("expected 3 deleted segments, got %d", deleted)
+	}
+	found, _ := db.Search(Query{})
+	expectMessages(t, found, "minute 3")
+}
+func TestSearchMatchesParsedFields(t *testing.T) {
+	dir := t.TempDir()
+	db, err := Open(Options{Dir: dir, SegmentBytes: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, user := range []string{"42", "7", "42"} {
+		r := record(i, "a", "info", fmt.Sprintf("request %d", i))
+		r.Fields = map[string]string{"user_id": user, "level": "debug"}
+		if err := db.Append(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	byField, _ := db.Search(Query{Matchers: map[string][]string{"user_id": {"42"}}})
+	expectMessages(t, byField, "request 2", "request 0")
+	if shadowed, _ := db.Search(Query{Matchers: map[string][]string{"level": {"debug"}}}); len(shadowed) != 0 {
+		t.Fatalf("fields shadowed by labels should not be indexed, got %q", messages(shadowed))
+	}
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+	indexes, _ := filepath.Glob(filepath.Join(dir, "*"+indexSuffix))
+	for _, path := range indexes {
+		idx, err := readIndex(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for key := range idx.Postings {
+			if strings.HasPrefix(key, "user_id\x00") {
+				delete(idx.Postings, key)
+			}
+		}
+		idx.FieldsIndexed = false
+		if err := writeIndex(path, idx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	reader, err := Open(Options{Dir: dir, ReadOnly: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	legacy, _ := reader.Search(Query{Matchers: map[string][]string{"user_id": {"42"}}})
+	expectMessages(t, legacy, "request 2", "request 0")
+	reader.Close()
+	db, err = Open(Options{Dir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	for _, path := range indexes {
+		if idx, err := readIndex(path); err != nil || !idx.FieldsIndexed {
+			t.Fatalf("expected %s to be reindexed on open, got %v", path, err)
+		}
+	}
+	upgraded, _ := db.Search(Query{Matchers: map[string][]string{"user_id": {"7"}}})
+	expectMessages(t, upgraded, "request 1")
+}
+func TestTailFollowsAppendOrderAcrossSegments(t *testing.T) {
+	dir := t.TempDir()
+	writer, err := Open(Options{Dir: dir, SegmentBytes: 200})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer writer.Close()
+	reader, err := Open(Options{Dir: dir, ReadOnly: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+	if err := writer.Append(record(10, "a", "info", "before the tail")); err != nil {
+		t.Fatal(err)
+	}
+	head, err := reader.Head()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, second := range []int{20, 1, 21, 2, 22} {
+		if err := writer.Append(record(second, "a", "info", fmt.Sprintf("event %d", i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	found, position, err := reader.Tail(head, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectMessages(t, found, "event 0", "event 1", "event 2")
+	found, position, err = reader.Tail(position, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectMessages(t, found, "event 3", "event 4")
+	if found, _, _ = reader.Tail(position, 3); len(found) != 0 {
+		t.Fatalf("expected nothing after the last record, got %q", messages(found))
+	}
+	if err := writer.Append(record(0, "a", "info", "late")); err != nil {
+		t.Fatal(err)
+	}
+	found, _, _ = reader.Tail(position, 3)
+	expectMessages(t, found, "late")
+}
\ No newline at end of file
@@ -1,53 +1,126 @@
 package prometheus
 import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
 	"github.com/prometheus/client_golang/prometheus"
-	"github.com/prometheus/client_golang/prometheus/promauto"
+	"observability-system/internal/domain/entities"
 )
 type MetricsExporter struct {
-	cpuUsage    *prometheus.GaugeVec
-	memoryUsage *prometheus.GaugeVec
-	networkRx   *prometheus.CounterVec
-	networkTx   *prometheus.CounterVec
+	mu         sync.Mutex
+	samples    map[string]exportedSample
+	staleAfter time.Duration
+	now        func() time.Time
 }
-func NewMetricsExporter() *MetricsExporter {
+type exportedSample struct {
+	sample entities.Sample
+	seen   time.Time
+}
+func NewMetricsExporter(staleAfter time.Duration) *MetricsExporter {
+	e := newMetricsExporter(staleAfter)
+	prometheus.MustRegister(e)
+	return e
+}
+func newMetricsExporter(staleAfter time.Duration) *MetricsExporter {
 	return &MetricsExporter{
-		cpuUsage: promauto.NewGaugeVec(
-			prometheus.GaugeOpts{
-				Name: "container_cpu_usage_percent",
-				Help: "Container CPU usage percentage",
-			},
-			[]string{"container_id", "container_name"},
-		),
-		memoryUsage: promauto.NewGaugeVec(
-			prometheus.GaugeOpts{
-				Name: "container_memory_usage_percent",
-				Help: "Container memory usage percentage",
-			},
-			[]string{"container_id", "container_name"},
-		),
-		networkRx: promauto.NewCounterVec(
-			prometheus.CounterOpts{
-				Name: "container_network_rx_bytes_total",
-				Help: "Container network received bytes",
-			},
-			[]string{"container_id", "container_name"},
-		),
-		networkTx: promauto.NewCounterVec(
-			prometheus.CounterOpts{
-				Name: "container_network_tx_bytes_total",
-				Help: "Container network transmitted bytes",
-			},
-			[]string{"container_id", "container_name"},
-		),
+		samples:    make(map[string]exportedSample),
+		staleAfter: staleAfter,
+		now:        time.Now,
+	}
+}
+func (e *MetricsExporter) RecordSamples(samples []entities.Sample) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	now := e.now()
+	for _, sample := range samples {
+		e.samples[entities.SeriesKey(sample.Name, sample.Labels)] = exportedSample{sample: sample, seen: now}
 	}
 }
 func (e *MetricsExporter) RecordMetrics(containerID, containerName string, cpuPercent, memoryPercent float64, networkRx, networkTx uint64) {
-	labels := prometheus.Labels{
-		"container_id":   containerID,
-		"container_name": containerName,
-	}
-	e.cpuUsage.With(labels).Set(cpuPercent)
-	e.memoryUsage.With(labels).Set(memoryPercent)
-	e.networkRx.With(labels).Add(float64(networkRx))
-	e.networkTx.With(labels).Add(float64(networkTx))
+	metrics := &entities.ContainerMetrics{
+		ContainerID:   containerID,
+		ContainerName: containerName,
+		CPUPercent:    cpuPercent,
+		MemoryPercent: memoryPercent,
+		NetworkRx:     networkRx,
+		NetworkTx:     networkTx,
+		Timestamp:     time.Now(),
+	}
+	var samples []entities.Sample
+	for _, sample := range metrics.Samples() {
+		switch sample.Name {
+		case "container_cpu_usage_percent", "container_memory_usage_percent",
+			"container_network_rx_bytes_total", "container_network_tx_bytes_total":
+			samples = append(samples, sample)
+		}
+	}
+	e.RecordSamples(samples)
+}
+func (e *MetricsExporter) Describe(ch chan<- *prometheus.Desc) {
+}
+func (e *MetricsExporter) Collect(ch chan<- prometheus.Metric) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	now := e.now()
+	for key, exported := range e.samples {
+		if e.staleAfter > 0 && now.Sub(exported.seen) > e.staleAfter {
+			delete(e.samples, key)
+			continue
+		}
+		metric, err := constMetric(exported.sample)
+		if err != nil {
+			continue
+		}
+		ch <- metric
+	}
+}
+func constMetric(sample entities.Sample) (prometheus.Metric, error) {
+	names := make([]string, 0, len(sample.Labels))
+	for name := range sample.Labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	labelNames := make([]string, 0, len(names))
+	labelValues := make([]string, 0, len(names))
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		sanitized := sanitizeName(name)
+		if seen[sanitized] || strings.HasPrefix(sanitized, "__") {
+			continue
+		}
+		seen[sanitized] = true
+		labelNames = append(labelNames, sanitized)
+		labelValues = append(labelValues, sample.Labels[name])
+	}
+	help := sample.Name
+	if sample.Unit != "" {
+		help += " (" + sample.Unit + ")"
+	}
+	desc := prometheus.NewDesc(sanitizeName(sample.Name), help, labelNames, nil)
+	switch sample.Type {
+	case entities.MetricTypeCounter:
+		return prometheus.NewConstMetric(desc, prometheus.CounterValue, sample.Value, labelValues...)
+	case entities.MetricTypeHistogram:
+		buckets := make(map[float64]uint64, len(sample.Buckets))
+		for _, bucket := range sample.Buckets {
+			buckets[bucket.UpperBound] = bucket.Count
+		}
+		return prometheus.NewConstHistogram(desc, sample.Count, sample.Value, buckets, labelValues...)
+	}
+	return prometheus.NewConstMetric(desc, prometheus.GaugeValue, sample.Value, labelValues...)
+}
+func sanitizeName(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r == '_', r == ':':
+			b.WriteRune(r)
+		case r >= '0' && r <= '9' && i > 0:
+			b.WriteRune(r)
+		default:
+			b.WriteByte('_')
+		}
+	}
+	return b.String()
 }
\ No newline at end of file
@@ -0,0 +1,38 @@
+package prometheus
+import (
+	"testing"
+	"time"
+	"github.com/prometheus/client_golang/prometheus"
+	"observability-system/internal/domain/entities"
+)
+func collect(e *MetricsExporter) []prometheus.Metric {
+	ch := make(chan prometheus.Metric, 16)
+	e.Collect(ch)
+	close(ch)
+	var metrics []prometheus.Metric
+	for metric := range ch {
+		metrics = append(metrics, metric)
+	}
+	return metrics
+}
+func TestMetricsExporterExpiresSeriesNotSeenRecently(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	e := newMetricsExporter(time.Minute)
+	e.now = func() time.Time { return now }
+	e.RecordSamples([]entities.Sample{
+		{Name: "cpu", Labels: map[string]string{"container_id": "gone"}, Value: 1, Type: entities.MetricTypeGauge},
+		{Name: "cpu", Labels: map[string]string{"container_id": "live"}, Value: 2, Type: entities.MetricTypeGauge},
+	})
+	if got := len(collect(e)); got != 2 {
+		t.Fatalf("expected 2 series, got %d", got)
+	}
+	now = now.Add(45 * time.Second)
+	e.RecordSamples([]entities.Sample{{Name: "cpu", Labels: map[string]string{"container_id": "live"}, Value: 3, Type: entities.MetricTypeGauge}})
+	now = now.Add(30 * time.Second)
+	if got := len(collect(e)); got != 1 {
+		t.Fatalf("expected the stale series to be dropped, got %d series", got)
+	}
+	if len(e.samples) != 1 {
+		t.Fatalf("expected the stale series to be pruned from memory, got %d", len(e.samples))
+	}
+}
\ No newline at end of file
@@ -0,0 +1,200 @@
+package redact
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"observability-system/internal/domain/entities"
+)
+type Mode string
+const (
+	ModeMask Mode = "mask"
+	ModeHash Mode = "hash"
+)
+const (
+	TargetLog   = "log"
+	TargetAlert = "alert"
+)
+const DefaultDetectors = "email,credit_card,jwt,aws_key,bearer"
+type Detector struct {
+	Name     string
+	Pattern  *regexp.Regexp
+	Validate func(match string) bool
+}
+var BuiltinDetectors = map[string]Detector{
+	"email": {
+		Name:    "email",
+		Pattern: regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9-]+(?:\.[A-Za-z0-9-]+)*\.[A-Za-z]{2,}`),
+	},
+	"credit_card": {
+		Name:     "credit_card",
+		Pattern:  regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`),
+		Validate: luhnValid,
+	},
+	"jwt": {
+		Name:    "jwt",
+		Pattern: regexp.MustCompile(`\beyJ[A-Za-z0-9_-]+\.eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+`),
+	},
+	"aws_key": {
+		Name:    "aws_key",
+		Pattern: regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b|(?i:aws_?secret_?access_?key)["'\s:=]{1,4}([A-Za-z0-9/+=]{40})\b`),
+	},
+	"bearer": {
+		Name:    "bearer",
+		Pattern: regexp.MustCompile(`(?i)\bbearer\s+([A-Za-z0-9\-._~+/]+=*)`),
+	},
+}
+type Options struct {
+	Detectors []string
+	Custom    []Detector
+	Mode      Mode
+	HashKey   string
+}
+type Redactor struct {
+	detectors []Detector
+	mode      Mode
+	hashKey   []byte
+	mu        sync.Mutex
+	counts    map[[2]string]uint64
+}
+func New(options Options) (*Redactor, error) {
+	r := &Redactor{
+		mode:    options.Mode,
+		hashKey: []byte(options.HashKey),
+		counts:  make(map[[2]string]uint64),
+	}
+	if r.mode == "" {
+		r.mode = ModeMask
+	}
+	if r.mode != ModeMask && r.mode != ModeHash {
+		return nil, fmt.Errorf("unknown redaction mode %q", options.Mode)
+	}
+	for _, name := range options.Detectors {
+		detector, ok := BuiltinDetectors[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown redaction detector %q", name)
+		}
+		r.detectors = append(r.detectors, detector)
+	}
+	r.detectors = append(r.detectors, options.Custom...)
+	return r, nil
+}
+func ParseCustomDetectors(spec string) ([]Detector, error) {
+	var detectors []Detector
+	for number, line := range strings.Split(spec, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		name, expr, ok := strings.Cut(line, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("redaction pattern %d: expected name=regex", number+1)
+		}
+		pattern, err := regexp.Compile(strings.TrimSpace(expr))
+		if err != nil {
+			return nil, fmt.Errorf("redaction pattern %d: %w", number+1, err)
+		}
+		detectors = append(detectors, Detector{Name: name, Pattern: pattern})
+	}
+	return detectors, nil
+}
+func (r *Redactor) Enabled() bool {
+	return len(r.detectors) > 0
+}
+func (r *Redactor) RedactLog(entry *entities.LogEntry) {
+	entry.Message = r.Redact(entry.Message, TargetLog)
+	for name, value := range entry.Fields {
+		entry.Fields[name] = r.Redact(value, TargetLog)
+	}
+}
+func (r *Redactor) RedactAlert(alert *entities.Alert) {
+	alert.Message = r.Redact(alert.Message, TargetAlert)
+}
+func (r *Redactor) Redact(text, target string) string {
+	for _, detector := range r.detectors {
+		matches := detector.Pattern.FindAllStringSubmatchIndex(text, -1)
+		if len(matches) == 0 {
+			continue
+		}
+		var b strings.Builder
+		last, redacted := 0, 0
+		for _, match := range matches {
+			start, end := match[0], match[1]
+			if len(match) >= 4 && match[2] >= 0 {
+				start, end = match[2], match[3]
+			}
+			secret := text[start:end]
+			if secret == "" || (detector.Validate != nil && !detector.Validate(secret)) {
+				continue
+			}
+			b.WriteString(text[last:start])
+			b.WriteString(r.replacement(detector.Name, secret))
+			last = end
+			redacted++
+		}
+		if redacted == 0 {
+			continue
+		}
+		b.WriteString(text[last:])
+		text = b.String()
+		r.mu.Lock()
+		r.counts[[2]string{detector.Name, target}] += uint64(redacted)
+		r.mu.Unlock()
+	}
+	return text
+}
+func (r *Redactor) replacement(name, secret string) string {
+	if r.mode == ModeHash {
+		mac := hmac.New(sha256.New, r.hashKey)
+		mac.Write([]byte(secret))
+		return "[" + name + ":" + hex.EncodeToString(mac.Sum(nil))[:12] + "]"
+	}
+	return "[REDACTED:" + name + "]"
+}
+func (r *Redactor) Count(detector, target string) uint64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.counts[[2]string{detector, target}]
+}
+func (r *Redactor) Samples(now time.Time) []entities.Sample {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	samples := make([]entities.Sample, 0, len(r.counts))
+	for key, count := range r.counts {
+		samples = append(samples, entities.Sample{
+			Name:      "redactions_total",
+			Labels:    map[string]string{"detector": key[0], "target": key[1]},
+			Value:     float64(count),
+			Type:      entities.MetricTypeCounter,
+			Timestamp: now,
+		})
+	}
+	sort.Slice(samples, func(i, j int) bool {
+		return entities.SeriesKey(samples[i].Name, samples[i].Labels) < entities.SeriesKey(samples[j].Name, samples[j].Labels)
+	})
+	return samples
+}
+func luhnValid(match string) bool {
+	sum, digits := 0, 0
+	for i := len(match) - 1; i >= 0; i-- {
+		c := match[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if digits%2 == 1 {
+			if d *= 2; d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		digits++
+	}
+	return digits >= 13 && digits <= 19 && sum%10 == 0
+}
\ No newline at end of file
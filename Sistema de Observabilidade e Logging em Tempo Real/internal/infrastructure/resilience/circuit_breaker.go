@@ -38,6 +38,17 @@ func (cb *CircuitBreaker) Execute(ctx context.Context, fn func() error) error {
 	cb.afterRequest(err)
 	return err
 }
+func (cb *CircuitBreaker) Allow() error {
+	return cb.beforeRequest()
+}
+func (cb *CircuitBreaker) IsOpen() bool {
+	cb.mu.RLock()
+	defer cb.mu.RUnlock()
+	return cb.state == StateOpen && time.Since(cb.lastFailTime) <= cb.timeout
+}
+func (cb *CircuitBreaker) Record(err error) {
+	cb.afterRequest(err)
+}
 func (cb *CircuitBreaker) beforeRequest() error {
 	cb.mu.Lock()
 	defer cb.mu.Unlock()
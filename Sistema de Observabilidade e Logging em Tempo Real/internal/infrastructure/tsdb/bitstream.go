@@ -0,0 +1,58 @@
+package tsdb
+import "io"
+type bitWriter struct {
+	buf   []byte
+	count uint8
+}
+func (w *bitWriter) writeBit(bit bool) {
+	if w.count == 0 {
+		w.buf = append(w.buf, 0)
+		w.count = 8
+	}
+	if bit {
+		w.buf[len(w.buf)-1] |= 1 << (w.count - 1)
+	}
+	w.count--
+}
+func (w *bitWriter) writeBits(value uint64, n int) {
+	for i := n - 1; i >= 0; i-- {
+		w.writeBit(value&(1<<uint(i)) != 0)
+	}
+}
+func (w *bitWriter) bytes() []byte {
+	return w.buf
+}
+type bitReader struct {
+	buf   []byte
+	pos   int
+	count uint8
+}
+func newBitReader(buf []byte) *bitReader {
+	return &bitReader{buf: buf, count: 8}
+}
+func (r *bitReader) readBit() (bool, error) {
+	if r.pos >= len(r.buf) {
+		return false, io.ErrUnexpectedEOF
+	}
+	bit := r.buf[r.pos]&(1<<(r.count-1)) != 0
+	r.count--
+	if r.count == 0 {
+		r.pos++
+		r.count = 8
+	}
+	return bit, nil
+}
+func (r *bitReader) readBits(n int) (uint64, error) {
+	var value uint64
+	for i := 0; i < n; i++ {
+		bit, err := r.readBit()
+		if err != nil {
+			return 0, err
+		}
+		value <<= 1
+		if bit {
+			value |= 1
+		}
+	}
+	return value, nil
+}
\ No newline at end of file
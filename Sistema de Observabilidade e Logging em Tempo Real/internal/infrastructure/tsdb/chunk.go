@@ -0,0 +1,173 @@
+package tsdb
+import (
+	"math"
+	"math/bits"
+)
+type Point struct {
+	Timestamp int64
+	Value     float64
+}
+type chunk struct {
+	stream    bitWriter
+	count     int
+	first     int64
+	last      int64
+	lastDelta int64
+	lastValue uint64
+	leading   int
+	trailing  int
+}
+func newChunk() *chunk {
+	return &chunk{leading: -1}
+}
+func (c *chunk) append(t int64, v float64) {
+	value := math.Float64bits(v)
+	if c.count == 0 {
+		c.stream.writeBits(uint64(t), 64)
+		c.stream.writeBits(value, 64)
+		c.first, c.last, c.lastValue = t, t, value
+		c.count++
+		return
+	}
+	delta := t - c.last
+	c.writeDeltaOfDelta(delta - c.lastDelta)
+	c.writeValue(value)
+	c.last, c.lastDelta, c.lastValue = t, delta, value
+	c.count++
+}
+func (c *chunk) writeDeltaOfDelta(dod int64) {
+	switch {
+	case dod == 0:
+		c.stream.writeBit(false)
+	case dod >= -63 && dod <= 64:
+		c.stream.writeBits(0b10, 2)
+		c.stream.writeBits(uint64(dod), 7)
+	case dod >= -255 && dod <= 256:
+		c.stream.writeBits(0b110, 3)
+		c.stream.writeBits(uint64(dod), 9)
+	case dod >= -2047 && dod <= 2048:
+		c.stream.writeBits(0b1110, 4)
+		c.stream.writeBits(uint64(dod), 12)
+	default:
+		c.stream.writeBits(0b1111, 4)
+		c.stream.writeBits(uint64(dod), 64)
+	}
+}
+func (c *chunk) writeValue(value uint64) {
+	xor := value ^ c.lastValue
+	if xor == 0 {
+		c.stream.writeBit(false)
+		return
+	}
+	c.stream.writeBit(true)
+	leading := bits.LeadingZeros64(xor)
+	trailing := bits.TrailingZeros64(xor)
+	if leading > 31 {
+		leading = 31
+	}
+	if c.leading >= 0 && leading >= c.leading && trailing >= c.trailing {
+		c.stream.writeBit(false)
+		c.stream.writeBits(xor>>uint(c.trailing), 64-c.leading-c.trailing)
+		return
+	}
+	significant := 64 - leading - trailing
+	c.stream.writeBit(true)
+	c.stream.writeBits(uint64(leading), 5)
+	c.stream.writeBits(uint64(significant%64), 6)
+	c.stream.writeBits(xor>>uint(trailing), significant)
+	c.leading, c.trailing = leading, trailing
+}
+func (c *chunk) bytes() []byte {
+	return c.stream.bytes()
+}
+func decodeChunk(data []byte, count int) ([]Point, error) {
+	points := make([]Point, 0, count)
+	if count == 0 {
+		return points, nil
+	}
+	r := newBitReader(data)
+	t, err := r.readBits(64)
+	if err != nil {
+		return nil, err
+	}
+	value, err := r.readBits(64)
+	if err != nil {
+		return nil, err
+	}
+	points = append(points, Point{Timestamp: int64(t), Value: math.Float64frombits(value)})
+	last, lastDelta := int64(t), int64(0)
+	leading, trailing := 0, 0
+	for len(points) < count {
+		dod, err := readDeltaOfDelta(r)
+		if err != nil {
+			return nil, err
+		}
+		lastDelta += dod
+		last += lastDelta
+		changed, err := r.readBit()
+		if err != nil {
+			return nil, err
+		}
+		if changed {
+			newWindow, err := r.readBit()
+			if err != nil {
+				return nil, err
+			}
+			if newWindow {
+				l, err := r.readBits(5)
+				if err != nil {
+					return nil, err
+				}
+				significant, err := r.readBits(6)
+				if err != nil {
+					return nil, err
+				}
+				if significant == 0 {
+					significant = 64
+				}
+				leading, trailing = int(l), 64-int(l)-int(significant)
+			}
+			xor, err := r.readBits(64 - leading - trailing)
+			if err != nil {
+				return nil, err
+			}
+			value ^= xor << uint(trailing)
+		}
+		points = append(points, Point{Timestamp: last, Value: math.Float64frombits(value)})
+	}
+	return points, nil
+}
+func readDeltaOfDelta(r *bitReader) (int64, error) {
+	prefix := 0
+	for prefix < 4 {
+		bit, err := r.readBit()
+		if err != nil {
+			return 0, err
+		}
+		if !bit {
+			break
+		}
+		prefix++
+	}
+	var width int
+	switch prefix {
+	case 0:
+		return 0, nil
+	case 1:
+		width = 7
+	case 2:
+		width = 9
+	case 3:
+		width = 12
+	default:
+		width = 64
+	}
+	raw, err := r.readBits(width)
+	if err != nil {
+		return 0, err
+	}
+	if width < 64 && raw > (1<<uint(width-1)) {
+		return int64(raw) - (1 << uint(width)), nil
+	}
+	return int64(raw), nil
+}
\ No newline at end of file
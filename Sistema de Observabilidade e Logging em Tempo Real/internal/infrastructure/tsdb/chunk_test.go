@@ -0,0 +1,49 @@
+package tsdb
+import (
+	"math"
+	"testing"
+)
+func TestChunkRoundTrip(t *testing.T) {
+	cases := map[string][]Point{
+		"regular": {{1000, 1}, {2000, 2}, {3000, 3}, {4000, 4}},
+		"irregular deltas": {
+			{0, 1.5}, {10, 1.5}, {75, 2}, {76, 2}, {400, -3}, {2500, 1e9}, {10_000_000, 4}, {10_000_001, 4},
+		},
+		"negative deltas":  {{5000, 1}, {4000, 2}, {4100, 3}, {-7000, 4}, {math.MaxInt32, 5}, {-math.MaxInt32, 6}},
+		"equal values":     {{1, 42}, {2, 42}, {3, 42}, {4, 42}, {5, 42}},
+		"nan and infinity": {{1, math.NaN()}, {2, 1}, {3, math.NaN()}, {4, math.Inf(1)}, {5, math.Inf(-1)}, {6, 0}},
+		"single point":     {{123456789, -0.5}},
+		"varying windows":  {{1, 1}, {2, 1.0000001}, {3, 1e-300}, {4, math.MaxFloat64}, {5, math.SmallestNonzeroFloat64}},
+	}
+	for name, points := range cases {
+		t.Run(name, func(t *testing.T) {
+			c := newChunk()
+			for _, p := range points {
+				c.append(p.Timestamp, p.Value)
+			}
+			decoded, err := decodeChunk(c.bytes(), c.count)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(decoded) != len(points) {
+				t.Fatalf("expected %d points, got %d", len(points), len(decoded))
+			}
+			for i, p := range points {
+				got := decoded[i]
+				if got.Timestamp != p.Timestamp || math.Float64bits(got.Value) != math.Float64bits(p.Value) {
+					t.Fatalf("point %d: expected %v, got %v", i, p, got)
+				}
+			}
+		})
+	}
+}
+func TestDecodeChunkRejectsTruncatedData(t *testing.T) {
+	c := newChunk()
+	for i := int64(0); i < 10; i++ {
+		c.append(i*1000, float64(i))
+	}
+	data := c.bytes()
+	if _, err := decodeChunk(data[:len(data)/2], c.count); err == nil {
+		t.Fatal("expected an error for a truncated chunk")
+	}
+}
\ No newline at end of file
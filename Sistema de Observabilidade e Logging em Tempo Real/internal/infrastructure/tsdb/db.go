@@ -0,0 +1,538 @@
+package tsdb
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"observability-system/internal/infrastructure/wal"
+)
+const (
+	headLogDir        = "wal"
+	maxBlockReadTries = 5
+)
+var (
+	ErrClosed        = errors.New("tsdb is closed")
+	ErrReadOnly      = errors.New("tsdb is opened read-only")
+	errBadHeadRecord = errors.New("malformed head log record")
+)
+type Labels map[string]string
+type Series struct {
+	Labels Labels
+	Points []Point
+}
+type Options struct {
+	Dir           string
+	BlockDuration time.Duration
+	Retention     time.Duration
+	FlushInterval time.Duration
+	ReadOnly      bool
+}
+type DB struct {
+	options Options
+	head    map[int64]map[string]*headSeries
+	pending []map[int64]map[string]*headSeries
+	headLog *wal.Log
+	closed  bool
+	done    chan struct{}
+	wg      sync.WaitGroup
+	mu      sync.RWMutex
+	diskMu  sync.Mutex
+}
+type headSeries struct {
+	labels Labels
+	chunks []*chunk
+}
+type block struct {
+	dir   string
+	start int64
+	end   int64
+}
+func Open(options Options) (*DB, error) {
+	if options.BlockDuration <= 0 {
+		options.BlockDuration = 2 * time.Hour
+	}
+	if options.FlushInterval <= 0 {
+		options.FlushInterval = 30 * time.Second
+	}
+	if err := os.MkdirAll(options.Dir, 0o755); err != nil {
+		return nil, err
+	}
+	db := &DB{
+		options: options,
+		head:    make(map[int64]map[string]*headSeries),
+		done:    make(chan struct{}),
+	}
+	if !options.ReadOnly {
+		if err := db.openHeadLog(); err != nil {
+			return nil, err
+		}
+		db.wg.Add(1)
+		go db.maintenanceLoop()
+	}
+	return db, nil
+}
+func (db *DB) openHeadLog() error {
+	headLog, err := wal.Open(wal.Options{Dir: filepath.Join(db.options.Dir, headLogDir), SyncPolicy: wal.SyncInterval})
+	if err != nil {
+		return err
+	}
+	db.headLog = headLog
+	recovered := 0
+	err = headLog.Scan(func(record []byte) error {
+		labels, ts, v, err := decodeHeadRecord(record)
+		if err != nil {
+			log.Printf("Skipping unreadable embedded storage head record: %v", err)
+			return nil
+		}
+		db.appendHead(labels, ts, v)
+		recovered++
+		return nil
+	})
+	if err != nil {
+		headLog.Close()
+		return err
+	}
+	if recovered > 0 {
+		log.Printf("Recovered %d unflushed samples from the embedded storage head log", recovered)
+		if err := db.Flush(); err != nil {
+			log.Printf("Failed to flush recovered embedded storage samples: %v", err)
+		}
+	}
+	return nil
+}
+func (db *DB) Append(labels Labels, t time.Time, v float64) error {
+	if db.options.ReadOnly {
+		return ErrReadOnly
+	}
+	ts := t.UnixMilli()
+	record := encodeHeadRecord(labels, ts, v)
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if db.closed {
+		return ErrClosed
+	}
+	if err := db.headLog.Append(record); err != nil {
+		return err
+	}
+	db.appendHead(labels, ts, v)
+	return nil
+}
+func (db *DB) appendHead(labels Labels, ts int64, v float64) {
+	blockStart := ts - ts%db.options.BlockDuration.Milliseconds()
+	key := labels.key()
+	seriesByKey, ok := db.head[blockStart]
+	if !ok {
+		seriesByKey = make(map[string]*headSeries)
+		db.head[blockStart] = seriesByKey
+	}
+	series, ok := seriesByKey[key]
+	if !ok {
+		series = &headSeries{labels: labels.copy()}
+		seriesByKey[key] = series
+	}
+	current := series.current()
+	if current == nil || ts < current.last {
+		current = newChunk()
+		series.chunks = append(series.chunks, current)
+	}
+	current.append(ts, v)
+}
+func (db *DB) Select(matchers Labels, start, end time.Time) ([]*Series, error) {
+	from, to := start.UnixMilli(), end.UnixMilli()
+	merged := make(map[string]*Series)
+	var headEntries []chunkEntry
+	db.mu.RLock()
+	heads := append(append([]map[int64]map[string]*headSeries(nil), db.pending...), db.head)
+	for _, head := range heads {
+		for blockStart, seriesByKey := range head {
+			if blockStart+db.options.BlockDuration.Milliseconds() <= from || blockStart > to {
+				continue
+			}
+			for _, series := range seriesByKey {
+				if !series.labels.matches(matchers) {
+					continue
+				}
+				for _, c := range series.chunks {
+					points, err := decodeChunk(c.bytes(), c.count)
+					if err != nil {
+						db.mu.RUnlock()
+						return nil, err
+					}
+					headEntries = append(headEntries, chunkEntry{labels: series.labels, points: points})
+				}
+			}
+		}
+	}
+	db.mu.RUnlock()
+	blocks, err := db.blocks()
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range blocks {
+		if b.end <= from || b.start > to {
+			continue
+		}
+		entries, err := readBlock(b.dir, matchers)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			mergeInto(merged, entry.labels, entry.points, from, to)
+		}
+	}
+	for _, entry := range headEntries {
+		mergeInto(merged, entry.labels, entry.points, from, to)
+	}
+	result := make([]*Series, 0, len(merged))
+	for _, series := range merged {
+		series.Points = sortAndDedupe(series.Points)
+		result = append(result, series)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Labels.key() < result[j].Labels.key()
+	})
+	return result, nil
+}
+func (db *DB) Flush() error {
+	db.diskMu.Lock()
+	defer db.diskMu.Unlock()
+	db.mu.Lock()
+	head := db.head
+	db.head = make(map[int64]map[string]*headSeries)
+	if len(head) > 0 {
+		db.pending = append(db.pending, head)
+	}
+	pending := append([]map[int64]map[string]*headSeries(nil), db.pending...)
+	var cut uint64
+	if db.headLog != nil {
+		var err error
+		if cut, err = db.headLog.Cut(); err != nil {
+			db.mu.Unlock()
+			return err
+		}
+	}
+	db.mu.Unlock()
+	for _, head := range pending {
+		if err := db.writeHead(head); err != nil {
+			return err
+		}
+		db.mu.Lock()
+		db.pending = db.pending[1:]
+		db.mu.Unlock()
+	}
+	if db.headLog != nil {
+		db.headLog.TruncateThrough(cut)
+	}
+	return nil
+}
+func (db *DB) writeHead(head map[int64]map[string]*headSeries) error {
+	for blockStart, seriesByKey := range head {
+		b := db.blockFor(blockStart)
+		if err := os.MkdirAll(b.dir, 0o755); err != nil {
+			return err
+		}
+		var entries []chunkEntry
+		for _, series := range seriesByKey {
+			for _, c := range series.chunks {
+				entries = append(entries, chunkEntry{labels: series.labels, count: c.count, data: c.bytes()})
+			}
+		}
+		if err := writeChunkFile(b.dir, entries); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+func (db *DB) DeleteBefore(cutoff time.Time) (int, error) {
+	db.diskMu.Lock()
+	defer db.diskMu.Unlock()
+	blocks, err := db.blocks()
+	if err != nil {
+		return 0, err
+	}
+	deleted := 0
+	for _, b := range blocks {
+		if b.end > cutoff.UnixMilli() {
+			continue
+		}
+		if err := os.RemoveAll(b.dir); err != nil {
+			return deleted, err
+		}
+		deleted++
+	}
+	return deleted, nil
+}
+func (db *DB) Delete(matchers Labels, before time.Time) (int64, error) {
+	if db.options.ReadOnly {
+		return 0, ErrReadOnly
+	}
+	db.diskMu.Lock()
+	defer db.diskMu.Unlock()
+	blocks, err := db.blocks()
+	if err != nil {
+		return 0, err
+	}
+	cutoff := before.UnixMilli()
+	var deleted int64
+	for _, b := range blocks {
+		if b.start >= cutoff {
+			continue
+		}
+		files, err := chunkFiles(b.dir)
+		if err != nil {
+			return deleted, err
+		}
+		expired := false
+		for _, file := range files {
+			entries, err := readChunkFile(file, matchers)
+			if err != nil {
+				return deleted, err
+			}
+			for _, entry := range entries {
+				if len(entry.points) > 0 && entry.points[0].Timestamp < cutoff {
+					expired = true
+				}
+			}
+		}
+		if !expired {
+			continue
+		}
+		dropped, err := rewriteBlock(b.dir, files, func(labels Labels, p Point) bool {
+			return p.Timestamp >= cutoff || !labels.matches(matchers)
+		})
+		deleted += dropped
+		if err != nil {
+			return deleted, err
+		}
+		if remaining, err := chunkFiles(b.dir); err == nil && len(remaining) == 0 {
+			os.Remove(b.dir)
+		}
+	}
+	return deleted, nil
+}
+func (db *DB) Close() error {
+	db.mu.Lock()
+	if db.closed {
+		db.mu.Unlock()
+		return nil
+	}
+	db.closed = true
+	close(db.done)
+	db.mu.Unlock()
+	db.wg.Wait()
+	if db.options.ReadOnly {
+		return nil
+	}
+	err := db.Flush()
+	if closeErr := db.headLog.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+func (db *DB) maintenanceLoop() {
+	defer db.wg.Done()
+	ticker := time.NewTicker(db.options.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-db.done:
+			return
+		case <-ticker.C:
+			if err := db.Flush(); err != nil {
+				log.Printf("Failed to flush embedded storage: %v", err)
+			}
+			if err := db.compact(); err != nil {
+				log.Printf("Failed to compact embedded storage: %v", err)
+			}
+			if db.options.Retention > 0 {
+				if _, err := db.DeleteBefore(time.Now().Add(-db.options.Retention)); err != nil {
+					log.Printf("Failed to apply embedded storage retention: %v", err)
+				}
+			}
+		}
+	}
+}
+func (db *DB) compact() error {
+	db.diskMu.Lock()
+	defer db.diskMu.Unlock()
+	blocks, err := db.blocks()
+	if err != nil {
+		return err
+	}
+	sealedBefore := time.Now().Add(-db.options.BlockDuration).UnixMilli()
+	for _, b := range blocks {
+		if b.end > sealedBefore {
+			continue
+		}
+		files, err := chunkFiles(b.dir)
+		if err != nil {
+			return err
+		}
+		if len(files) < 2 {
+			continue
+		}
+		if _, err := rewriteBlock(b.dir, files, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+func (db *DB) blockFor(blockStart int64) block {
+	end := blockStart + db.options.BlockDuration.Milliseconds()
+	return block{
+		dir:   filepath.Join(db.options.Dir, fmt.Sprintf("%d-%d", blockStart, end)),
+		start: blockStart,
+		end:   end,
+	}
+}
+func (db *DB) blocks() ([]block, error) {
+	entries, err := os.ReadDir(db.options.Dir)
+	if err != nil {
+		return nil, err
+	}
+	var blocks []block
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		startText, endText, ok := strings.Cut(entry.Name(), "-")
+		if !ok {
+			continue
+		}
+		start, err := strconv.ParseInt(startText, 10, 64)
+		if err != nil {
+			continue
+		}
+		end, err := strconv.ParseInt(endText, 10, 64)
+		if err != nil {
+			continue
+		}
+		blocks = append(blocks, block{dir: filepath.Join(db.options.Dir, entry.Name()), start: start, end: end})
+	}
+	sort.Slice(blocks, func(i, j int) bool {
+		return blocks[i].start < blocks[j].start
+	})
+	return blocks, nil
+}
+func (s *headSeries) current() *chunk {
+	if len(s.chunks) == 0 {
+		return nil
+	}
+	return s.chunks[len(s.chunks)-1]
+}
+func (l Labels) key() string {
+	names := make([]string, 0, len(l))
+	for name := range l {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var b strings.Builder
+	for _, name := range names {
+		b.WriteString(name)
+		b.WriteByte('=')
+		b.WriteString(l[name])
+		b.WriteByte(0xff)
+	}
+	return b.String()
+}
+func (l Labels) copy() Labels {
+	c := make(Labels, len(l))
+	for k, v := range l {
+		c[k] = v
+	}
+	return c
+}
+func (l Labels) matches(matchers Labels) bool {
+	for name, value := range matchers {
+		if l[name] != value {
+			return false
+		}
+	}
+	return true
+}
+func mergeInto(merged map[string]*Series, labels Labels, points []Point, from, to int64) {
+	key := labels.key()
+	series, ok := merged[key]
+	if !ok {
+		series = &Series{Labels: labels}
+		merged[key] = series
+	}
+	for _, p := range points {
+		if p.Timestamp >= from && p.Timestamp <= to {
+			series.Points = append(series.Points, p)
+		}
+	}
+	if len(series.Points) == 0 {
+		delete(merged, key)
+	}
+}
+func encodeHeadRecord(labels Labels, ts int64, v float64) []byte {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	record := binary.AppendUvarint(nil, uint64(len(names)))
+	for _, name := range names {
+		record = binary.AppendUvarint(record, uint64(len(name)))
+		record = append(record, name...)
+		record = binary.AppendUvarint(record, uint64(len(labels[name])))
+		record = append(record, labels[name]...)
+	}
+	record = binary.BigEndian.AppendUint64(record, uint64(ts))
+	return binary.BigEndian.AppendUint64(record, math.Float64bits(v))
+}
+func decodeHeadRecord(record []byte) (Labels, int64, float64, error) {
+	count, n := binary.Uvarint(record)
+	if n <= 0 {
+		return nil, 0, 0, errBadHeadRecord
+	}
+	record = record[n:]
+	next := func() (string, bool) {
+		size, n := binary.Uvarint(record)
+		if n <= 0 || uint64(len(record)-n) < size {
+			return "", false
+		}
+		value := string(record[n : n+int(size)])
+		record = record[n+int(size):]
+		return value, true
+	}
+	labels := make(Labels, count)
+	for i := uint64(0); i < count; i++ {
+		name, ok := next()
+		if !ok {
+			return nil, 0, 0, errBadHeadRecord
+		}
+		value, ok := next()
+		if !ok {
+			return nil, 0, 0, errBadHeadRecord
+		}
+		labels[name] = value
+	}
+	if len(record) != 16 {
+		return nil, 0, 0, errBadHeadRecord
+	}
+	return labels, int64(binary.BigEndian.Uint64(record)), math.Float64frombits(binary.BigEndian.Uint64(record[8:])), nil
+}
+func sortAndDedupe(points []Point) []Point {
+	sort.SliceStable(points, func(i, j int) bool {
+		return points[i].Timestamp < points[j].Timestamp
+	})
+	out := points[:0]
+	for i, p := range points {
+		if i > 0 && p.Timestamp == out[len(out)-1].Timestamp {
+			out[len(out)-1] = p
+			continue
+		}
+		out = append(out, p)
+	}
+	return out
+}
\ No newline at end of file
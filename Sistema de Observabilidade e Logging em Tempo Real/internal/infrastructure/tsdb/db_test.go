@@ -0,0 +1,224 @@
+package tsdb
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+)
+var base = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+func openTestDB(t *testing.T, dir string) *DB {
+	t.Helper()
+	db, err := Open(Options{Dir: dir, BlockDuration: time.Hour, FlushInterval: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+func appendPoints(t *testing.T, db *DB, labels Labels, minutes ...int) {
+	t.Helper()
+	for _, minute := range minutes {
+		if err := db.Append(labels, base.Add(time.Duration(minute)*time.Minute), float64(minute)); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+func selectValues(t *testing.T, db *DB, matchers Labels) map[string][]float64 {
+	t.Helper()
+	series, err := db.Select(matchers, base.Add(-time.Hour), base.Add(24*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := make(map[string][]float64)
+	for _, s := range series {
+		for _, p := range s.Points {
+			out[s.Labels["container"]] = append(out[s.Labels["container"]], p.Value)
+		}
+	}
+	return out
+}
+func crash(db *DB) {
+	db.mu.Lock()
+	db.closed = true
+	close(db.done)
+	db.mu.Unlock()
+	db.wg.Wait()
+	db.headLog.Close()
+}
+func TestDBFlushWritesBlocksReadableAfterReopen(t *testing.T) {
+	dir := t.TempDir()
+	db := openTestDB(t, dir)
+	appendPoints(t, db, Labels{"container": "a"}, 0, 10, 70, 130)
+	appendPoints(t, db, Labels{"container": "b"}, 5)
+	if err := db.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	appendPoints(t, db, Labels{"container": "a"}, 140)
+	if got := fmt.Sprint(selectValues(t, db, Labels{"container": "a"})); got != "map[a:[0 10 70 130 140]]" {
+		t.Fatalf("unexpected points before reopen: %s", got)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+	blocks, err := db.blocks()
+	if err != nil || len(blocks) != 3 {
+		t.Fatalf("expected one block per hour, got %v %v", blocks, err)
+	}
+	reopened := openTestDB(t, dir)
+	defer reopened.Close()
+	if got := fmt.Sprint(selectValues(t, reopened, nil)); got != "map[a:[0 10 70 130 140] b:[5]]" {
+		t.Fatalf("unexpected points after reopen: %s", got)
+	}
+}
+func TestDBRecoversUnflushedSamplesFromHeadLog(t *testing.T) {
+	dir := t.TempDir()
+	db := openTestDB(t, dir)
+	appendPoints(t, db, Labels{"container": "a"}, 1, 2)
+	if err := db.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	appendPoints(t, db, Labels{"container": "a"}, 3, 4)
+	crash(db)
+	reopened := openTestDB(t, dir)
+	if got := fmt.Sprint(selectValues(t, reopened, nil)); got != "map[a:[1 2 3 4]]" {
+		t.Fatalf("expected unflushed samples to survive a crash, got %s", got)
+	}
+	crash(reopened)
+	again := openTestDB(t, dir)
+	defer again.Close()
+	if got := fmt.Sprint(selectValues(t, again, nil)); got != "map[a:[1 2 3 4]]" {
+		t.Fatalf("expected recovered samples to be flushed exactly once, got %s", got)
+	}
+}
+func TestDBCompactionMergesChunkFiles(t *testing.T) {
+	dir := t.TempDir()
+	db := openTestDB(t, dir)
+	defer db.Close()
+	for _, minute := range []int{1, 2, 3} {
+		appendPoints(t, db, Labels{"container": "a"}, minute)
+		appendPoints(t, db, Labels{"container": "b"}, minute+10)
+		if err := db.Flush(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	appendPoints(t, db, Labels{"container": "a"}, 2)
+	if err := db.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	block := db.blockFor(base.UnixMilli())
+	if files, _ := chunkFiles(block.dir); len(files) != 4 {
+		t.Fatalf("expected a chunk file per flush, got %v", files)
+	}
+	if err := db.compact(); err != nil {
+		t.Fatal(err)
+	}
+	if files, _ := chunkFiles(block.dir); len(files) != 1 {
+		t.Fatalf("expected compaction to leave one chunk file, got %v", files)
+	}
+	if got := fmt.Sprint(selectValues(t, db, nil)); got != "map[a:[1 2 3] b:[11 12 13]]" {
+		t.Fatalf("unexpected points after compaction: %s", got)
+	}
+}
+func TestDBDeleteRemovesMatchingPointsBeforeCutoff(t *testing.T) {
+	dir := t.TempDir()
+	db := openTestDB(t, dir)
+	defer db.Close()
+	appendPoints(t, db, Labels{"container": "a"}, 10, 20, 30, 90)
+	appendPoints(t, db, Labels{"container": "b"}, 10, 20)
+	if err := db.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	deleted, err := db.Delete(Labels{"container": "a"}, base.Add(25*time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deleted != 2 {
+		t.Fatalf("expected 2 deleted points, got %d", deleted)
+	}
+	if got := fmt.Sprint(selectValues(t, db, nil)); got != "map[a:[30 90] b:[10 20]]" {
+		t.Fatalf("unexpected points after delete: %s", got)
+	}
+}
+func TestDBDeleteBeforeDropsWholeBlocks(t *testing.T) {
+	dir := t.TempDir()
+	db := openTestDB(t, dir)
+	defer db.Close()
+	appendPoints(t, db, Labels{"container": "a"}, 10, 70, 130)
+	if err := db.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	deleted, err := db.DeleteBefore(base.Add(2 * time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deleted != 2 {
+		t.Fatalf("expected 2 deleted blocks, got %d", deleted)
+	}
+	if got := fmt.Sprint(selectValues(t, db, nil)); got != "map[a:[130]]" {
+		t.Fatalf("unexpected points after retention: %s", got)
+	}
+}
+func TestReadBlockRetriesWhenFilesAreReplaced(t *testing.T) {
+	dir := t.TempDir()
+	db := openTestDB(t, dir)
+	defer db.Close()
+	appendPoints(t, db, Labels{"container": "a"}, 1)
+	db.Flush()
+	appendPoints(t, db, Labels{"container": "a"}, 2)
+	db.Flush()
+	block := db.blockFor(base.UnixMilli())
+	files, err := chunkFiles(block.dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rewriteBlock(block.dir, files, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readChunkFiles(files, nil); !os.IsNotExist(err) {
+		t.Fatalf("expected stale file list to fail, got %v", err)
+	}
+	entries, err := readBlock(block.dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || len(entries[0].points) != 2 {
+		t.Fatalf("expected the rewritten block, got %+v", entries)
+	}
+}
+func TestDBSelectSeesConsistentDataDuringRewrites(t *testing.T) {
+	dir := t.TempDir()
+	db := openTestDB(t, dir)
+	defer db.Close()
+	for minute := 0; minute < 20; minute++ {
+		appendPoints(t, db, Labels{"container": "a"}, minute)
+		if err := db.Flush(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	block := db.blockFor(base.UnixMilli())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 50; i++ {
+			db.diskMu.Lock()
+			files, err := chunkFiles(block.dir)
+			if err == nil {
+				_, err = rewriteBlock(block.dir, files, nil)
+			}
+			db.diskMu.Unlock()
+			if err != nil {
+				t.Error(err)
+				return
+			}
+		}
+	}()
+	for {
+		select {
+		case <-done:
+			return
+		default:
+		}
+		if got := selectValues(t, db, nil); len(got["a"]) != 20 {
+			t.Fatalf("expected 20 points during rewrites, got %v", got)
+		}
+	}
+}
\ No newline at end of file
@@ -0,0 +1,258 @@
+package tsdb
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+const (
+	chunkSuffix = ".chunks"
+	fileMagic   = "TSDBCHK1"
+)
+var fileSeq atomic.Uint64
+type chunkEntry struct {
+	labels Labels
+	count  int
+	data   []byte
+	points []Point
+}
+func chunkFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var files []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), chunkSuffix) {
+			files = append(files, filepath.Join(dir, entry.Name()))
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+func writeChunkFile(dir string, entries []chunkEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	name := fmt.Sprintf("%020d-%06d%s", time.Now().UnixNano(), fileSeq.Add(1)%1000000, chunkSuffix)
+	path := filepath.Join(dir, name)
+	tmp := path + ".tmp"
+	file, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	if err := encodeEntries(file, entries); err != nil {
+		file.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+func encodeEntries(w io.Writer, entries []chunkEntry) error {
+	gz := gzip.NewWriter(w)
+	buf := bufio.NewWriter(gz)
+	if _, err := buf.WriteString(fileMagic); err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		writeUvarint(buf, uint64(len(entry.labels)))
+		names := make([]string, 0, len(entry.labels))
+		for name := range entry.labels {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			writeString(buf, name)
+			writeString(buf, entry.labels[name])
+		}
+		writeUvarint(buf, uint64(entry.count))
+		writeUvarint(buf, uint64(len(entry.data)))
+		if _, err := buf.Write(entry.data); err != nil {
+			return err
+		}
+	}
+	if err := buf.Flush(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+func readChunkFile(path string, matchers Labels) ([]chunkEntry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", path, err)
+	}
+	defer gz.Close()
+	r := bufio.NewReader(gz)
+	magic := make([]byte, len(fileMagic))
+	if _, err := io.ReadFull(r, magic); err != nil || string(magic) != fileMagic {
+		return nil, fmt.Errorf("read %s: not a chunk file", path)
+	}
+	var entries []chunkEntry
+	for {
+		labelCount, err := binary.ReadUvarint(r)
+		if errors.Is(err, io.EOF) {
+			return entries, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", path, err)
+		}
+		labels := make(Labels, labelCount)
+		for i := uint64(0); i < labelCount; i++ {
+			name, err := readString(r)
+			if err != nil {
+				return nil, fmt.Errorf("read %s: %w", path, err)
+			}
+			value, err := readString(r)
+			if err != nil {
+				return nil, fmt.Errorf("read %s: %w", path, err)
+			}
+			labels[name] = value
+		}
+		count, err := binary.ReadUvarint(r)
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", path, err)
+		}
+		size, err := binary.ReadUvarint(r)
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", path, err)
+		}
+		data := make([]byte, size)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return nil, fmt.Errorf("read %s: %w", path, err)
+		}
+		if !labels.matches(matchers) {
+			continue
+		}
+		points, err := decodeChunk(data, int(count))
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", path, err)
+		}
+		entries = append(entries, chunkEntry{labels: labels, count: int(count), data: data, points: points})
+	}
+}
+func readBlock(dir string, matchers Labels) ([]chunkEntry, error) {
+	for attempt := 0; attempt < maxBlockReadTries; attempt++ {
+		files, err := chunkFiles(dir)
+		if err != nil {
+			return nil, err
+		}
+		entries, err := readChunkFiles(files, matchers)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		current, err := chunkFiles(dir)
+		if err != nil {
+			return nil, err
+		}
+		if slices.Equal(files, current) {
+			return entries, nil
+		}
+	}
+	return nil, fmt.Errorf("read %s: block kept changing during read", dir)
+}
+func readChunkFiles(files []string, matchers Labels) ([]chunkEntry, error) {
+	var entries []chunkEntry
+	for _, file := range files {
+		fileEntries, err := readChunkFile(file, matchers)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, fileEntries...)
+	}
+	return entries, nil
+}
+func rewriteBlock(dir string, files []string, keep func(Labels, Point) bool) (int64, error) {
+	merged := make(map[string]*Series)
+	var dropped int64
+	for _, file := range files {
+		entries, err := readChunkFile(file, nil)
+		if err != nil {
+			return 0, err
+		}
+		for _, entry := range entries {
+			key := entry.labels.key()
+			series, ok := merged[key]
+			if !ok {
+				series = &Series{Labels: entry.labels}
+				merged[key] = series
+			}
+			for _, p := range entry.points {
+				if keep != nil && !keep(entry.labels, p) {
+					dropped++
+					continue
+				}
+				series.Points = append(series.Points, p)
+			}
+		}
+	}
+	var entries []chunkEntry
+	for _, series := range merged {
+		if len(series.Points) == 0 {
+			continue
+		}
+		c := newChunk()
+		for _, p := range sortAndDedupe(series.Points) {
+			c.append(p.Timestamp, p.Value)
+		}
+		entries = append(entries, chunkEntry{labels: series.Labels, count: c.count, data: c.bytes()})
+	}
+	if err := writeChunkFile(dir, entries); err != nil {
+		return 0, err
+	}
+	for _, file := range files {
+		if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+			return dropped, err
+		}
+	}
+	return dropped, nil
+}
+func writeUvarint(w *bufio.Writer, value uint64) {
+	var buf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(buf[:], value)
+	w.Write(buf[:n])
+}
+func writeString(w *bufio.Writer, value string) {
+	writeUvarint(w, uint64(len(value)))
+	w.WriteString(value)
+}
+func readString(r *bufio.Reader) (string, error) {
+	size, err := binary.ReadUvarint(r)
+	if err != nil {
+		return "", err
+	}
+	buf := make([]byte, size)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return "", err
+	}
+	return string(buf), nil
+}
\ No newline at end of file
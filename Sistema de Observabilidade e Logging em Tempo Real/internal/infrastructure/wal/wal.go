@@ -0,0 +1,490 @@
+package wal
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+type SyncPolicy int
+const (
+	SyncAlways SyncPolicy = iota
+	SyncInterval
+	SyncNever
+)
+const (
+	segmentSuffix     = ".wal"
+	checkpointFile    = "checkpoint"
+	headerSize        = 8
+	segmentMagic      = "OBSWAL01"
+	segmentHeaderSize = int64(len(segmentMagic) + 8)
+)
+var (
+	ErrClosed         = errors.New("wal is closed")
+	ErrRecordTooLarge = errors.New("wal record exceeds segment size")
+)
+type Options struct {
+	Dir             string
+	MaxSegmentBytes int64
+	MaxTotalBytes   int64
+	MaxAge          time.Duration
+	SyncPolicy      SyncPolicy
+	SyncInterval    time.Duration
+}
+type segment struct {
+	seq       uint64
+	size      int64
+	dataStart int64
+	created   time.Time
+}
+type Log struct {
+	options    Options
+	segments   []*segment
+	active     *os.File
+	activeSeg  *segment
+	readSeq    uint64
+	readOffset int64
+	dropped    uint64
+	syncs      uint64
+	now        func() time.Time
+	replayMu   sync.Mutex
+	closed     bool
+	done       chan struct{}
+	wg         sync.WaitGroup
+	mu         sync.Mutex
+}
+func ParseSyncPolicy(value string) (SyncPolicy, error) {
+	switch strings.ToLower(value) {
+	case "always":
+		return SyncAlways, nil
+	case "interval", "":
+		return SyncInterval, nil
+	case "never":
+		return SyncNever, nil
+	}
+	return SyncInterval, fmt.Errorf("unknown wal sync policy %q", value)
+}
+func Open(options Options) (*Log, error) {
+	if options.MaxSegmentBytes <= 0 {
+		options.MaxSegmentBytes = 16 * 1024 * 1024
+	}
+	if options.SyncInterval <= 0 {
+		options.SyncInterval = time.Second
+	}
+	if err := os.MkdirAll(options.Dir, 0o755); err != nil {
+		return nil, err
+	}
+	l := &Log{options: options, now: time.Now, done: make(chan struct{})}
+	if err := l.loadSegments(); err != nil {
+		return nil, err
+	}
+	l.loadCheckpoint()
+	if options.SyncPolicy == SyncInterval {
+		l.wg.Add(1)
+		go l.syncLoop()
+	}
+	return l, nil
+}
+func (l *Log) Append(record []byte) error {
+	if int64(len(record)+headerSize)+segmentHeaderSize > l.options.MaxSegmentBytes {
+		return ErrRecordTooLarge
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return ErrClosed
+	}
+	if l.active != nil && l.activeSeg.size+int64(len(record)+headerSize) > l.options.MaxSegmentBytes {
+		if err := l.sealActive(); err != nil {
+			return err
+		}
+	}
+	if l.active == nil {
+		if err := l.openActive(); err != nil {
+			return err
+		}
+	}
+	frame := make([]byte, headerSize+len(record))
+	binary.BigEndian.PutUint32(frame[0:4], uint32(len(record)))
+	binary.BigEndian.PutUint32(frame[4:8], crc32.ChecksumIEEE(record))
+	copy(frame[headerSize:], record)
+	if _, err := l.active.Write(frame); err != nil {
+		return err
+	}
+	l.activeSeg.size += int64(len(frame))
+	if l.options.SyncPolicy == SyncAlways {
+		if err := l.sync(l.active); err != nil {
+			return err
+		}
+	}
+	l.enforceLimits()
+	return nil
+}
+func (l *Log) Replay(fn func(record []byte) error) error {
+	l.replayMu.Lock()
+	defer l.replayMu.Unlock()
+	l.mu.Lock()
+	if l.closed {
+		l.mu.Unlock()
+		return ErrClosed
+	}
+	if l.active != nil {
+		if err := l.sealActive(); err != nil {
+			l.mu.Unlock()
+			return err
+		}
+	}
+	l.enforceLimits()
+	pending := append([]*segment(nil), l.segments...)
+	l.mu.Unlock()
+	for _, seg := range pending {
+		offset := seg.dataStart
+		l.mu.Lock()
+		if seg.seq == l.readSeq && l.readOffset > offset {
+			offset = l.readOffset
+		}
+		l.mu.Unlock()
+		next, err := l.replaySegment(seg, offset, fn)
+		l.mu.Lock()
+		if err != nil {
+			l.readSeq, l.readOffset = seg.seq, next
+			l.saveCheckpoint()
+			l.mu.Unlock()
+			return err
+		}
+		l.removeSegment(seg.seq)
+		l.readSeq, l.readOffset = 0, 0
+		l.saveCheckpoint()
+		l.mu.Unlock()
+	}
+	return nil
+}
+func (l *Log) Scan(fn func(record []byte) error) error {
+	l.replayMu.Lock()
+	defer l.replayMu.Unlock()
+	l.mu.Lock()
+	if l.closed {
+		l.mu.Unlock()
+		return ErrClosed
+	}
+	if l.active != nil {
+		if err := l.sealActive(); err != nil {
+			l.mu.Unlock()
+			return err
+		}
+	}
+	segments := append([]*segment(nil), l.segments...)
+	l.mu.Unlock()
+	for _, seg := range segments {
+		if _, err := l.replaySegment(seg, seg.dataStart, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+func (l *Log) Rewrite(fn func(record []byte) []byte) error {
+	l.replayMu.Lock()
+	defer l.replayMu.Unlock()
+	l.mu.Lock()
+	if l.closed {
+		l.mu.Unlock()
+		return ErrClosed
+	}
+	if l.active != nil {
+		if err := l.sealActive(); err != nil {
+			l.mu.Unlock()
+			return err
+		}
+	}
+	pending := append([]*segment(nil), l.segments...)
+	readSeq, readOffset := l.readSeq, l.readOffset
+	l.mu.Unlock()
+	for _, seg := range pending {
+		offset := seg.dataStart
+		if seg.seq == readSeq && readOffset > offset {
+			offset = readOffset
+		}
+		_, err := l.replaySegment(seg, offset, func(record []byte) error {
+			if kept := fn(record); kept != nil {
+				return l.Append(kept)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, seg := range pending {
+		l.removeSegment(seg.seq)
+	}
+	l.readSeq, l.readOffset = 0, 0
+	l.saveCheckpoint()
+	return nil
+}
+func (l *Log) Cut() (uint64, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return 0, ErrClosed
+	}
+	if l.active != nil {
+		if err := l.sealActive(); err != nil {
+			return 0, err
+		}
+	}
+	if n := len(l.segments); n > 0 {
+		return l.segments[n-1].seq, nil
+	}
+	return 0, nil
+}
+func (l *Log) TruncateThrough(seq uint64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for len(l.segments) > 0 && l.segments[0].seq <= seq && l.segments[0] != l.activeSeg {
+		l.removeSegment(l.segments[0].seq)
+	}
+	if l.readSeq != 0 && l.readSeq <= seq {
+		l.readSeq, l.readOffset = 0, 0
+		l.saveCheckpoint()
+	}
+}
+func (l *Log) Empty() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.segments) == 0
+}
+func (l *Log) Size() int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.totalSize()
+}
+func (l *Log) DroppedSegments() uint64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.dropped
+}
+func (l *Log) Close() error {
+	l.mu.Lock()
+	if l.closed {
+		l.mu.Unlock()
+		return nil
+	}
+	l.closed = true
+	close(l.done)
+	var err error
+	if l.active != nil {
+		err = l.sealActive()
+	}
+	l.mu.Unlock()
+	l.wg.Wait()
+	return err
+}
+func (l *Log) replaySegment(seg *segment, offset int64, fn func(record []byte) error) (int64, error) {
+	file, err := os.Open(l.segmentPath(seg.seq))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return offset, err
+	}
+	defer file.Close()
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return offset, err
+	}
+	reader := bufio.NewReader(file)
+	header := make([]byte, headerSize)
+	for {
+		if _, err := io.ReadFull(reader, header); err != nil {
+			return offset, nil
+		}
+		length := binary.BigEndian.Uint32(header[0:4])
+		if int64(length) > l.options.MaxSegmentBytes {
+			log.Printf("WAL segment %d corrupted at offset %d, skipping remainder", seg.seq, offset)
+			return offset, nil
+		}
+		record := make([]byte, length)
+		if _, err := io.ReadFull(reader, record); err != nil {
+			return offset, nil
+		}
+		if crc32.ChecksumIEEE(record) != binary.BigEndian.Uint32(header[4:8]) {
+			log.Printf("WAL segment %d corrupted at offset %d, skipping remainder", seg.seq, offset)
+			return offset, nil
+		}
+		if err := fn(record); err != nil {
+			return offset, err
+		}
+		offset += int64(headerSize) + int64(length)
+	}
+}
+func (l *Log) openActive() error {
+	seq := uint64(1)
+	if n := len(l.segments); n > 0 {
+		seq = l.segments[n-1].seq + 1
+	}
+	file, err := os.OpenFile(l.segmentPath(seq), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	created := l.now()
+	header := make([]byte, segmentHeaderSize)
+	copy(header, segmentMagic)
+	binary.BigEndian.PutUint64(header[len(segmentMagic):], uint64(created.UnixNano()))
+	if _, err := file.Write(header); err != nil {
+		file.Close()
+		os.Remove(l.segmentPath(seq))
+		return err
+	}
+	l.active = file
+	l.activeSeg = &segment{seq: seq, size: segmentHeaderSize, dataStart: segmentHeaderSize, created: created}
+	l.segments = append(l.segments, l.activeSeg)
+	return nil
+}
+func (l *Log) sealActive() error {
+	file := l.active
+	l.active, l.activeSeg = nil, nil
+	if l.options.SyncPolicy != SyncNever {
+		if err := l.sync(file); err != nil {
+			file.Close()
+			return err
+		}
+	}
+	return file.Close()
+}
+func (l *Log) enforceLimits() {
+	for len(l.segments) > 0 {
+		oldest := l.segments[0]
+		if oldest == l.activeSeg {
+			return
+		}
+		tooOld := l.options.MaxAge > 0 && l.now().Sub(oldest.created) > l.options.MaxAge
+		tooBig := l.options.MaxTotalBytes > 0 && l.totalSize() > l.options.MaxTotalBytes
+		if !tooOld && !tooBig {
+			return
+		}
+		log.Printf("WAL dropping segment %d (%d bytes) to respect size/age limits", oldest.seq, oldest.size)
+		l.dropped++
+		l.removeSegment(oldest.seq)
+		if l.readSeq == oldest.seq {
+			l.readSeq, l.readOffset = 0, 0
+			l.saveCheckpoint()
+		}
+	}
+}
+func (l *Log) removeSegment(seq uint64) {
+	for i, seg := range l.segments {
+		if seg.seq == seq {
+			l.segments = append(l.segments[:i], l.segments[i+1:]...)
+			break
+		}
+	}
+	if err := os.Remove(l.segmentPath(seq)); err != nil && !os.IsNotExist(err) {
+		log.Printf("Failed to remove WAL segment %d: %v", seq, err)
+	}
+}
+func (l *Log) totalSize() int64 {
+	var total int64
+	for _, seg := range l.segments {
+		total += seg.size
+	}
+	return total
+}
+func (l *Log) syncLoop() {
+	defer l.wg.Done()
+	ticker := time.NewTicker(l.options.SyncInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.done:
+			return
+		case <-ticker.C:
+			l.mu.Lock()
+			if l.active != nil {
+				if err := l.sync(l.active); err != nil {
+					log.Printf("Failed to sync WAL segment: %v", err)
+				}
+			}
+			l.mu.Unlock()
+		}
+	}
+}
+func (l *Log) loadSegments() error {
+	entries, err := os.ReadDir(l.options.Dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, segmentSuffix) {
+			continue
+		}
+		seq, err := strconv.ParseUint(strings.TrimSuffix(name, segmentSuffix), 10, 64)
+		if err != nil {
+			continue
+		}
+		seg, err := l.readSegmentInfo(seq)
+		if err != nil {
+			return err
+		}
+		l.segments = append(l.segments, seg)
+	}
+	sort.Slice(l.segments, func(i, j int) bool {
+		return l.segments[i].seq < l.segments[j].seq
+	})
+	return nil
+}
+func (l *Log) readSegmentInfo(seq uint64) (*segment, error) {
+	file, err := os.Open(l.segmentPath(seq))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	seg := &segment{seq: seq, size: info.Size(), created: info.ModTime()}
+	header := make([]byte, segmentHeaderSize)
+	if _, err := io.ReadFull(file, header); err == nil && string(header[:len(segmentMagic)]) == segmentMagic {
+		seg.dataStart = segmentHeaderSize
+		seg.created = time.Unix(0, int64(binary.BigEndian.Uint64(header[len(segmentMagic):])))
+	}
+	return seg, nil
+}
+func (l *Log) sync(file *os.File) error {
+	l.syncs++
+	return file.Sync()
+}
+func (l *Log) loadCheckpoint() {
+	data, err := os.ReadFile(filepath.Join(l.options.Dir, checkpointFile))
+	if err != nil {
+		return
+	}
+	if _, err := fmt.Sscanf(string(data), "%d %d", &l.readSeq, &l.readOffset); err != nil {
+		l.readSeq, l.readOffset = 0, 0
+	}
+}
+func (l *Log) saveCheckpoint() {
+	path := filepath.Join(l.options.Dir, checkpointFile)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(fmt.Sprintf("%d %d", l.readSeq, l.readOffset)), 0o644); err != nil {
+		log.Printf("Failed to write WAL checkpoint: %v", err)
+		return
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		log.Printf("Failed to write WAL checkpoint: %v", err)
+	}
+}
+func (l *Log) segmentPath(seq uint64) string {
+	return filepath.Join(l.options.Dir, fmt.Sprintf("%020d%s", seq, segmentSuffix))
+}
\ No newline at end of file
@@ -0,0 +1,229 @@
+package wal
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+func openTestLog(t *testing.T, options Options) *Log {
+	t.Helper()
+	if options.Dir == "" {
+		options.Dir = t.TempDir()
+	}
+	l, err := Open(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+	return l
+}
+func appendRecords(t *testing.T, l *Log, records ...string) {
+	t.Helper()
+	for _, record := range records {
+		if err := l.Append([]byte(record)); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+func replayAll(t *testing.T, l *Log) []string {
+	t.Helper()
+	var got []string
+	if err := l.Replay(func(record []byte) error {
+		got = append(got, string(record))
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	return got
+}
+func TestLogReplaysRecordsInOrderAcrossSegments(t *testing.T) {
+	dir := t.TempDir()
+	l := openTestLog(t, Options{Dir: dir, MaxSegmentBytes: segmentHeaderSize + 3*(headerSize+6)})
+	var want []string
+	for i := 0; i < 10; i++ {
+		want = append(want, fmt.Sprintf("rec-%02d", i))
+	}
+	appendRecords(t, l, want...)
+	if l.Close(); len(segmentFiles(t, dir)) != 4 {
+		t.Fatalf("expected 4 segments, got %v", segmentFiles(t, dir))
+	}
+	reopened := openTestLog(t, Options{Dir: dir, MaxSegmentBytes: segmentHeaderSize + 3*(headerSize+6)})
+	got := replayAll(t, reopened)
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("unexpected replay order %v", got)
+	}
+	if !reopened.Empty() || len(segmentFiles(t, dir)) != 0 {
+		t.Fatalf("expected replayed segments to be removed, got %v", segmentFiles(t, dir))
+	}
+}
+func TestLogRejectsRecordLargerThanSegment(t *testing.T) {
+	l := openTestLog(t, Options{MaxSegmentBytes: 64})
+	if err := l.Append(make([]byte, 64)); !errors.Is(err, ErrRecordTooLarge) {
+		t.Fatalf("expected ErrRecordTooLarge, got %v", err)
+	}
+}
+func TestLogDropsOldestSegmentsOverSizeCap(t *testing.T) {
+	segmentBytes := segmentHeaderSize + headerSize + 6
+	l := openTestLog(t, Options{MaxSegmentBytes: segmentBytes, MaxTotalBytes: 2 * segmentBytes})
+	appendRecords(t, l, "rec-01", "rec-02", "rec-03", "rec-04")
+	if l.DroppedSegments() != 2 {
+		t.Fatalf("expected 2 dropped segments, got %d", l.DroppedSegments())
+	}
+	if got := replayAll(t, l); fmt.Sprint(got) != "[rec-03 rec-04]" {
+		t.Fatalf("expected only the newest records, got %v", got)
+	}
+}
+func TestLogAgeCapUsesCreationTimeRecordedInSegment(t *testing.T) {
+	dir := t.TempDir()
+	l := openTestLog(t, Options{Dir: dir})
+	l.now = func() time.Time { return time.Now().Add(-2 * time.Hour) }
+	appendRecords(t, l, "old")
+	l.Close()
+	for _, name := range segmentFiles(t, dir) {
+		if err := os.Chtimes(name, time.Now(), time.Now()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	reopened := openTestLog(t, Options{Dir: dir, MaxAge: time.Hour})
+	if got := replayAll(t, reopened); len(got) != 0 {
+		t.Fatalf("expected expired segment to be dropped, got %v", got)
+	}
+	if reopened.DroppedSegments() != 1 {
+		t.Fatalf("expected 1 dropped segment, got %d", reopened.DroppedSegments())
+	}
+}
+func TestLogReadsLegacySegmentsWithoutHeader(t *testing.T) {
+	dir := t.TempDir()
+	l := openTestLog(t, Options{Dir: dir})
+	appendRecords(t, l, "legacy")
+	l.Close()
+	path := segmentFiles(t, dir)[0]
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data[segmentHeaderSize:], 0o644); err != nil {
+		t.Fatal(err)
+	}
+	reopened := openTestLog(t, Options{Dir: dir, MaxAge: time.Hour})
+	if got := replayAll(t, reopened); fmt.Sprint(got) != "[legacy]" {
+		t.Fatalf("expected legacy record, got %v", got)
+	}
+}
+func TestLogSyncPolicy(t *testing.T) {
+	always := openTestLog(t, Options{SyncPolicy: SyncAlways})
+	appendRecords(t, always, "a", "b", "c")
+	if always.syncs != 3 {
+		t.Fatalf("expected a sync per append, got %d", always.syncs)
+	}
+	never := openTestLog(t, Options{SyncPolicy: SyncNever})
+	appendRecords(t, never, "a", "b", "c")
+	never.Close()
+	if never.syncs != 0 {
+		t.Fatalf("expected no syncs, got %d", never.syncs)
+	}
+	interval := openTestLog(t, Options{SyncPolicy: SyncInterval, SyncInterval: 10 * time.Millisecond})
+	appendRecords(t, interval, "a")
+	deadline := time.Now().Add(time.Second)
+	for {
+		interval.mu.Lock()
+		syncs := interval.syncs
+		interval.mu.Unlock()
+		if syncs > 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("expected the sync loop to sync the active segment")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+func TestLogReplayResumesAfterFailedRecord(t *testing.T) {
+	dir := t.TempDir()
+	l := openTestLog(t, Options{Dir: dir})
+	appendRecords(t, l, "a", "b", "c", "d")
+	unavailable := errors.New("backend unavailable")
+	var delivered []string
+	err := l.Replay(func(record []byte) error {
+		if string(record) == "c" {
+			return unavailable
+		}
+		delivered = append(delivered, string(record))
+		return nil
+	})
+	if !errors.Is(err, unavailable) || fmt.Sprint(delivered) != "[a b]" {
+		t.Fatalf("unexpected first replay %v %v", delivered, err)
+	}
+	l.Close()
+	reopened := openTestLog(t, Options{Dir: dir})
+	appendRecords(t, reopened, "e")
+	if got := replayAll(t, reopened); fmt.Sprint(got) != "[c d e]" {
+		t.Fatalf("expected replay to resume at the failed record, got %v", got)
+	}
+}
+func TestLogRewriteDropsAndReplacesUnreplayedRecords(t *testing.T) {
+	dir := t.TempDir()
+	l := openTestLog(t, Options{Dir: dir, MaxSegmentBytes: segmentHeaderSize + 2*(headerSize+2)})
+	appendRecords(t, l, "a1", "b1", "a2", "b2", "a3")
+	failed := errors.New("backend unavailable")
+	l.Replay(func(record []byte) error {
+		if string(record) == "b1" {
+			return failed
+		}
+		return nil
+	})
+	err := l.Rewrite(func(record []byte) []byte {
+		switch record[0] {
+		case 'a':
+			return nil
+		case 'b':
+			return []byte("c" + string(record[1:]))
+		}
+		return record
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	appendRecords(t, l, "d1")
+	if got := replayAll(t, l); fmt.Sprint(got) != "[c1 c2 d1]" {
+		t.Fatalf("expected rewritten records without already replayed ones, got %v", got)
+	}
+}
+func TestLogReplayStopsAtTornFinalRecord(t *testing.T) {
+	dir := t.TempDir()
+	l := openTestLog(t, Options{Dir: dir})
+	appendRecords(t, l, "first", "second")
+	l.Close()
+	path := segmentFiles(t, dir)[0]
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.Write([]byte{0, 0, 0, 32, 1, 2, 3, 4, 't', 'o', 'r', 'n'})
+	file.Close()
+	reopened := openTestLog(t, Options{Dir: dir})
+	if got := replayAll(t, reopened); fmt.Sprint(got) != "[first second]" {
+		t.Fatalf("expected intact records before the torn one, got %v", got)
+	}
+	appendRecords(t, reopened, "third")
+	if got := replayAll(t, reopened); fmt.Sprint(got) != "[third]" {
+		t.Fatalf("expected log to keep working after a torn record, got %v", got)
+	}
+}
+func segmentFiles(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var files []string
+	for _, entry := range entries {
+		if strings.HasSuffix(entry.Name(), segmentSuffix) {
+			files = append(files, filepath.Join(dir, entry.Name()))
+		}
+	}
+	return files
+}
\ No newline at end of file
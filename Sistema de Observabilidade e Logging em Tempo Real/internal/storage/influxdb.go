@@ -1,11 +1,11 @@
 package storage
 import (
 	"context"
-	"fmt"
 	"time"
 	influxdb2 "github.com/influxdata/influxdb-client-go/v2"
 	"github.com/influxdata/influxdb-client-go/v2/api"
 	"observability-system/internal/collector"
+	"observability-system/internal/infrastructure/flux"
 )
 type InfluxDBStorage struct {
 	client   influxdb2.Client
@@ -47,12 +47,11 @@ func (s *InfluxDBStorage) WriteMetrics(stats *collector.ContainerStats) error {
 	return nil
 }
 func (s *InfluxDBStorage) QueryMetrics(ctx context.Context, containerID string, duration time.Duration) ([]map[string]interface{}, error) {
-	query := fmt.Sprintf(`
-		from(bucket: "%s")
-		|> range(start: -%s)
-		|> filter(fn: (r) => r["_measurement"] == "container_metrics")
-		|> filter(fn: (r) => r["container_id"] == "%s")
-	`, s.bucket, duration.String(), containerID)
+	query := flux.From(s.bucket).
+		Range(duration).
+		Filter(flux.Equal("_measurement", "container_metrics")).
+		Filter(flux.Equal("container_id", containerID)).
+		String()
 	result, err := s.queryAPI.Query(ctx, query)
 	if err != nil {
 		return nil, err
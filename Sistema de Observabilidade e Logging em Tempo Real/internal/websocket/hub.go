@@ -3,26 +3,78 @@ import (
 	"encoding/json"
 	"log"
 	"sync"
+	"sync/atomic"
+	"time"
 	"github.com/gorilla/websocket"
+	"observability-system/internal/domain/entities"
 )
+type HubOptions struct {
+	LogRate  float64
+	LogBurst int
+}
 type Hub struct {
-	clients    map[*Client]bool
-	broadcast  chan []byte
-	register   chan *Client
-	unregister chan *Client
-	mu         sync.RWMutex
+	clients     map[*Client]bool
+	broadcast   chan []byte
+	logs        chan []*entities.LogEntry
+	register    chan *Client
+	unregister  chan *Client
+	subscribe   chan subscription
+	options     HubOptions
+	subscribers atomic.Int64
+	now         func() time.Time
+	mu          sync.RWMutex
 }
 type Client struct {
 	Hub  *Hub
 	Conn *websocket.Conn
 	Send chan []byte
+	tail *logTail
+}
+type subscription struct {
+	client  *Client
+	message clientMessage
+}
+type clientMessage struct {
+	Type           string            `json:"type"`
+	ContainerIDs   []string          `json:"container_ids"`
+	ContainerNames []string          `json:"container_names"`
+	Levels         []string          `json:"levels"`
+	Labels         map[string]string `json:"labels"`
+	Contains       string            `json:"q"`
+	Regex          string            `json:"regex"`
+}
+type logTail struct {
+	matcher *entities.LogMatcher
+	tokens  float64
+	refill  time.Time
+	dropped int
+}
+func DefaultHubOptions() HubOptions {
+	return HubOptions{
+		LogRate:  100,
+		LogBurst: 500,
+	}
 }
 func NewHub() *Hub {
+	return NewHubWithOptions(DefaultHubOptions())
+}
+func NewHubWithOptions(options HubOptions) *Hub {
+	defaults := DefaultHubOptions()
+	if options.LogRate <= 0 {
+		options.LogRate = defaults.LogRate
+	}
+	if options.LogBurst <= 0 {
+		options.LogBurst = defaults.LogBurst
+	}
 	return &Hub{
 		clients:    make(map[*Client]bool),
 		broadcast:  make(chan []byte, 256),
+		logs:       make(chan []*entities.LogEntry, 64),
 		register:   make(chan *Client),
 		unregister: make(chan *Client),
+		subscribe:  make(chan subscription),
+		options:    options,
+		now:        time.Now,
 	}
 }
 func (h *Hub) Run() {
@@ -36,19 +88,31 @@ func (h *Hub) Run() {
 		case client := <-h.unregister:
 			h.mu.Lock()
 			if _, ok := h.clients[client]; ok {
-				delete(h.clients, client)
-				close(client.Send)
+				h.remove(client)
 			}
 			h.mu.Unlock()
 			log.Printf("Client disconnected. Total clients: %d", len(h.clients))
 		case message := <-h.broadcast:
-			h.mu.RLock()
+			h.mu.Lock()
 			for client := range h.clients {
 				select {
 				case client.Send <- message:
 				default:
-					close(client.Send)
-					delete(h.clients, client)
+					h.remove(client)
+				}
+			}
+			h.mu.Unlock()
+		case sub := <-h.subscribe:
+			h.mu.Lock()
+			if _, ok := h.clients[sub.client]; ok {
+				h.updateSubscription(sub.client, sub.message)
+			}
+			h.mu.Unlock()
+		case entries := <-h.logs:
+			h.mu.RLock()
+			for client := range h.clients {
+				if client.tail != nil {
+					h.deliverLogs(client, entries)
 				}
 			}
 			h.mu.RUnlock()
@@ -63,12 +127,113 @@ func (h *Hub) BroadcastMetrics(data interface{}) error {
 	h.broadcast <- message
 	return nil
 }
+func (h *Hub) BroadcastLogs(entries []*entities.LogEntry) {
+	if len(entries) == 0 {
+		return
+	}
+	h.logs <- entries
+}
+func (h *Hub) LogSubscribers() int {
+	return int(h.subscribers.Load())
+}
 func (h *Hub) Register(client *Client) {
 	h.register <- client
 }
 func (h *Hub) Unregister(client *Client) {
 	h.unregister <- client
 }
+func (h *Hub) remove(client *Client) {
+	if client.tail != nil {
+		client.tail = nil
+		h.subscribers.Add(-1)
+	}
+	close(client.Send)
+	delete(h.clients, client)
+}
+func (h *Hub) updateSubscription(client *Client, message clientMessage) {
+	switch message.Type {
+	case "subscribe_logs":
+		matcher, err := entities.NewLogMatcher(entities.LogQuery{
+			ContainerIDs:   message.ContainerIDs,
+			ContainerNames: message.ContainerNames,
+			Levels:         message.Levels,
+			Labels:         message.Labels,
+			Contains:       message.Contains,
+			Regex:          message.Regex,
+		})
+		if err != nil {
+			h.trySend(client, map[string]interface{}{"type": "error", "error": err.Error()})
+			return
+		}
+		if client.tail == nil {
+			h.subscribers.Add(1)
+		}
+		client.tail = &logTail{matcher: matcher, tokens: float64(h.options.LogBurst), refill: h.now()}
+	case "unsubscribe_logs":
+		if client.tail != nil {
+			client.tail = nil
+			h.subscribers.Add(-1)
+		}
+	}
+}
+func (h *Hub) deliverLogs(client *Client, entries []*entities.LogEntry) {
+	tail := client.tail
+	tail.take(h.now(), h.options)
+	var matched []*entities.LogEntry
+	for _, entry := range entries {
+		if tail.matcher.Matches(entry) {
+			matched = append(matched, entry)
+		}
+	}
+	if !h.reportDropped(client) {
+		tail.dropped += len(matched)
+		return
+	}
+	var batch []*entities.LogEntry
+	for _, entry := range matched {
+		if tail.tokens < 1 {
+			tail.dropped++
+			continue
+		}
+		tail.tokens--
+		batch = append(batch, entry)
+	}
+	if len(batch) > 0 && !h.trySend(client, map[string]interface{}{"type": "logs", "entries": batch}) {
+		tail.dropped += len(batch)
+	}
+	h.reportDropped(client)
+}
+func (h *Hub) reportDropped(client *Client) bool {
+	tail := client.tail
+	if tail.dropped == 0 {
+		return true
+	}
+	if !h.trySend(client, map[string]interface{}{"type": "logs_dropped", "dropped": tail.dropped}) {
+		return false
+	}
+	tail.dropped = 0
+	return true
+}
+func (h *Hub) trySend(client *Client, payload interface{}) bool {
+	message, err := json.Marshal(payload)
+	if err != nil {
+		log.Printf("Failed to encode websocket message: %v", err)
+		return false
+	}
+	select {
+	case client.Send <- message:
+		return true
+	default:
+		return false
+	}
+}
+func (t *logTail) take(now time.Time, options HubOptions) {
+	t.tokens += now.Sub(t.refill).Seconds() * options.LogRate
+	if burst := float64(options.LogBurst); t.tokens > burst {
+		t.tokens = burst
+	}
+	t.refill = now
+}
 func (c *Client) WritePump() {
 	defer func() {
 		c.Conn.Close()
@@ -85,9 +250,14 @@ func (c *Client) ReadPump() {
 		c.Conn.Close()
 	}()
 	for {
-		_, _, err := c.Conn.ReadMessage()
+		_, data, err := c.Conn.ReadMessage()
 		if err != nil {
 			break
 		}
+		var message clientMessage
+		if err := json.Unmarshal(data, &message); err != nil {
+			continue
+		}
+		c.Hub.subscribe <- subscription{client: c, message: message}
 	}
 }
\ No newline at end of file
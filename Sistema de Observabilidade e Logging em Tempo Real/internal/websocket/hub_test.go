@@ -0,0 +1,150 @@
+package websocket
+import (
+	"encoding/json"
+	"sync/atomic"
+	"testing"
+	"time"
+	"observability-system/internal/domain/entities"
+)
+type tailMessage struct {
+	Type    string              `json:"type"`
+	Entries []entities.LogEntry `json:"entries"`
+	Dropped int                 `json:"dropped"`
+	Error   string              `json:"error"`
+}
+func newTestHub(t *testing.T, options HubOptions) (*Hub, *atomic.Int64) {
+	t.Helper()
+	var clock atomic.Int64
+	clock.Store(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC).UnixNano())
+	hub := NewHubWithOptions(options)
+	hub.now = func() time.Time { return time.Unix(0, clock.Load()) }
+	go hub.Run()
+	return hub, &clock
+}
+func subscribe(hub *Hub, capacity int, message clientMessage) *Client {
+	client := &Client{Hub: hub, Send: make(chan []byte, capacity)}
+	hub.Register(client)
+	hub.subscribe <- subscription{client: client, message: message}
+	return client
+}
+func receive(t *testing.T, client *Client) tailMessage {
+	t.Helper()
+	select {
+	case data := <-client.Send:
+		var message tailMessage
+		if err := json.Unmarshal(data, &message); err != nil {
+			t.Fatal(err)
+		}
+		return message
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for websocket message")
+	}
+	return tailMessage{}
+}
+func expectNothing(t *testing.T, client *Client) {
+	t.Helper()
+	select {
+	case data := <-client.Send:
+		t.Fatalf("unexpected message %s", data)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+func entry(container, level, message string) *entities.LogEntry {
+	return &entities.LogEntry{ContainerID: container, Level: level, Message: message}
+}
+func TestLogTailFiltersPerClient(t *testing.T) {
+	hub, _ := newTestHub(t, HubOptions{})
+	errors := subscribe(hub, 8, clientMessage{Type: "subscribe_logs", ContainerIDs: []string{"a"}, Levels: []string{"error"}})
+	timeouts := subscribe(hub, 8, clientMessage{Type: "subscribe_logs", Regex: `time(out)?\b`})
+	hub.BroadcastLogs([]*entities.LogEntry{
+		entry("a", "info", "started"),
+		entry("a", "error", "db timeout"),
+		entry("b", "error", "disk full"),
+		entry("b", "warn", "slow timeout"),
+	})
+	got := receive(t, errors)
+	if got.Type != "logs" || len(got.Entries) != 1 || got.Entries[0].Message != "db timeout" {
+		t.Fatalf("unexpected message for error tail %+v", got)
+	}
+	got = receive(t, timeouts)
+	if len(got.Entries) != 2 || got.Entries[0].Message != "db timeout" || got.Entries[1].Message != "slow timeout" {
+		t.Fatalf("unexpected message for regex tail %+v", got)
+	}
+	if hub.LogSubscribers() != 2 {
+		t.Fatalf("expected 2 subscribers, got %d", hub.LogSubscribers())
+	}
+	hub.subscribe <- subscription{client: timeouts, message: clientMessage{Type: "unsubscribe_logs"}}
+	hub.BroadcastLogs([]*entities.LogEntry{entry("a", "error", "another timeout")})
+	receive(t, errors)
+	expectNothing(t, timeouts)
+	if hub.LogSubscribers() != 1 {
+		t.Fatalf("expected 1 subscriber, got %d", hub.LogSubscribers())
+	}
+}
+func TestLogTailRateLimitsAndReportsDrops(t *testing.T) {
+	var clock atomic.Int64
+	hub := NewHubWithOptions(HubOptions{LogRate: 1, LogBurst: 2})
+	hub.now = func() time.Time { return time.Unix(0, clock.Load()) }
+	client := &Client{Hub: hub, Send: make(chan []byte, 8)}
+	hub.updateSubscription(client, clientMessage{Type: "subscribe_logs"})
+	var burst []*entities.LogEntry
+	for i := 0; i < 5; i++ {
+		burst = append(burst, entry("a", "info", "line"))
+	}
+	hub.deliverLogs(client, burst)
+	if got := receive(t, client); got.Type != "logs" || len(got.Entries) != 2 {
+		t.Fatalf("expected burst of 2 entries, got %+v", got)
+	}
+	if got := receive(t, client); got.Type != "logs_dropped" || got.Dropped != 3 {
+		t.Fatalf("expected drop marker for 3 lines without waiting for more logs, got %+v", got)
+	}
+	clock.Add(int64(3 * time.Second))
+	hub.deliverLogs(client, []*entities.LogEntry{entry("a", "info", "after pause")})
+	if got := receive(t, client); len(got.Entries) != 1 || got.Entries[0].Message != "after pause" {
+		t.Fatalf("unexpected entries after pause %+v", got)
+	}
+	expectNothing(t, client)
+}
+func TestLogTailReportsPendingDropsWithoutMatchingEntries(t *testing.T) {
+	hub := NewHub()
+	client := &Client{Hub: hub, Send: make(chan []byte, 1)}
+	hub.updateSubscription(client, clientMessage{Type: "subscribe_logs", Levels: []string{"error"}})
+	hub.deliverLogs(client, []*entities.LogEntry{entry("a", "error", "one")})
+	hub.deliverLogs(client, []*entities.LogEntry{entry("a", "error", "two"), entry("a", "info", "ignored")})
+	if got := receive(t, client); len(got.Entries) != 1 || got.Entries[0].Message != "one" {
+		t.Fatalf("unexpected entries %+v", got)
+	}
+	hub.deliverLogs(client, []*entities.LogEntry{entry("a", "info", "filtered out")})
+	if got := receive(t, client); got.Type != "logs_dropped" || got.Dropped != 1 {
+		t.Fatalf("expected drop marker for 1 line, got %+v", got)
+	}
+	expectNothing(t, client)
+}
+func TestLogTailDropsWhenClientFallsBehind(t *testing.T) {
+	hub := NewHub()
+	client := &Client{Hub: hub, Send: make(chan []byte, 2)}
+	hub.updateSubscription(client, clientMessage{Type: "subscribe_logs"})
+	for i := 0; i < 3; i++ {
+		hub.deliverLogs(client, []*entities.LogEntry{entry("a", "info", "one"), entry("a", "info", "two")})
+	}
+	receive(t, client)
+	receive(t, client)
+	hub.deliverLogs(client, []*entities.LogEntry{entry("a", "info", "caught up")})
+	if got := receive(t, client); got.Type != "logs_dropped" || got.Dropped != 2 {
+		t.Fatalf("expected drop marker for 2 lines, got %+v", got)
+	}
+	if got := receive(t, client); len(got.Entries) != 1 || got.Entries[0].Message != "caught up" {
+		t.Fatalf("unexpected entries %+v", got)
+	}
+}
+func TestLogTailRejectsInvalidRegex(t *testing.T) {
+	hub, _ := newTestHub(t, HubOptions{})
+	client := subscribe(hub, 8, clientMessage{Type: "subscribe_logs", Regex: "("})
+	got := receive(t, client)
+	if got.Type != "error" || got.Error == "" {
+		t.Fatalf("expected error message, got %+v", got)
+	}
+	if hub.LogSubscribers() != 0 {
+		t.Fatalf("expected no subscribers, got %d", hub.LogSubscribers())
+	}
+}
\ No newline at end of file
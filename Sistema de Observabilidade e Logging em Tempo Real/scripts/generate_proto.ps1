@@ -1,8 +1,8 @@
 Write-Host "🔧 Generating gRPC code from proto files..." -ForegroundColor Cyan
 New-Item -ItemType Directory -Force -Path "proto\gen" | Out-Null
-protoc --go_out=proto\gen --go_opt=paths=source_relative `
+protoc -I proto --go_out=proto\gen --go_opt=paths=source_relative `
        --go-grpc_out=proto\gen --go-grpc_opt=paths=source_relative `
-       proto\metrics.proto
+       metrics.proto
 if ($LASTEXITCODE -eq 0) {
     Write-Host "✅ gRPC code generated successfully!" -ForegroundColor Green
     Write-Host "Files created in proto\gen\" -ForegroundColor Green
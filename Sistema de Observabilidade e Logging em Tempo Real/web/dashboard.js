@@ -1,4 +1,6 @@
 let cpuChart, memoryChart, networkChart;
+let logCursor = '';
+let liveSocket = null;
 async function loadContainers() {
     try {
         const response = await fetch('/api/containers');
@@ -28,6 +30,83 @@ async function loadData() {
     } catch (error) {
         console.error('Failed to load metrics:', error);
     }
+    loadLogs(true);
+}
+async function loadLogs(reset) {
+    const containerID = document.getElementById('containerSelect').value;
+    const timeRange = document.getElementById('timeRange').value;
+    const list = document.getElementById('logList');
+    if (reset) {
+        logCursor = '';
+        list.innerHTML = '';
+    }
+    const params = new URLSearchParams({ duration: timeRange, limit: '100' });
+    if (containerID) params.set('container_id', containerID);
+    const search = document.getElementById('logSearch').value;
+    if (search) params.set('q', search);
+    const level = document.getElementById('logLevel').value;
+    if (level) params.set('level', level);
+    if (logCursor) params.set('cursor', logCursor);
+    try {
+        const response = await fetch(`/api/logs?${params}`);
+        if (!response.ok) throw new Error(await response.text());
+        const page = await response.json();
+        (page.Entries || []).forEach(entry => list.appendChild(renderLogLine(entry)));
+        logCursor = page.NextCursor || '';
+        document.getElementById('logMore').style.display = logCursor ? '' : 'none';
+    } catch (error) {
+        console.error('Failed to load logs:', error);
+    }
+}
+function renderLogLine(entry) {
+    const line = document.createElement('div');
+    line.className = `log-line log-level-${entry.Level}`;
+    const time = document.createElement('span');
+    time.className = 'log-time';
+    time.textContent = new Date(entry.Timestamp).toLocaleString() + ' ';
+    line.appendChild(time);
+    line.appendChild(document.createTextNode(`${entry.ContainerName || entry.Source} ${entry.Level || '-'} ${entry.Message}`));
+    return line;
+}
+function toggleLiveTail() {
+    const button = document.getElementById('logLive');
+    if (liveSocket) {
+        liveSocket.close();
+        liveSocket = null;
+        button.textContent = 'Live Tail';
+        return;
+    }
+    const list = document.getElementById('logList');
+    const protocol = window.location.protocol === 'https:' ? 'wss:' : 'ws:';
+    liveSocket = new WebSocket(`${protocol}//${window.location.host}/ws`);
+    button.textContent = 'Stop Tail';
+    liveSocket.onopen = () => {
+        const containerID = document.getElementById('containerSelect').value;
+        const level = document.getElementById('logLevel').value;
+        liveSocket.send(JSON.stringify({
+            type: 'subscribe_logs',
+            container_ids: containerID ? [containerID] : [],
+            levels: level ? level.split(',') : [],
+            q: document.getElementById('logSearch').value
+        }));
+    };
+    liveSocket.onmessage = (event) => {
+        const message = JSON.parse(event.data);
+        if (message.type === 'logs') {
+            message.entries.forEach(entry => list.prepend(renderLogLine(entry)));
+        } else if (message.type === 'logs_dropped') {
+            const marker = document.createElement('div');
+            marker.className = 'log-line log-dropped';
+            marker.textContent = `… dropped ${message.dropped} lines`;
+            list.prepend(marker);
+        } else if (message.type === 'error') {
+            console.error('Live tail error:', message.error);
+        }
+    };
+    liveSocket.onclose = () => {
+        liveSocket = null;
+        button.textContent = 'Live Tail';
+    };
 }
 function updateCharts(data) {
     const timestamps = data.map(d => new Date(d.Timestamp).toLocaleTimeString());